@@ -16,7 +16,9 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+	certaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/cert_access"
 	configaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/config_access"
+	jwtaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/jwt_access"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/cmd"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
@@ -444,6 +446,8 @@ func main() {
 
 	// Register built-in access providers before constructing services.
 	configaccess.Register()
+	jwtaccess.Register()
+	certaccess.Register()
 
 	// Handle different command modes based on the provided flags.
 
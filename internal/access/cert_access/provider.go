@@ -0,0 +1,130 @@
+// Package certaccess provides the client-cert access provider, which authenticates requests by
+// the TLS client certificate verified during the handshake.
+package certaccess
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"sync"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/mtls"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// MetadataSubject carries the distinguished name of the client certificate subject.
+const MetadataSubject = "subject"
+
+var registerOnce sync.Once
+
+// Register ensures the client-cert provider is available to the access manager.
+func Register() {
+	registerOnce.Do(func() {
+		sdkaccess.RegisterProvider(sdkconfig.AccessProviderTypeClientCert, newProvider)
+	})
+}
+
+type provider struct {
+	name  string
+	certs []sdkconfig.ClientCert
+	// verify checks the presented chain against the client CA bundle.
+	verify func([]*x509.Certificate) error
+}
+
+func newProvider(cfg *sdkconfig.AccessProvider, root *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
+	name := cfg.Name
+	if name == "" {
+		name = sdkconfig.DefaultClientCertProviderName
+	}
+	var certs []sdkconfig.ClientCert
+	if root != nil {
+		certs = append(certs, root.ClientCerts...)
+	}
+	return &provider{name: name, certs: certs, verify: mtls.Default().Verify}, nil
+}
+
+func (p *provider) Identifier() string {
+	if p == nil || p.name == "" {
+		return sdkconfig.DefaultClientCertProviderName
+	}
+	return p.name
+}
+
+// Authenticate maps the client certificate of r to the first matching client-certs entry. The
+// chain is verified again against the client CA bundle, so certificates requested without
+// verification are never trusted. Requests without a certificate, or with one no entry maps, are
+// left to the other providers.
+func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.Result, error) {
+	if p == nil || len(p.certs) == 0 || r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, sdkaccess.ErrNotHandled
+	}
+	if err := p.verify(r.TLS.PeerCertificates); err != nil {
+		log.Debugf("client-cert access provider %s rejected certificate: %v", p.Identifier(), err)
+		return nil, sdkaccess.ErrInvalidCredential
+	}
+	leaf := r.TLS.PeerCertificates[0]
+	for _, entry := range p.certs {
+		principal, ok := matchCertificate(entry, leaf)
+		if !ok {
+			continue
+		}
+		metadata := map[string]string{
+			"source":        "client-certificate",
+			MetadataSubject: leaf.Subject.String(),
+		}
+		sdkaccess.ApplyPolicyMetadata(metadata, entry.ClientPolicy)
+		return &sdkaccess.Result{
+			Provider:  p.Identifier(),
+			Principal: principal,
+			Metadata:  metadata,
+		}, nil
+	}
+	log.Debugf("client-cert access provider %s has no mapping for %s", p.Identifier(), leaf.Subject.String())
+	return nil, sdkaccess.ErrNotHandled
+}
+
+// matchCertificate reports whether entry maps cert and returns the principal it authenticates.
+func matchCertificate(entry sdkconfig.ClientCert, cert *x509.Certificate) (string, bool) {
+	if entry.Subject != "" &&
+		!sdkaccess.MatchPattern(entry.Subject, cert.Subject.CommonName) &&
+		!sdkaccess.MatchPattern(entry.Subject, cert.Subject.String()) {
+		return "", false
+	}
+	matchedSAN := ""
+	if entry.SAN != "" {
+		for _, san := range subjectAltNames(cert) {
+			if sdkaccess.MatchPattern(entry.SAN, san) {
+				matchedSAN = san
+				break
+			}
+		}
+		if matchedSAN == "" {
+			return "", false
+		}
+	}
+	switch {
+	case entry.Principal != "":
+		return entry.Principal, true
+	case matchedSAN != "":
+		return matchedSAN, true
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName, true
+	default:
+		return cert.Subject.String(), true
+	}
+}
+
+func subjectAltNames(cert *x509.Certificate) []string {
+	names := make([]string, 0, len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.URIs)+len(cert.IPAddresses))
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	return names
+}
@@ -0,0 +1,155 @@
+package certaccess
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/mtls"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (ca testCA) issue(t *testing.T, subject pkix.Name, emails ...string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate client key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:   big.NewInt(time.Now().UnixNano()),
+		Subject:        subject,
+		EmailAddresses: emails,
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       time.Now().Add(time.Hour),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("create client certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert
+}
+
+func certRequest(cert *x509.Certificate) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	if cert != nil {
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	}
+	return req
+}
+
+func TestProvider_MapsVerifiedCertificatesToPrincipals(t *testing.T) {
+	ca := newTestCA(t, "Team CA")
+	caPath := filepath.Join(t.TempDir(), "client-ca.pem")
+	if err := os.WriteFile(caPath, ca.pem, 0o600); err != nil {
+		t.Fatalf("write CA bundle: %v", err)
+	}
+	verifier := &mtls.Verifier{}
+	if _, err := verifier.Load(caPath); err != nil {
+		t.Fatalf("load CA bundle: %v", err)
+	}
+
+	built, err := newProvider(&sdkconfig.AccessProvider{Type: sdkconfig.AccessProviderTypeClientCert}, &sdkconfig.SDKConfig{
+		ClientCerts: []sdkconfig.ClientCert{
+			{
+				Subject:      "ci-*",
+				Principal:    "ci",
+				ClientPolicy: sdkconfig.ClientPolicy{AllowedModels: []string{"gpt-*"}},
+			},
+			{SAN: "*@example.com", ClientPolicy: sdkconfig.ClientPolicy{Label: "staff"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("newProvider: %v", err)
+	}
+	p := built.(*provider)
+	p.verify = verifier.Verify
+
+	result, err := p.Authenticate(context.Background(), certRequest(ca.issue(t, pkix.Name{CommonName: "ci-runner-7", Organization: []string{"Example"}})))
+	if err != nil {
+		t.Fatalf("CI certificate rejected: %v", err)
+	}
+	if result.Principal != "ci" || result.Provider != sdkconfig.DefaultClientCertProviderName {
+		t.Fatalf("CI result = %+v", result)
+	}
+	if result.Metadata[sdkaccess.MetadataAllowedModels] != "gpt-*" || result.Metadata[MetadataSubject] != "CN=ci-runner-7,O=Example" {
+		t.Fatalf("CI metadata = %v", result.Metadata)
+	}
+
+	result, err = p.Authenticate(context.Background(), certRequest(ca.issue(t, pkix.Name{CommonName: "Alice"}, "alice@example.com")))
+	if err != nil {
+		t.Fatalf("staff certificate rejected: %v", err)
+	}
+	if result.Principal != "alice@example.com" || result.Metadata[sdkaccess.MetadataLabel] != "staff" {
+		t.Fatalf("staff result = %+v", result)
+	}
+
+	if _, err = p.Authenticate(context.Background(), certRequest(ca.issue(t, pkix.Name{CommonName: "guest"}))); !errors.Is(err, sdkaccess.ErrNotHandled) {
+		t.Fatalf("unmapped certificate error = %v, want not handled", err)
+	}
+	if _, err = p.Authenticate(context.Background(), certRequest(nil)); !errors.Is(err, sdkaccess.ErrNotHandled) {
+		t.Fatalf("plain request error = %v, want not handled", err)
+	}
+
+	other := newTestCA(t, "Other CA")
+	forged := other.issue(t, pkix.Name{CommonName: "ci-runner-7"})
+	if _, err = p.Authenticate(context.Background(), certRequest(forged)); !errors.Is(err, sdkaccess.ErrInvalidCredential) {
+		t.Fatalf("foreign certificate error = %v, want invalid credential", err)
+	}
+
+	// Rotating the bundle on disk takes effect on reload without rebuilding the provider.
+	if err = os.WriteFile(caPath, append(ca.pem, other.pem...), 0o600); err != nil {
+		t.Fatalf("rotate CA bundle: %v", err)
+	}
+	if changed, errReload := verifier.Reload(); errReload != nil || !changed {
+		t.Fatalf("Reload() = %v, %v, want changed", changed, errReload)
+	}
+	if _, err = p.Authenticate(context.Background(), certRequest(forged)); err != nil {
+		t.Fatalf("certificate of the rotated-in CA rejected: %v", err)
+	}
+}
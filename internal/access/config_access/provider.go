@@ -22,9 +22,11 @@ func Register() {
 type provider struct {
 	name string
 	keys map[string]struct{}
+	// clients holds the label, model restrictions and limits of structured client keys.
+	clients map[string]sdkconfig.ClientKey
 }
 
-func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
+func newProvider(cfg *sdkconfig.AccessProvider, root *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
 	name := cfg.Name
 	if name == "" {
 		name = sdkconfig.DefaultAccessProviderName
@@ -36,7 +38,18 @@ func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkacce
 		}
 		keys[key] = struct{}{}
 	}
-	return &provider{name: name, keys: keys}, nil
+	var clients map[string]sdkconfig.ClientKey
+	if root != nil && len(root.ClientKeys) > 0 {
+		clients = make(map[string]sdkconfig.ClientKey, len(root.ClientKeys))
+		for _, entry := range root.ClientKeys {
+			if entry.Key == "" {
+				continue
+			}
+			keys[entry.Key] = struct{}{}
+			clients[entry.Key] = entry
+		}
+	}
+	return &provider{name: name, keys: keys, clients: clients}, nil
 }
 
 func (p *provider) Identifier() string {
@@ -84,12 +97,16 @@ func (p *provider) Authenticate(_ context.Context, r *http.Request) (*sdkaccess.
 			continue
 		}
 		if _, ok := p.keys[candidate.value]; ok {
+			metadata := map[string]string{
+				"source": candidate.source,
+			}
+			if client, isClient := p.clients[candidate.value]; isClient {
+				sdkaccess.ApplyPolicyMetadata(metadata, client.ClientPolicy)
+			}
 			return &sdkaccess.Result{
 				Provider:  p.Identifier(),
 				Principal: candidate.value,
-				Metadata: map[string]string{
-					"source": candidate.source,
-				},
+				Metadata:  metadata,
 			}, nil
 		}
 	}
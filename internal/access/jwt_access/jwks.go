@@ -0,0 +1,288 @@
+package jwtaccess
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxJWKSBytes bounds a fetched key set document.
+	maxJWKSBytes = 1 << 20
+	// jwksFetchTimeout bounds a single key set download.
+	jwksFetchTimeout = 10 * time.Second
+	// jwksMissRefetchInterval throttles the reloads triggered by tokens signed with an unknown key.
+	jwksMissRefetchInterval = 30 * time.Second
+)
+
+// verificationKey is a public key of the key set.
+type verificationKey struct {
+	id  string
+	alg string
+	key crypto.PublicKey
+}
+
+// keySet caches the keys of a JWKS document read from a file or URL. The document is reloaded
+// once the refresh interval elapsed, and early when a token names a key it does not contain, so
+// signing key rotations are picked up without a restart. Reloads run in the background while the
+// cached keys keep serving; only callers without a matching cached key wait for them.
+type keySet struct {
+	url     string
+	file    string
+	refresh time.Duration
+	client  *http.Client
+
+	mu          sync.Mutex
+	keys        []verificationKey
+	loadedAt    time.Time
+	lastAttempt time.Time
+	// reloading is closed once the reload in progress finishes; nil while none runs.
+	reloading chan struct{}
+}
+
+func newKeySet(url, file string, refresh time.Duration) *keySet {
+	return &keySet{
+		url:     url,
+		file:    file,
+		refresh: refresh,
+		client:  &http.Client{Timeout: jwksFetchTimeout},
+	}
+}
+
+// source describes where the key set is loaded from.
+func (s *keySet) source() string {
+	if s.url != "" {
+		return s.url
+	}
+	return s.file
+}
+
+// lookup returns the keys that may have signed a token with the given key ID and algorithm. An
+// empty key ID matches every key usable with alg.
+func (s *keySet) lookup(ctx context.Context, kid, alg string) []crypto.PublicKey {
+	now := time.Now()
+	s.mu.Lock()
+	matches := s.matchLocked(kid, alg)
+	unknownKey := len(matches) == 0 && kid != "" && !s.loadedAt.IsZero() && now.Sub(s.lastAttempt) >= jwksMissRefetchInterval
+	done := s.reloading
+	if done == nil && (s.reloadDueLocked(now) || unknownKey) {
+		done = s.startReloadLocked(ctx, now)
+	}
+	s.mu.Unlock()
+	if len(matches) > 0 || done == nil {
+		return matches
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.matchLocked(kid, alg)
+}
+
+// reloadDueLocked reports whether the refresh interval elapsed. Until a first load succeeds,
+// attempts are spaced like the reloads for unknown keys.
+func (s *keySet) reloadDueLocked(now time.Time) bool {
+	if s.loadedAt.IsZero() {
+		return s.lastAttempt.IsZero() || now.Sub(s.lastAttempt) >= jwksMissRefetchInterval
+	}
+	return now.Sub(s.loadedAt) >= s.refresh
+}
+
+func (s *keySet) matchLocked(kid, alg string) []crypto.PublicKey {
+	var matches []crypto.PublicKey
+	for _, key := range s.keys {
+		if kid != "" && key.id != kid {
+			continue
+		}
+		if key.alg != "" && key.alg != alg {
+			continue
+		}
+		if !keyFitsAlgorithm(key.key, alg) {
+			continue
+		}
+		matches = append(matches, key.key)
+	}
+	return matches
+}
+
+// startReloadLocked starts a background reload and returns the channel closed once it finishes.
+func (s *keySet) startReloadLocked(ctx context.Context, now time.Time) chan struct{} {
+	s.lastAttempt = now
+	done := make(chan struct{})
+	s.reloading = done
+	go s.reload(context.WithoutCancel(ctx), now, done)
+	return done
+}
+
+// reload fetches and parses the key set without holding the lock, then replaces the cached keys.
+// A failed reload keeps the previous keys.
+func (s *keySet) reload(ctx context.Context, now time.Time, done chan struct{}) {
+	data, err := s.fetch(ctx)
+	var keys []verificationKey
+	if err == nil {
+		keys, err = parseJWKS(data)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloading = nil
+	defer close(done)
+	if err == nil {
+		s.keys = keys
+		s.loadedAt = now
+		return
+	}
+	log.Warnf("jwt access: failed to load JWKS from %s: %v", s.source(), err)
+	if s.loadedAt.IsZero() {
+		return
+	}
+	// Keep serving the stale keys, but retry on the next refresh interval rather than on every request.
+	s.loadedAt = now
+}
+
+func (s *keySet) fetch(ctx context.Context) ([]byte, error) {
+	if s.url == "" {
+		return os.ReadFile(s.file)
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			log.Debugf("jwt access: close JWKS response body: %v", errClose)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+}
+
+// jsonWebKey is the subset of RFC 7517 fields needed to build verification keys.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS decodes the signature keys of a JWKS document. Keys of unsupported types are skipped.
+func parseJWKS(data []byte) ([]verificationKey, error) {
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("decode JWKS: %w", err)
+	}
+	keys := make([]verificationKey, 0, len(document.Keys))
+	for _, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Debugf("jwt access: skipping JWKS key %q: %v", jwk.Kid, err)
+			continue
+		}
+		keys = append(keys, verificationKey{id: jwk.Kid, alg: jwk.Alg, key: key})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no usable signature keys")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		if n.BitLen() < 2048 || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("unsupported RSA key parameters")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		var ecdhCurve ecdh.Curve
+		switch k.Crv {
+		case "P-256":
+			curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		size := (curve.Params().BitSize + 7) / 8
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("invalid EC coordinates")
+		}
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid EC point: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,259 @@
+// Package jwtaccess provides the jwt access provider, which authenticates clients by signed bearer
+// tokens issued by an SSO identity provider and verified against its JWKS.
+package jwtaccess
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSubjectClaim = "sub"
+	defaultGroupsClaim  = "groups"
+	defaultJWKSRefresh  = 5 * time.Minute
+	defaultLeeway       = 60 * time.Second
+)
+
+// Metadata keys set from every verified token.
+const (
+	MetadataSubject = "subject"
+	MetadataIssuer  = "issuer"
+	MetadataGroups  = "groups"
+)
+
+var registerOnce sync.Once
+
+// Register ensures the jwt provider is available to the access manager.
+func Register() {
+	registerOnce.Do(func() {
+		sdkaccess.RegisterProvider(sdkconfig.AccessProviderTypeJWT, newProvider)
+	})
+}
+
+type provider struct {
+	name         string
+	keys         *keySet
+	validation   claimValidation
+	subjectClaim string
+	groupsClaim  string
+	// metadataClaims maps Result.Metadata keys to the claims filling them.
+	metadataClaims map[string]string
+	now            func() time.Time
+}
+
+// newProvider builds a jwt provider from the options of cfg.Config:
+//   - jwks-url or jwks-file: where the signing keys are published (one is required)
+//   - jwks-refresh: seconds between key set reloads, 300 by default
+//   - issuer: the required iss claim
+//   - audience: one or a list of accepted aud values
+//   - subject-claim: the claim naming the principal, "sub" by default
+//   - groups-claim: the claim listing the groups, "groups" by default
+//   - metadata-claims: a map of metadata keys, such as allowed-models or rpm, to claim names
+//   - leeway: seconds of clock skew tolerated on exp, nbf and iat, 60 by default
+func newProvider(cfg *sdkconfig.AccessProvider, _ *sdkconfig.SDKConfig) (sdkaccess.Provider, error) {
+	options := cfg.Config
+	name := strings.TrimSpace(cfg.Name)
+	if name == "" {
+		name = sdkconfig.AccessProviderTypeJWT
+	}
+	jwksURL := stringOption(options, "jwks-url")
+	jwksFile := stringOption(options, "jwks-file")
+	if (jwksURL == "") == (jwksFile == "") {
+		return nil, fmt.Errorf("jwt access provider %s: exactly one of jwks-url and jwks-file is required", name)
+	}
+	refresh := defaultJWKSRefresh
+	if seconds, ok := intOption(options, "jwks-refresh"); ok && seconds > 0 {
+		refresh = time.Duration(seconds) * time.Second
+	}
+	leeway := defaultLeeway
+	if seconds, ok := intOption(options, "leeway"); ok && seconds >= 0 {
+		leeway = time.Duration(seconds) * time.Second
+	}
+	p := &provider{
+		name: name,
+		keys: newKeySet(jwksURL, jwksFile, refresh),
+		validation: claimValidation{
+			issuer:    stringOption(options, "issuer"),
+			audiences: stringListOption(options, "audience"),
+			leeway:    leeway,
+		},
+		subjectClaim:   stringOption(options, "subject-claim"),
+		groupsClaim:    stringOption(options, "groups-claim"),
+		metadataClaims: stringMapOption(options, "metadata-claims"),
+		now:            time.Now,
+	}
+	if p.subjectClaim == "" {
+		p.subjectClaim = defaultSubjectClaim
+	}
+	if p.groupsClaim == "" {
+		p.groupsClaim = defaultGroupsClaim
+	}
+	if p.validation.issuer == "" && len(p.validation.audiences) == 0 {
+		log.Warnf("jwt access provider %s accepts tokens of any issuer and audience signed by its key set", name)
+	}
+	return p, nil
+}
+
+func (p *provider) Identifier() string {
+	if p == nil || p.name == "" {
+		return sdkconfig.AccessProviderTypeJWT
+	}
+	return p.name
+}
+
+// Authenticate verifies the bearer token of r. Credentials that are not shaped like a JWT are left
+// to the other providers.
+func (p *provider) Authenticate(ctx context.Context, r *http.Request) (*sdkaccess.Result, error) {
+	if p == nil {
+		return nil, sdkaccess.ErrNotHandled
+	}
+	candidates := []struct {
+		value  string
+		source string
+	}{
+		{extractBearerToken(r.Header.Get("Authorization")), "authorization"},
+		{r.Header.Get("X-Api-Key"), "x-api-key"},
+		{r.Header.Get("X-Goog-Api-Key"), "x-goog-api-key"},
+	}
+	token, source, present := "", "", false
+	for _, candidate := range candidates {
+		if candidate.value == "" {
+			continue
+		}
+		present = true
+		if looksLikeJWT(candidate.value) {
+			token, source = candidate.value, candidate.source
+			break
+		}
+	}
+	if token == "" {
+		if present {
+			return nil, sdkaccess.ErrNotHandled
+		}
+		return nil, sdkaccess.ErrNoCredentials
+	}
+
+	claims, err := verifyToken(ctx, token, p.keys)
+	if err == nil {
+		err = p.validation.validate(claims, p.now())
+	}
+	if err != nil {
+		log.Debugf("jwt access provider %s rejected token: %v", p.Identifier(), err)
+		return nil, sdkaccess.ErrInvalidCredential
+	}
+	rawSubject, _ := lookupClaim(claims, p.subjectClaim)
+	subject := strings.Join(claimStrings(rawSubject), ",")
+	if subject == "" {
+		log.Debugf("jwt access provider %s rejected token without %s claim", p.Identifier(), p.subjectClaim)
+		return nil, sdkaccess.ErrInvalidCredential
+	}
+
+	metadata := map[string]string{
+		"source":        source,
+		MetadataSubject: subject,
+	}
+	if issuer, ok := claims["iss"].(string); ok && issuer != "" {
+		metadata[MetadataIssuer] = issuer
+	}
+	if rawGroups, ok := lookupClaim(claims, p.groupsClaim); ok {
+		if groups := claimStrings(rawGroups); len(groups) > 0 {
+			metadata[MetadataGroups] = strings.Join(groups, ",")
+		}
+	}
+	for key, claim := range p.metadataClaims {
+		if raw, ok := lookupClaim(claims, claim); ok {
+			if values := claimStrings(raw); len(values) > 0 {
+				metadata[key] = strings.Join(values, ",")
+			}
+		}
+	}
+	return &sdkaccess.Result{
+		Provider:  p.Identifier(),
+		Principal: subject,
+		Metadata:  metadata,
+	}, nil
+}
+
+func extractBearerToken(header string) string {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+func stringOption(options map[string]any, key string) string {
+	value, _ := options[key].(string)
+	return strings.TrimSpace(value)
+}
+
+// stringListOption reads a string or a list of strings.
+func stringListOption(options map[string]any, key string) []string {
+	var out []string
+	switch value := options[key].(type) {
+	case string:
+		out = append(out, value)
+	case []any:
+		for _, item := range value {
+			if text, ok := item.(string); ok {
+				out = append(out, text)
+			}
+		}
+	case []string:
+		out = append(out, value...)
+	}
+	trimmed := out[:0]
+	for _, item := range out {
+		if item = strings.TrimSpace(item); item != "" {
+			trimmed = append(trimmed, item)
+		}
+	}
+	return trimmed
+}
+
+func stringMapOption(options map[string]any, key string) map[string]string {
+	out := make(map[string]string)
+	switch value := options[key].(type) {
+	case map[string]any:
+		for k, v := range value {
+			if text, ok := v.(string); ok && strings.TrimSpace(k) != "" && strings.TrimSpace(text) != "" {
+				out[strings.TrimSpace(k)] = strings.TrimSpace(text)
+			}
+		}
+	case map[string]string:
+		for k, v := range value {
+			if strings.TrimSpace(k) != "" && strings.TrimSpace(v) != "" {
+				out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func intOption(options map[string]any, key string) (int, bool) {
+	switch value := options[key].(type) {
+	case int:
+		return value, true
+	case int64:
+		return int(value), true
+	case float64:
+		return int(value), true
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
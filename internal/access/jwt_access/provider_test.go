@@ -0,0 +1,258 @@
+package jwtaccess
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func rsaJWK(t *testing.T, kid string, key *rsa.PrivateKey) map[string]string {
+	t.Helper()
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func jwksDocument(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatalf("marshal JWKS: %v", err)
+	}
+	return data
+}
+
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		sig, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("sign RS256: %v", err)
+		}
+		signature = sig
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatalf("sign ES256: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func bearerRequest(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestProvider_AuthenticatesTokenFromJWKSFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate EC key: %v", err)
+	}
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+	if err = os.WriteFile(jwksPath, jwksDocument(t, rsaJWK(t, "rsa-1", rsaKey), ecJWK("ec-1", ecKey)), 0o600); err != nil {
+		t.Fatalf("write JWKS: %v", err)
+	}
+
+	built, err := newProvider(&sdkconfig.AccessProvider{
+		Name: "sso",
+		Type: sdkconfig.AccessProviderTypeJWT,
+		Config: map[string]any{
+			"jwks-file":       jwksPath,
+			"issuer":          "https://sso.example.com",
+			"audience":        []any{"cli-proxy"},
+			"metadata-claims": map[string]any{sdkaccess.MetadataAllowedModels: "models"},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("newProvider: %v", err)
+	}
+	p := built.(*provider)
+	now := time.Now()
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"iss":    "https://sso.example.com",
+			"aud":    "cli-proxy",
+			"sub":    "alice@example.com",
+			"groups": []string{"eng", "ml"},
+			"models": []string{"gpt-*", "claude-*"},
+			"exp":    now.Add(time.Hour).Unix(),
+			"iat":    now.Unix(),
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+
+	for _, tc := range []struct {
+		alg, kid string
+		key      crypto.Signer
+	}{{"RS256", "rsa-1", rsaKey}, {"ES256", "ec-1", ecKey}} {
+		result, errAuth := p.Authenticate(context.Background(), bearerRequest(signToken(t, tc.alg, tc.kid, tc.key, claims(nil))))
+		if errAuth != nil {
+			t.Fatalf("%s token rejected: %v", tc.alg, errAuth)
+		}
+		if result.Principal != "alice@example.com" || result.Provider != "sso" {
+			t.Fatalf("%s result = %+v", tc.alg, result)
+		}
+		if result.Metadata[MetadataGroups] != "eng,ml" || result.Metadata[sdkaccess.MetadataAllowedModels] != "gpt-*,claude-*" {
+			t.Fatalf("%s metadata = %v", tc.alg, result.Metadata)
+		}
+	}
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	rejected := map[string]string{
+		"expired":         signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"exp": now.Add(-time.Hour).Unix()})),
+		"wrong audience":  signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"aud": []string{"other"}})),
+		"wrong issuer":    signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"iss": "https://evil.example.com"})),
+		"forged":          signToken(t, "RS256", "rsa-1", otherKey, claims(nil)),
+		"no subject":      signToken(t, "RS256", "rsa-1", rsaKey, claims(map[string]any{"sub": ""})),
+		"unsigned (none)": base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + ".e30.",
+	}
+	for name, token := range rejected {
+		if _, errAuth := p.Authenticate(context.Background(), bearerRequest(token)); !errors.Is(errAuth, sdkaccess.ErrInvalidCredential) {
+			t.Fatalf("%s token error = %v, want invalid credential", name, errAuth)
+		}
+	}
+
+	if _, errAuth := p.Authenticate(context.Background(), bearerRequest("sk-plain-api-key")); !errors.Is(errAuth, sdkaccess.ErrNotHandled) {
+		t.Fatalf("plain API key error = %v, want not handled", errAuth)
+	}
+	if _, errAuth := p.Authenticate(context.Background(), httptest.NewRequest(http.MethodGet, "/v1/models", nil)); !errors.Is(errAuth, sdkaccess.ErrNoCredentials) {
+		t.Fatalf("missing credentials error = %v, want no credentials", errAuth)
+	}
+}
+
+func TestProvider_PicksUpRotatedKeysFromJWKSURL(t *testing.T) {
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	var document atomic.Value
+	document.Store(jwksDocument(t, rsaJWK(t, "old", oldKey)))
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write(document.Load().([]byte))
+	}))
+	t.Cleanup(server.Close)
+
+	built, err := newProvider(&sdkconfig.AccessProvider{
+		Type:   sdkconfig.AccessProviderTypeJWT,
+		Config: map[string]any{"jwks-url": server.URL, "audience": "cli-proxy"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("newProvider: %v", err)
+	}
+	p := built.(*provider)
+	claims := map[string]any{"sub": "bob", "aud": "cli-proxy", "exp": time.Now().Add(time.Hour).Unix()}
+
+	if _, err = p.Authenticate(context.Background(), bearerRequest(signToken(t, "RS256", "old", oldKey, claims))); err != nil {
+		t.Fatalf("token of the published key rejected: %v", err)
+	}
+	if _, err = p.Authenticate(context.Background(), bearerRequest(signToken(t, "RS256", "old", oldKey, claims))); err != nil || fetches.Load() != 1 {
+		t.Fatalf("cached key set: err = %v, fetches = %d, want one fetch", err, fetches.Load())
+	}
+
+	document.Store(jwksDocument(t, rsaJWK(t, "new", newKey)))
+	p.keys.lastAttempt = time.Now().Add(-jwksMissRefetchInterval)
+	if _, err = p.Authenticate(context.Background(), bearerRequest(signToken(t, "RS256", "new", newKey, claims))); err != nil {
+		t.Fatalf("token of the rotated key rejected: %v", err)
+	}
+	if fetches.Load() != 2 {
+		t.Fatalf("fetches = %d, want a reload for the unknown key", fetches.Load())
+	}
+}
+
+func TestProvider_ServesCachedKeysWhileJWKSReloads(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	document := jwksDocument(t, rsaJWK(t, "current", key))
+	var fetches atomic.Int32
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-stalled
+		}
+		_, _ = w.Write(document)
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(stalled) })
+
+	built, err := newProvider(&sdkconfig.AccessProvider{
+		Type:   sdkconfig.AccessProviderTypeJWT,
+		Config: map[string]any{"jwks-url": server.URL, "audience": "cli-proxy"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("newProvider: %v", err)
+	}
+	p := built.(*provider)
+	token := signToken(t, "RS256", "current", key, map[string]any{"sub": "carol", "aud": "cli-proxy", "exp": time.Now().Add(time.Hour).Unix()})
+	if _, err = p.Authenticate(context.Background(), bearerRequest(token)); err != nil {
+		t.Fatalf("first load: %v", err)
+	}
+
+	// A due refresh against an unresponsive endpoint must not hold up verification.
+	p.keys.mu.Lock()
+	p.keys.loadedAt = time.Now().Add(-p.keys.refresh)
+	p.keys.mu.Unlock()
+	started := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err = p.Authenticate(context.Background(), bearerRequest(token)); err != nil {
+			t.Fatalf("token rejected during a reload: %v", err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("verification waited %s for the reload", elapsed)
+	}
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if fetches.Load() >= 2 {
+			break
+		}
+	}
+	if fetches.Load() != 2 {
+		t.Fatalf("fetches = %d, want one background reload", fetches.Load())
+	}
+}
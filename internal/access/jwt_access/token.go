@@ -0,0 +1,254 @@
+package jwtaccess
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	// Register the hash implementations used by the RS, PS and ES algorithms.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+var (
+	errMalformedToken   = errors.New("malformed token")
+	errUnknownKey       = errors.New("no key in the key set matches the token")
+	errInvalidSignature = errors.New("invalid signature")
+)
+
+// tokenHeader is the JOSE header of a signed token.
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// looksLikeJWT reports whether token has the three dot-separated segments of a compact JWS, so
+// opaque API keys can be left to other providers.
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2 && strings.HasPrefix(token, "eyJ")
+}
+
+// verifyToken checks the signature of a compact JWS against keys and returns its claims.
+func verifyToken(ctx context.Context, token string, keys *keySet) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMalformedToken
+	}
+	headerJSON, errHeader := base64.RawURLEncoding.DecodeString(parts[0])
+	payloadJSON, errPayload := base64.RawURLEncoding.DecodeString(parts[1])
+	signature, errSignature := base64.RawURLEncoding.DecodeString(parts[2])
+	if errHeader != nil || errPayload != nil || errSignature != nil {
+		return nil, errMalformedToken
+	}
+	var header tokenHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, errMalformedToken
+	}
+	if _, ok := algorithmHash(header.Alg); !ok {
+		return nil, fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+
+	candidates := keys.lookup(ctx, header.Kid, header.Alg)
+	if len(candidates) == 0 {
+		return nil, errUnknownKey
+	}
+	signingInput := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range candidates {
+		if verifySignature(header.Alg, key, signingInput, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errInvalidSignature
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payloadJSON))
+	decoder.UseNumber()
+	var claims map[string]any
+	if err := decoder.Decode(&claims); err != nil || claims == nil {
+		return nil, errMalformedToken
+	}
+	return claims, nil
+}
+
+// algorithmHash returns the digest of a supported signature algorithm; EdDSA signs the message
+// itself and reports a zero hash.
+func algorithmHash(alg string) (crypto.Hash, bool) {
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		return crypto.SHA256, true
+	case "RS384", "PS384", "ES384":
+		return crypto.SHA384, true
+	case "RS512", "PS512", "ES512":
+		return crypto.SHA512, true
+	case "EdDSA":
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// keyFitsAlgorithm reports whether key can verify signatures of alg.
+func keyFitsAlgorithm(key crypto.PublicKey, alg string) bool {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		switch alg {
+		case "ES256":
+			return k.Curve.Params().BitSize == 256
+		case "ES384":
+			return k.Curve.Params().BitSize == 384
+		case "ES512":
+			return k.Curve.Params().BitSize == 521
+		}
+		return false
+	case ed25519.PublicKey:
+		return alg == "EdDSA"
+	default:
+		return false
+	}
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signingInput, signature []byte) bool {
+	hash, ok := algorithmHash(alg)
+	if !ok || !keyFitsAlgorithm(key, alg) {
+		return false
+	}
+	if alg == "EdDSA" {
+		return ed25519.Verify(key.(ed25519.PublicKey), signingInput, signature)
+	}
+	hasher := hash.New()
+	hasher.Write(signingInput)
+	digest := hasher.Sum(nil)
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if strings.HasPrefix(alg, "PS") {
+			return rsa.VerifyPSS(k, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		}
+		return rsa.VerifyPKCS1v15(k, hash, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(k, digest, r, s)
+	default:
+		return false
+	}
+}
+
+// claimValidation holds the registered claim checks of a provider.
+type claimValidation struct {
+	issuer    string
+	audiences []string
+	leeway    time.Duration
+}
+
+// validate checks the issuer, audience and validity window of claims at now. Tokens must expire.
+func (v claimValidation) validate(claims map[string]any, now time.Time) error {
+	if v.issuer != "" {
+		if issuer, _ := claims["iss"].(string); issuer != v.issuer {
+			return fmt.Errorf("unexpected issuer %q", issuer)
+		}
+	}
+	if len(v.audiences) > 0 && !audienceMatches(claims["aud"], v.audiences) {
+		return fmt.Errorf("token audience not accepted")
+	}
+	expiresAt, ok := numericDate(claims["exp"])
+	if !ok {
+		return fmt.Errorf("token has no expiry")
+	}
+	if now.After(expiresAt.Add(v.leeway)) {
+		return fmt.Errorf("token expired at %s", expiresAt.Format(time.RFC3339))
+	}
+	if notBefore, ok := numericDate(claims["nbf"]); ok && now.Add(v.leeway).Before(notBefore) {
+		return fmt.Errorf("token not valid before %s", notBefore.Format(time.RFC3339))
+	}
+	if issuedAt, ok := numericDate(claims["iat"]); ok && now.Add(v.leeway).Before(issuedAt) {
+		return fmt.Errorf("token issued in the future")
+	}
+	return nil
+}
+
+func audienceMatches(raw any, accepted []string) bool {
+	for _, audience := range claimStrings(raw) {
+		for _, want := range accepted {
+			if audience == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// numericDate converts a NumericDate claim to a time.
+func numericDate(raw any) (time.Time, bool) {
+	number, ok := raw.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+// lookupClaim resolves a claim by name, descending into nested objects on dots so that claims
+// such as "realm_access.roles" can be addressed.
+func lookupClaim(claims map[string]any, name string) (any, bool) {
+	if value, ok := claims[name]; ok {
+		return value, true
+	}
+	var current any = claims
+	for _, segment := range strings.Split(name, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[segment]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// claimStrings flattens a string, number, boolean or array claim into strings.
+func claimStrings(raw any) []string {
+	switch value := raw.(type) {
+	case string:
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	case json.Number:
+		return []string{value.String()}
+	case bool:
+		return []string{strconv.FormatBool(value)}
+	case []any:
+		out := make([]string, 0, len(value))
+		for _, item := range value {
+			out = append(out, claimStrings(item)...)
+		}
+		return out
+	default:
+		return nil
+	}
+}
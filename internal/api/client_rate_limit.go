@@ -0,0 +1,189 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+)
+
+// clientIdleTimeout is how long an idle client keeps its rate limit state; a bucket idle this long
+// has refilled completely anyway.
+const clientIdleTimeout = time.Minute
+
+var clientLimiter = newClientRateLimiter()
+
+// setClientRateLimitDefault sets the rate limit applied to clients without an override.
+func setClientRateLimitDefault(limit config.ClientRateLimit) {
+	clientLimiter.mu.Lock()
+	clientLimiter.defaults = limit
+	clientLimiter.mu.Unlock()
+}
+
+// clientBucket is the request token bucket and in-flight count of a single client key.
+type clientBucket struct {
+	tokens   float64
+	updated  time.Time
+	inFlight int
+}
+
+// clientRateLimiter throttles authenticated clients by requests per minute and in-flight requests.
+type clientRateLimiter struct {
+	mu       sync.Mutex
+	defaults config.ClientRateLimit
+	clients  map[string]*clientBucket
+	swept    time.Time
+}
+
+// clientAdmission reports the outcome of admitting a request and the state of the RPM bucket.
+type clientAdmission struct {
+	rpm        int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+	// rejected names the exhausted limit; empty when the request was admitted.
+	rejected string
+	limit    int
+}
+
+func newClientRateLimiter() *clientRateLimiter {
+	return &clientRateLimiter{clients: make(map[string]*clientBucket)}
+}
+
+// limitFor merges the overrides in access metadata over the default limit.
+func (l *clientRateLimiter) limitFor(metadata map[string]string) config.ClientRateLimit {
+	limit := l.defaults
+	if rpm := metadataInt(metadata, sdkaccess.MetadataRPM); rpm > 0 {
+		limit.RPM = rpm
+	}
+	if maxConcurrent := metadataInt(metadata, sdkaccess.MetadataMaxConcurrent); maxConcurrent > 0 {
+		limit.MaxConcurrent = maxConcurrent
+	}
+	return limit
+}
+
+// admit takes a request slot for key. The returned release must be called once the request
+// completes when the admission was not rejected.
+func (l *clientRateLimiter) admit(key string, metadata map[string]string, now time.Time) (clientAdmission, func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	limit := l.limitFor(metadata)
+	admission := clientAdmission{rpm: limit.RPM}
+	if limit.RPM <= 0 && limit.MaxConcurrent <= 0 {
+		return admission, func() {}
+	}
+	l.sweepLocked(now)
+
+	bucket, ok := l.clients[key]
+	if !ok {
+		bucket = &clientBucket{tokens: float64(limit.RPM), updated: now}
+		l.clients[key] = bucket
+	}
+	perSecond := float64(limit.RPM) / 60
+	if limit.RPM > 0 {
+		if elapsed := now.Sub(bucket.updated).Seconds(); elapsed > 0 {
+			bucket.tokens += elapsed * perSecond
+		}
+		bucket.tokens = math.Min(bucket.tokens, float64(limit.RPM))
+	}
+	bucket.updated = now
+
+	switch {
+	case limit.MaxConcurrent > 0 && bucket.inFlight >= limit.MaxConcurrent:
+		admission.rejected = sdkaccess.MetadataMaxConcurrent
+		admission.limit = limit.MaxConcurrent
+		admission.retryAfter = time.Second
+	case limit.RPM > 0 && bucket.tokens < 1:
+		admission.rejected = sdkaccess.MetadataRPM
+		admission.limit = limit.RPM
+		admission.retryAfter = time.Duration((1 - bucket.tokens) / perSecond * float64(time.Second))
+	default:
+		if limit.RPM > 0 {
+			bucket.tokens--
+		}
+		bucket.inFlight++
+	}
+	if limit.RPM > 0 {
+		admission.remaining = int(math.Max(bucket.tokens, 0))
+		admission.reset = time.Duration((float64(limit.RPM) - bucket.tokens) / perSecond * float64(time.Second))
+	}
+	if admission.rejected != "" {
+		return admission, func() {}
+	}
+
+	// Releasing only frees the in-flight slot; updated stays the refill clock of the bucket, so
+	// tokens keep accruing for the time the request ran.
+	var once sync.Once
+	return admission, func() {
+		once.Do(func() {
+			l.mu.Lock()
+			bucket.inFlight--
+			l.mu.Unlock()
+		})
+	}
+}
+
+// sweepLocked forgets idle clients at most once per idle timeout.
+func (l *clientRateLimiter) sweepLocked(now time.Time) {
+	if now.Sub(l.swept) < clientIdleTimeout {
+		return
+	}
+	l.swept = now
+	for key, bucket := range l.clients {
+		if bucket.inFlight == 0 && now.Sub(bucket.updated) >= clientIdleTimeout {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// admitClientRequest applies the HTTP rate limit of the authenticated client and sets the rate
+// limit response headers: the Anthropic ones on the Claude messages API, the OpenAI ones elsewhere.
+// It reports whether the request may proceed; release must be called once it has completed.
+func admitClientRequest(c *gin.Context, result *sdkaccess.Result) (release func(), admitted bool) {
+	now := time.Now()
+	admission, release := clientLimiter.admit(result.Principal, result.Metadata, now)
+	path := c.Request.URL.Path
+	if admission.rpm > 0 {
+		remaining := strconv.Itoa(admission.remaining)
+		if isClaudeMessagesPath(path) {
+			c.Header("anthropic-ratelimit-requests-limit", strconv.Itoa(admission.rpm))
+			c.Header("anthropic-ratelimit-requests-remaining", remaining)
+			c.Header("anthropic-ratelimit-requests-reset", now.Add(admission.reset).UTC().Format(time.RFC3339))
+		} else {
+			c.Header("x-ratelimit-limit-requests", strconv.Itoa(admission.rpm))
+			c.Header("x-ratelimit-remaining-requests", remaining)
+			c.Header("x-ratelimit-reset-requests", admission.reset.Round(time.Millisecond).String())
+		}
+	}
+	if admission.rejected == "" {
+		return release, true
+	}
+
+	message := fmt.Sprintf("API key rate limit of %d requests per minute reached", admission.limit)
+	if admission.rejected == sdkaccess.MetadataMaxConcurrent {
+		message = fmt.Sprintf("API key limit of %d concurrent requests reached", admission.limit)
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(admission.retryAfter.Seconds()))))
+	c.Data(http.StatusTooManyRequests, "application/json", keyLimitErrorBody(path, message))
+	c.Abort()
+	return nil, false
+}
+
+func isClaudeMessagesPath(path string) bool {
+	return strings.HasSuffix(path, "/messages") || strings.HasSuffix(path, "/messages/count_tokens")
+}
+
+func metadataInt(metadata map[string]string, key string) int {
+	value, err := strconv.Atoi(strings.TrimSpace(metadata[key]))
+	if err != nil || value < 0 {
+		return 0
+	}
+	return value
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+)
+
+func TestClientRateLimiter_RPMAndConcurrency(t *testing.T) {
+	limiter := newClientRateLimiter()
+	limiter.defaults = config.ClientRateLimit{RPM: 2}
+	now := time.Now()
+
+	first, releaseFirst := limiter.admit("client", nil, now)
+	second, _ := limiter.admit("client", nil, now)
+	if first.rejected != "" || second.rejected != "" {
+		t.Fatalf("requests within the RPM limit were rejected: %+v %+v", first, second)
+	}
+	if second.remaining != 0 || second.reset != time.Minute {
+		t.Fatalf("remaining = %d, reset = %s; want 0 and 1m0s", second.remaining, second.reset)
+	}
+	third, _ := limiter.admit("client", nil, now)
+	if third.rejected != sdkaccess.MetadataRPM || third.retryAfter != 30*time.Second {
+		t.Fatalf("over RPM admission = %+v, want rpm rejection retrying in 30s", third)
+	}
+	if later, _ := limiter.admit("client", nil, now.Add(30*time.Second)); later.rejected != "" {
+		t.Fatalf("admission after refill = %+v, want admitted", later)
+	}
+	releaseFirst()
+
+	override := map[string]string{sdkaccess.MetadataMaxConcurrent: "1", sdkaccess.MetadataRPM: "100"}
+	_, release := limiter.admit("override", override, now)
+	if blocked, _ := limiter.admit("override", override, now); blocked.rejected != sdkaccess.MetadataMaxConcurrent {
+		t.Fatalf("second concurrent admission = %+v, want concurrency rejection", blocked)
+	}
+	release()
+	if admitted, _ := limiter.admit("override", override, now); admitted.rejected != "" {
+		t.Fatalf("admission after release = %+v, want admitted", admitted)
+	}
+}
+
+func TestClientRateLimiter_LongRequestKeepsRefilling(t *testing.T) {
+	limiter := newClientRateLimiter()
+	limiter.defaults = config.ClientRateLimit{RPM: 2}
+	started := time.Now().Add(-time.Minute)
+
+	_, release := limiter.admit("long", nil, started)
+	if drained, _ := limiter.admit("long", nil, started); drained.remaining != 0 {
+		t.Fatalf("remaining = %d, want the bucket drained", drained.remaining)
+	}
+	// The first request completes a full refill interval after it started.
+	release()
+	if admitted, _ := limiter.admit("long", nil, time.Now()); admitted.rejected != "" || admitted.remaining != 1 {
+		t.Fatalf("admission after a long request = %+v, want admitted with 1 remaining", admitted)
+	}
+}
+
+type rateLimitedKeyProvider struct{}
+
+func (rateLimitedKeyProvider) Identifier() string { return "rate-limited" }
+
+func (rateLimitedKeyProvider) Authenticate(context.Context, *http.Request) (*sdkaccess.Result, error) {
+	return &sdkaccess.Result{
+		Provider:  "rate-limited",
+		Principal: "rate-limited-key",
+		Metadata:  map[string]string{sdkaccess.MetadataRPM: "1"},
+	}, nil
+}
+
+func TestAuthMiddleware_SetsRateLimitHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := sdkaccess.NewManager()
+	manager.SetProviders([]sdkaccess.Provider{rateLimitedKeyProvider{}})
+	engine := gin.New()
+	engine.Use(AuthMiddleware(manager))
+	engine.POST("/v1/messages", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.POST("/v1/chat/completions", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		engine.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, nil))
+		return rr
+	}
+
+	rr := serve("/v1/chat/completions")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", rr.Code)
+	}
+	if rr.Header().Get("x-ratelimit-limit-requests") != "1" || rr.Header().Get("x-ratelimit-remaining-requests") != "0" {
+		t.Fatalf("openai rate limit headers = %v", rr.Header())
+	}
+
+	rr = serve("/v1/messages")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want 429", rr.Code)
+	}
+	if rr.Header().Get("anthropic-ratelimit-requests-limit") != "1" || rr.Header().Get("Retry-After") == "" {
+		t.Fatalf("anthropic rate limit headers = %v", rr.Header())
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `"rate_limit_error"`) {
+		t.Fatalf("error body = %s", body)
+	}
+}
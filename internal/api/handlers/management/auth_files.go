@@ -421,6 +421,24 @@ func (h *Handler) buildAuthFileEntry(auth *coreauth.Auth) gin.H {
 			log.WithError(err).Warnf("failed to stat auth file %s", path)
 		}
 	}
+	if windows := strings.TrimSpace(authAttribute(auth, "active_windows")); windows != "" {
+		entry["active_windows"] = strings.Split(windows, ";")
+		next, inactive := auth.NextActivation(time.Now())
+		entry["outside_active_windows"] = inactive
+		if errWindows := auth.ActiveWindowsError(); errWindows != nil {
+			entry["inactive_reason"] = "invalid_active_windows"
+			entry["active_windows_error"] = errWindows.Error()
+		}
+		if !next.IsZero() {
+			entry["next_active_at"] = next
+		}
+	}
+	if snapshot := auth.Quota.Snapshot; snapshot != nil {
+		entry["quota_snapshot"] = snapshot
+		if headroom, ok := snapshot.Headroom(time.Now()); ok {
+			entry["quota_headroom"] = headroom
+		}
+	}
 	if claims := extractCodexIDTokenClaims(auth); claims != nil {
 		entry["id_token"] = claims
 	}
@@ -0,0 +1,69 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+)
+
+type clientKeyUsageEntry struct {
+	usage.KeyUsage
+	Label  string                 `json:"label,omitempty"`
+	Limits config.ClientKeyLimits `json:"limits"`
+}
+
+// GetClientKeyUsage returns what each client key consumed in the current UTC day and month next
+// to its configured limits. Configured client keys are listed even before their first request;
+// the optional key query parameter selects a single key.
+func (h *Handler) GetClientKeyUsage(c *gin.Context) {
+	now := time.Now()
+	tracker := usage.GetKeyUsageTracker()
+	configured := make(map[string]config.ClientKey)
+	if h != nil && h.cfg != nil {
+		for _, entry := range h.cfg.ClientKeys {
+			configured[entry.Key] = entry
+		}
+	}
+	filter := strings.TrimSpace(c.Query("key"))
+
+	entries := make([]clientKeyUsageEntry, 0, len(configured))
+	seen := make(map[string]struct{}, len(configured))
+	for _, consumption := range tracker.Snapshot(now) {
+		if filter != "" && consumption.Key != filter {
+			continue
+		}
+		seen[consumption.Key] = struct{}{}
+		client := configured[consumption.Key]
+		entries = append(entries, clientKeyUsageEntry{KeyUsage: consumption, Label: client.Label, Limits: client.Limits})
+	}
+	if h != nil && h.cfg != nil {
+		for _, client := range h.cfg.ClientKeys {
+			if _, ok := seen[client.Key]; ok || (filter != "" && client.Key != filter) {
+				continue
+			}
+			entries = append(entries, clientKeyUsageEntry{KeyUsage: tracker.Usage(client.Key, now), Label: client.Label, Limits: client.Limits})
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"keys": entries})
+}
+
+// DeleteClientKeyUsage resets the consumption of the client key named by the key query
+// parameter, or of every key when it is absent.
+func (h *Handler) DeleteClientKeyUsage(c *gin.Context) {
+	tracker := usage.GetKeyUsageTracker()
+	key := strings.TrimSpace(c.Query("key"))
+	if key == "" {
+		tracker.ResetAll()
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		return
+	}
+	if !tracker.Reset(key) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no usage recorded for key"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
@@ -269,7 +270,18 @@ func normalizeRoutingStrategy(strategy string) (string, bool) {
 		return "round-robin", true
 	case "fill-first", "fillfirst", "ff":
 		return "fill-first", true
+	case "least-latency", "leastlatency", "latency":
+		return "least-latency", true
+	case "least-inflight", "leastinflight", "inflight", "least-loaded":
+		return "least-inflight", true
+	case "sticky", "affinity":
+		return "sticky", true
+	case "most-headroom", "mostheadroom", "headroom":
+		return "most-headroom", true
 	default:
+		if normalized != "" && coreauth.SelectorRegistered(normalized) {
+			return normalized, true
+		}
 		return "", false
 	}
 }
@@ -278,25 +290,58 @@ func normalizeRoutingStrategy(strategy string) (string, bool) {
 func (h *Handler) GetRoutingStrategy(c *gin.Context) {
 	strategy, ok := normalizeRoutingStrategy(h.cfg.Routing.Strategy)
 	if !ok {
-		c.JSON(200, gin.H{"strategy": strings.TrimSpace(h.cfg.Routing.Strategy)})
-		return
+		strategy = strings.TrimSpace(h.cfg.Routing.Strategy)
 	}
-	c.JSON(200, gin.H{"strategy": strategy})
+	overrides := h.cfg.Routing.Overrides
+	if overrides == nil {
+		overrides = []config.RoutingOverride{}
+	}
+	c.JSON(200, gin.H{"strategy": strategy, "overrides": overrides})
 }
+
+// PutRoutingStrategy updates the global strategy ("value") and/or replaces the per-model and
+// per-provider overrides ("overrides"); an empty overrides list removes them all.
 func (h *Handler) PutRoutingStrategy(c *gin.Context) {
 	var body struct {
-		Value *string `json:"value"`
+		Value     *string                   `json:"value"`
+		Overrides *[]config.RoutingOverride `json:"overrides"`
 	}
-	if errBindJSON := c.ShouldBindJSON(&body); errBindJSON != nil || body.Value == nil {
+	if errBindJSON := c.ShouldBindJSON(&body); errBindJSON != nil || (body.Value == nil && body.Overrides == nil) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
 		return
 	}
-	normalized, ok := normalizeRoutingStrategy(*body.Value)
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid strategy"})
-		return
+	var normalized string
+	if body.Value != nil {
+		var ok bool
+		if normalized, ok = normalizeRoutingStrategy(*body.Value); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid strategy"})
+			return
+		}
+	}
+	var overrides []config.RoutingOverride
+	if body.Overrides != nil {
+		overrides = make([]config.RoutingOverride, 0, len(*body.Overrides))
+		for _, entry := range *body.Overrides {
+			strategy, ok := normalizeRoutingStrategy(entry.Strategy)
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid override strategy %q", entry.Strategy)})
+				return
+			}
+			entry.Strategy = strategy
+			if strings.TrimSpace(entry.Model) == "" && strings.TrimSpace(entry.Provider) == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "override requires a model or provider"})
+				return
+			}
+			overrides = append(overrides, entry)
+		}
+	}
+	if body.Value != nil {
+		h.cfg.Routing.Strategy = normalized
+	}
+	if body.Overrides != nil {
+		h.cfg.Routing.Overrides = overrides
+		h.cfg.SanitizeRoutingOverrides()
 	}
-	h.cfg.Routing.Strategy = normalized
 	h.persist(c)
 }
 
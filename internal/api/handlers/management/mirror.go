@@ -0,0 +1,26 @@
+package management
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/mirror"
+)
+
+// GetMirrorComparisons returns the latest shadow traffic comparisons, newest first. The optional
+// limit query parameter bounds the number of entries.
+func (h *Handler) GetMirrorComparisons(c *gin.Context) {
+	limit, errLimit := parseLimit(c.Query("limit"))
+	if errLimit != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid limit: %v", errLimit)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"comparisons": mirror.DefaultLog().Entries(limit)})
+}
+
+// DeleteMirrorComparisons clears the shadow traffic comparison log.
+func (h *Handler) DeleteMirrorComparisons(c *gin.Context) {
+	mirror.DefaultLog().Clear()
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
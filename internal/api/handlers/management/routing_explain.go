@@ -0,0 +1,57 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	sdkhandlers "github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// GetRoutingExplain reports how a request for the model query parameter would be routed: the
+// providers it resolves to, every candidate credential with its block reason, and the credential
+// the current selector would pick. Nothing is executed. The optional key parameter is checked
+// against the configured client API keys and applies its default route tags; the optional tags
+// parameter mirrors the X-Route-Tags request header.
+func (h *Handler) GetRoutingExplain(c *gin.Context) {
+	model := strings.TrimSpace(c.Query("model"))
+	if model == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing model"})
+		return
+	}
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+
+	response := gin.H{"requested_model": model}
+	metadata := make(map[string]any, 3)
+	if tags := strings.TrimSpace(c.Query("tags")); tags != "" {
+		metadata[coreexecutor.RouteTagsMetadataKey] = tags
+	}
+	if key := strings.TrimSpace(c.Query("key")); key != "" {
+		metadata[coreexecutor.ClientAPIKeyMetadataKey] = key
+		accepted := false
+		if h.cfg != nil {
+			for _, configured := range h.cfg.InlineAPIKeys() {
+				if strings.TrimSpace(configured) == key {
+					accepted = true
+					break
+				}
+			}
+		}
+		response["key_accepted"] = accepted
+	}
+
+	providers, normalizedModel, errMsg := sdkhandlers.ResolveModelProviders(model)
+	if errMsg != nil {
+		response["error"] = errMsg.Error.Error()
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	metadata[coreexecutor.RequestedModelMetadataKey] = normalizedModel
+	opts := coreexecutor.Options{Metadata: metadata}
+	response["routing"] = h.authManager.ExplainRouting(c.Request.Context(), providers, normalizedModel, opts)
+	c.JSON(http.StatusOK, response)
+}
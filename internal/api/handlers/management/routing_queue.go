@@ -0,0 +1,16 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRoutingQueue reports the depth and wait statistics of the cooldown admission queue.
+func (h *Handler) GetRoutingQueue(c *gin.Context) {
+	if h == nil || h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"cooldown-queue": h.authManager.CooldownQueueStats()})
+}
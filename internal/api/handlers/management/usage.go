@@ -20,16 +20,21 @@ type usageImportPayload struct {
 	Usage   usage.StatisticsSnapshot `json:"usage"`
 }
 
-// GetUsageStatistics returns the in-memory request statistics snapshot.
+// GetUsageStatistics returns the in-memory request statistics snapshot, along with the live
+// provider shares of models with configured provider weights.
 func (h *Handler) GetUsageStatistics(c *gin.Context) {
 	var snapshot usage.StatisticsSnapshot
 	if h != nil && h.usageStats != nil {
 		snapshot = h.usageStats.Snapshot()
 	}
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"usage":           snapshot,
 		"failed_requests": snapshot.FailureCount,
-	})
+	}
+	if h != nil && h.authManager != nil {
+		response["provider_shares"] = h.authManager.ProviderShares()
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // ExportUsageStatistics returns a complete usage snapshot for backup/migration.
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/api/handlers"
+)
+
+// keyLimitsFromMetadata reads the spending limits an access provider attached to a client.
+func keyLimitsFromMetadata(metadata map[string]string) usage.KeyLimits {
+	parse := func(key string) int64 {
+		value, err := strconv.ParseInt(strings.TrimSpace(metadata[key]), 10, 64)
+		if err != nil || value < 0 {
+			return 0
+		}
+		return value
+	}
+	return usage.KeyLimits{
+		RequestsPerDay: parse(sdkaccess.MetadataRequestsPerDay),
+		TokensPerDay:   parse(sdkaccess.MetadataTokensPerDay),
+		TokensPerMonth: parse(sdkaccess.MetadataTokensPerMonth),
+	}
+}
+
+// abortOnKeyLimits rejects the request with a 429 in the error shape of the called API when the
+// authenticated client has used up one of its spending limits. It reports whether c was aborted.
+func abortOnKeyLimits(c *gin.Context, result *sdkaccess.Result) bool {
+	if result == nil || len(result.Metadata) == 0 {
+		return false
+	}
+	now := time.Now()
+	exceeded, ok := usage.GetKeyUsageTracker().Check(result.Principal, keyLimitsFromMetadata(result.Metadata), now)
+	if !ok {
+		return false
+	}
+	message := fmt.Sprintf("API key %s limit of %d reached; resets at %s", exceeded.Limit, exceeded.Max, exceeded.ResetAt.Format(time.RFC3339))
+	retryAfter := int64(exceeded.ResetAt.Sub(now).Seconds()) + 1
+	c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	c.Header("X-RateLimit-Reset", exceeded.ResetAt.Format(time.RFC3339))
+	c.Data(http.StatusTooManyRequests, "application/json", keyLimitErrorBody(c.Request.URL.Path, message))
+	c.Abort()
+	return true
+}
+
+// keyLimitErrorBody builds a 429 body in the error shape of the API addressed by path.
+func keyLimitErrorBody(path, message string) []byte {
+	var payload any
+	switch {
+	case isClaudeMessagesPath(path):
+		payload = gin.H{"type": "error", "error": gin.H{"type": "rate_limit_error", "message": message}}
+	case strings.HasPrefix(path, "/v1beta") || strings.Contains(path, "v1internal:") || strings.Contains(path, ":generateContent") || strings.Contains(path, ":streamGenerateContent"):
+		payload = gin.H{"error": gin.H{"code": http.StatusTooManyRequests, "message": message, "status": "RESOURCE_EXHAUSTED"}}
+	default:
+		return handlers.BuildErrorResponseBody(http.StatusTooManyRequests, message)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return handlers.BuildErrorResponseBody(http.StatusTooManyRequests, message)
+	}
+	return body
+}
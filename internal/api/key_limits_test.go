@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gin "github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+)
+
+type limitedKeyProvider struct{}
+
+func (limitedKeyProvider) Identifier() string { return "limited" }
+
+func (limitedKeyProvider) Authenticate(context.Context, *http.Request) (*sdkaccess.Result, error) {
+	return &sdkaccess.Result{
+		Provider:  "limited",
+		Principal: "limited-key",
+		Metadata:  map[string]string{sdkaccess.MetadataRequestsPerDay: "2"},
+	}, nil
+}
+
+func TestAuthMiddleware_RejectsKeyOverDailyRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := usage.GetKeyUsageTracker()
+	tracker.Reset("limited-key")
+	t.Cleanup(func() { tracker.Reset("limited-key") })
+
+	manager := sdkaccess.NewManager()
+	manager.SetProviders([]sdkaccess.Provider{limitedKeyProvider{}})
+	engine := gin.New()
+	engine.Use(AuthMiddleware(manager))
+	engine.POST("/v1/messages", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.POST("/v1beta/models/*action", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		engine.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, nil))
+		return rr
+	}
+
+	tracker.Add("limited-key", 100, time.Now())
+	if rr := serve("/v1/messages"); rr.Code != http.StatusOK {
+		t.Fatalf("status under limit = %d, want 200", rr.Code)
+	}
+
+	tracker.Add("limited-key", 100, time.Now())
+	rr := serve("/v1/messages")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("status over limit = %d, want 429", rr.Code)
+	}
+	if rr.Header().Get("Retry-After") == "" {
+		t.Fatal("Retry-After header missing")
+	}
+	if body := rr.Body.String(); !strings.Contains(body, `"type":"rate_limit_error"`) || !strings.Contains(body, `"type":"error"`) {
+		t.Fatalf("claude error body = %s", body)
+	}
+	if body := serve("/v1beta/models/gemini-2.5-pro:generateContent").Body.String(); !strings.Contains(body, `"RESOURCE_EXHAUSTED"`) {
+		t.Fatalf("gemini error body = %s", body)
+	}
+}
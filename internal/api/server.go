@@ -27,6 +27,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/mtls"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
@@ -256,6 +257,7 @@ func NewServer(cfg *config.Config, authManager *auth.Manager, accessManager *sdk
 	managementasset.SetCurrentConfig(cfg)
 	auth.SetQuotaCooldownDisabled(cfg.DisableCooling)
 	misc.SetCodexInstructionsEnabled(cfg.CodexInstructionsEnabled)
+	setClientRateLimitDefault(cfg.ClientRateLimit)
 	// Initialize management handler
 	s.mgmt = managementHandlers.NewHandler(cfg, configFilePath, authManager)
 	if optionState.localPassword != "" {
@@ -574,6 +576,12 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/routing/strategy", s.mgmt.GetRoutingStrategy)
 		mgmt.PUT("/routing/strategy", s.mgmt.PutRoutingStrategy)
 		mgmt.PATCH("/routing/strategy", s.mgmt.PutRoutingStrategy)
+		mgmt.GET("/routing/explain", s.mgmt.GetRoutingExplain)
+		mgmt.GET("/routing/queue", s.mgmt.GetRoutingQueue)
+		mgmt.GET("/mirror/comparisons", s.mgmt.GetMirrorComparisons)
+		mgmt.DELETE("/mirror/comparisons", s.mgmt.DeleteMirrorComparisons)
+		mgmt.GET("/client-keys/usage", s.mgmt.GetClientKeyUsage)
+		mgmt.DELETE("/client-keys/usage", s.mgmt.DeleteClientKeyUsage)
 
 		mgmt.GET("/claude-api-key", s.mgmt.GetClaudeKeys)
 		mgmt.PUT("/claude-api-key", s.mgmt.PutClaudeKeys)
@@ -777,6 +785,20 @@ func (s *Server) Start() error {
 		if cert == "" || key == "" {
 			return fmt.Errorf("failed to start HTTPS server: tls.cert or tls.key is empty")
 		}
+		clientAuth, errMode := mtls.ParseMode(s.cfg.TLS.ClientAuth)
+		if errMode != nil {
+			return fmt.Errorf("failed to start HTTPS server: %v", errMode)
+		}
+		if clientAuth != mtls.ModeNone {
+			if strings.TrimSpace(s.cfg.TLS.ClientCA) == "" {
+				return fmt.Errorf("failed to start HTTPS server: tls.client-ca is required when tls.client-auth is %s", clientAuth)
+			}
+			if _, errLoad := mtls.Default().Load(s.cfg.TLS.ClientCA); errLoad != nil {
+				return fmt.Errorf("failed to start HTTPS server: %v", errLoad)
+			}
+			s.server.TLSConfig = mtls.Default().ServerConfig(clientAuth)
+			log.Infof("client certificate verification enabled (%s)", clientAuth)
+		}
 		log.Debugf("Starting API server on %s with TLS", s.server.Addr)
 		if errServeTLS := s.server.ListenAndServeTLS(cert, key); errServeTLS != nil && !errors.Is(errServeTLS, http.ErrServerClosed) {
 			return fmt.Errorf("failed to start HTTPS server: %v", errServeTLS)
@@ -905,6 +927,21 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		}
 	}
 
+	if oldCfg != nil && oldCfg.TLS.ClientCA != cfg.TLS.ClientCA && mtls.Default().Path() != "" {
+		if _, errLoad := mtls.Default().Load(cfg.TLS.ClientCA); errLoad != nil {
+			log.Errorf("failed to load tls.client-ca, keeping the previous bundle: %v", errLoad)
+		} else {
+			log.Infof("client CA bundle switched to %s", cfg.TLS.ClientCA)
+		}
+	}
+
+	if oldCfg == nil || oldCfg.ClientRateLimit != cfg.ClientRateLimit {
+		setClientRateLimitDefault(cfg.ClientRateLimit)
+		if oldCfg != nil {
+			log.Debugf("client-rate-limit updated from %+v to %+v", oldCfg.ClientRateLimit, cfg.ClientRateLimit)
+		}
+	}
+
 	if oldCfg == nil || oldCfg.DisableCooling != cfg.DisableCooling {
 		auth.SetQuotaCooldownDisabled(cfg.DisableCooling)
 		if oldCfg != nil {
@@ -1038,8 +1075,9 @@ func (s *Server) SetWebsocketAuthChangeHandler(fn func(bool, bool)) {
 // (management handlers moved to internal/api/handlers/management)
 
 // AuthMiddleware returns a Gin middleware handler that authenticates requests
-// using the configured authentication providers. When no providers are available,
-// it allows all requests (legacy behaviour).
+// using the configured authentication providers, rejects clients that used up their spending
+// limits and throttles them by their rate limits. When no providers are available, it allows all
+// requests (legacy behaviour).
 func AuthMiddleware(manager *sdkaccess.Manager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if manager == nil {
@@ -1055,6 +1093,14 @@ func AuthMiddleware(manager *sdkaccess.Manager) gin.HandlerFunc {
 				if len(result.Metadata) > 0 {
 					c.Set("accessMetadata", result.Metadata)
 				}
+				if abortOnKeyLimits(c, result) {
+					return
+				}
+				release, admitted := admitClientRequest(c, result)
+				if !admitted {
+					return
+				}
+				defer release()
 			}
 			c.Next()
 			return
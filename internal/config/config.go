@@ -68,6 +68,16 @@ type Config struct {
 	// Routing controls credential selection behavior.
 	Routing RoutingConfig `yaml:"routing" json:"routing"`
 
+	// ModelFallbacks defines ordered fallback chains used when every credential for a model is exhausted.
+	ModelFallbacks []ModelFallback `yaml:"model-fallbacks,omitempty" json:"model-fallbacks,omitempty"`
+
+	// RateLimits sets default per-credential request and token budgets keyed by provider
+	// (e.g. "claude", "codex", or an openai-compatibility name). Credential rpm/tpm settings override them.
+	RateLimits map[string]RateLimit `yaml:"rate-limits,omitempty" json:"rate-limits,omitempty"`
+
+	// CircuitBreaker configures how upstream errors take a credential's model out of rotation.
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit-breaker,omitempty" json:"circuit-breaker,omitempty"`
+
 	// WebsocketAuth enables or disables authentication for the WebSocket API.
 	WebsocketAuth bool `yaml:"ws-auth" json:"ws-auth"`
 
@@ -120,6 +130,13 @@ type TLSConfig struct {
 	Cert string `yaml:"cert" json:"cert"`
 	// Key is the path to the TLS private key file.
 	Key string `yaml:"key" json:"key"`
+	// ClientCA is the path to the PEM bundle of CAs trusted to issue client certificates. The
+	// bundle is reloaded when the file changes.
+	ClientCA string `yaml:"client-ca,omitempty" json:"client-ca,omitempty"`
+	// ClientAuth selects client certificate verification: "none" (default), "optional" verifies
+	// certificates that clients present, "require" rejects connections without a valid one.
+	// Changes apply on restart.
+	ClientAuth string `yaml:"client-auth,omitempty" json:"client-auth,omitempty"`
 }
 
 // RemoteManagement holds management API configuration under 'remote-management'.
@@ -139,17 +156,187 @@ type RemoteManagement struct {
 // It provides configuration options for automatic failover mechanisms.
 type QuotaExceeded struct {
 	// SwitchProject indicates whether to automatically switch to another project when a quota is exceeded.
+	// It applies to Gemini CLI credentials that list several project IDs; when disabled, the remaining
+	// projects of an account are skipped for a request once one of them returns 429.
 	SwitchProject bool `yaml:"switch-project" json:"switch-project"`
 
 	// SwitchPreviewModel indicates whether to automatically switch to a preview model when a quota is exceeded.
 	SwitchPreviewModel bool `yaml:"switch-preview-model" json:"switch-preview-model"`
+
+	// PreviewModels optionally maps a model to its preview sibling (model -> preview model).
+	// Models without an entry fall back to the preview sibling discovered from the model registry.
+	PreviewModels map[string]string `yaml:"preview-models,omitempty" json:"preview-models,omitempty"`
 }
 
 // RoutingConfig configures how credentials are selected for requests.
 type RoutingConfig struct {
 	// Strategy selects the credential selection strategy.
-	// Supported values: "weighted" (default), "round-robin", "fill-first".
+	// Supported values: "weighted" (default), "round-robin", "fill-first",
+	// "least-latency" (lowest latency EWMA scaled by in-flight requests; time-to-first-byte for
+	// streaming requests, response time otherwise),
+	// "least-inflight" (fewest in-flight requests),
+	// "sticky" (pins each conversation to one credential for prompt-cache reuse),
+	// "most-headroom" (most upstream quota left according to rate-limit response headers),
+	// or the name of a strategy registered with RegisterSelector.
 	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// Options holds strategy-specific options keyed by strategy name. The options of the active
+	// strategy are passed to its selector factory, including strategies registered by SDK users.
+	Options map[string]map[string]any `yaml:"options,omitempty" json:"options,omitempty"`
+
+	// Overrides routes requests matching a model or provider pattern with a different strategy.
+	// Entries are evaluated in order and the first match wins.
+	Overrides []RoutingOverride `yaml:"overrides,omitempty" json:"overrides,omitempty"`
+
+	// ProviderWeights splits requests for models served by several providers by weight before
+	// a credential of the chosen provider is selected.
+	ProviderWeights []ProviderWeight `yaml:"provider-weights,omitempty" json:"provider-weights,omitempty"`
+
+	// StickyTTL is how long, in seconds, the sticky strategy keeps an idle conversation pinned.
+	// Zero or negative uses the default of 1800 seconds.
+	StickyTTL int `yaml:"sticky-ttl,omitempty" json:"sticky-ttl,omitempty"`
+
+	// ConcurrencyQueueSize bounds how many requests may wait when every matching credential is at
+	// its max-concurrency or rate limits. Zero uses the default of 64; negative disables queueing.
+	ConcurrencyQueueSize int `yaml:"concurrency-queue-size,omitempty" json:"concurrency-queue-size,omitempty"`
+
+	// ConcurrencyQueueTimeout is how long, in seconds, a queued request waits for a slot.
+	// Zero or negative uses the default of 30 seconds.
+	ConcurrencyQueueTimeout int `yaml:"concurrency-queue-timeout,omitempty" json:"concurrency-queue-timeout,omitempty"`
+
+	// Spillover lets selectors also draw from the next lower priority group while the top group
+	// is busy or failing, keyed by provider; "*" applies to providers without an entry.
+	Spillover map[string]SpilloverPolicy `yaml:"spillover,omitempty" json:"spillover,omitempty"`
+
+	// KeyTags maps client API keys to the credential tags their requests require by default.
+	// Tags from the X-Route-Tags request header are required in addition.
+	KeyTags map[string][]string `yaml:"key-tags,omitempty" json:"key-tags,omitempty"`
+
+	// CooldownQueue makes requests wait for a credential to recover from cooldown instead of
+	// failing with 429 when every credential for the model is cooling down.
+	CooldownQueue CooldownQueueConfig `yaml:"cooldown-queue,omitempty" json:"cooldown-queue,omitempty"`
+
+	// Hedge configures hedged dispatch of non-streaming requests.
+	Hedge HedgeConfig `yaml:"hedge,omitempty" json:"hedge,omitempty"`
+}
+
+// SpilloverPolicy sets when the credentials of a provider in the top priority group count as
+// overloaded. Zero disables the corresponding threshold.
+type SpilloverPolicy struct {
+	// InFlight is the average number of in-flight requests per available credential of the model.
+	InFlight float64 `yaml:"inflight,omitempty" json:"inflight,omitempty"`
+
+	// ErrorRate is the average recent error rate, between 0 and 1, of the credentials for the model.
+	ErrorRate float64 `yaml:"error-rate,omitempty" json:"error-rate,omitempty"`
+}
+
+// CooldownQueueConfig controls the admission queue used while every credential for a model is
+// cooling down. Waiting requests are served first-in first-out within their priority class.
+type CooldownQueueConfig struct {
+	// Enabled turns the queue on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Size bounds how many requests may wait per model. Zero or negative uses the default of 64.
+	Size int `yaml:"size,omitempty" json:"size,omitempty"`
+
+	// MaxWait is how long, in seconds, a request waits at most. Requests whose earliest credential
+	// recovery is further away fail immediately. Zero or negative uses the default of 60 seconds.
+	MaxWait int `yaml:"max-wait,omitempty" json:"max-wait,omitempty"`
+
+	// KeyMaxWait overrides MaxWait, in seconds, for individual client API keys.
+	KeyMaxWait map[string]int `yaml:"key-max-wait,omitempty" json:"key-max-wait,omitempty"`
+
+	// PriorityClasses lists the values accepted in the X-Priority-Class request header, highest
+	// priority first. Requests without a listed class rank as "default" when it is listed, and
+	// after every listed class otherwise.
+	PriorityClasses []string `yaml:"priority-classes,omitempty" json:"priority-classes,omitempty"`
+}
+
+// RoutingOverride selects the strategy for requests whose model and provider match the patterns.
+// Patterns may use '*' wildcards and are matched case-insensitively; an empty pattern matches
+// anything, but at least one of Model and Provider must be set.
+type RoutingOverride struct {
+	// Model is the requested model name or wildcard pattern, such as "claude-*".
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+	// Provider is the provider name or wildcard pattern, such as "claude".
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	// Strategy is the routing strategy used for matching requests.
+	Strategy string `yaml:"strategy" json:"strategy"`
+}
+
+// ProviderWeight assigns traffic shares to the providers serving a model. Model may be an exact
+// model name or a wildcard pattern; exact matches take precedence over wildcard entries, which are
+// evaluated in order. Providers without a positive weight only receive requests when no weighted
+// provider has an available credential.
+type ProviderWeight struct {
+	// Model is the requested model name or wildcard pattern.
+	Model string `yaml:"model" json:"model"`
+	// Weights maps provider names to relative weights, e.g. {"gemini-cli": 70, "antigravity": 30}.
+	Weights map[string]int `yaml:"weights" json:"weights"`
+}
+
+// HedgeConfig controls hedged requests: when the first attempt of a non-streaming request is slow,
+// the same request is sent to a second credential and whichever answers first wins.
+type HedgeConfig struct {
+	// Enabled turns hedging on.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Delay is how long, in milliseconds, the first attempt may run before the hedge is dispatched.
+	// Zero uses the p95 latency observed for the model once enough samples were collected.
+	Delay int `yaml:"delay-ms,omitempty" json:"delay-ms,omitempty"`
+}
+
+// RateLimit defines requests-per-minute and tokens-per-minute budgets enforced before dispatch.
+// Zero disables the corresponding limit.
+type RateLimit struct {
+	// RPM is the maximum number of requests per minute.
+	RPM int `yaml:"rpm,omitempty" json:"rpm,omitempty"`
+
+	// TPM is the maximum number of tokens (prompt and completion) per minute.
+	TPM int `yaml:"tpm,omitempty" json:"tpm,omitempty"`
+}
+
+// CircuitBreakerConfig tunes the per-credential, per-model circuit breaker; failures not tied to a
+// model use a per-credential circuit under the same rules. A circuit opens
+// after consecutive failures, rejects traffic for the open interval, then lets a single probe
+// request through; a successful probe closes it and a failed one reopens it.
+type CircuitBreakerConfig struct {
+	// ProbeTimeout is how long, in seconds, a half-open probe may stay unanswered before another
+	// request is allowed to probe. Defaults to 120.
+	ProbeTimeout int `yaml:"probe-timeout,omitempty" json:"probe-timeout,omitempty"`
+
+	// Rules override the built-in policies. The first rule matching the provider and status code wins;
+	// status codes without a matching rule fall back to the built-in defaults.
+	Rules []CircuitBreakerRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+}
+
+// CircuitBreakerRule defines the breaker policy for a set of upstream status codes.
+type CircuitBreakerRule struct {
+	// Provider restricts the rule to one provider (e.g. "claude"); empty or "*" matches every provider.
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// Status lists the upstream HTTP status codes the rule applies to.
+	Status []int `yaml:"status" json:"status"`
+
+	// FailureThreshold is the number of consecutive failures that opens the circuit. Defaults to 1.
+	FailureThreshold int `yaml:"failure-threshold,omitempty" json:"failure-threshold,omitempty"`
+
+	// OpenInterval is how long, in seconds, the circuit stays open. Zero never opens it.
+	OpenInterval int `yaml:"open-interval" json:"open-interval"`
+
+	// MaxOpenInterval caps the open interval, in seconds, which doubles after every failed probe.
+	// Values not above OpenInterval keep the interval fixed.
+	MaxOpenInterval int `yaml:"max-open-interval,omitempty" json:"max-open-interval,omitempty"`
+}
+
+// ModelFallback defines an ordered list of fallback models for a requested model.
+// Model may be an exact model name or a wildcard pattern such as "gemini-2.5-*" or "*".
+// Exact matches take precedence over wildcard entries; wildcard entries are evaluated in order.
+type ModelFallback struct {
+	// Model is the requested model name or wildcard pattern.
+	Model string `yaml:"model" json:"model"`
+	// Fallbacks lists the models to try, in order, once the requested model has no usable credential.
+	Fallbacks []string `yaml:"fallbacks" json:"fallbacks"`
 }
 
 // OAuthModelAlias defines a model ID alias for a specific channel.
@@ -277,6 +464,19 @@ type ClaudeKey struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// MaxConcurrency caps simultaneous requests sent with this key; 0 means unlimited.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// Tags are free-form labels that requests can require with X-Route-Tags or routing.key-tags.
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// ActiveWindows restricts when the key may serve requests, e.g. "mon-fri 18:00-08:00 Europe/Berlin".
+	ActiveWindows []string `yaml:"active-windows,omitempty" json:"active-windows,omitempty"`
+
+	// RPM and TPM cap requests and tokens per minute for this key, overriding rate-limits; 0 means unlimited.
+	RPM int `yaml:"rpm,omitempty" json:"rpm,omitempty"`
+	TPM int `yaml:"tpm,omitempty" json:"tpm,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/claude-sonnet-4").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -325,6 +525,19 @@ type CodexKey struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// MaxConcurrency caps simultaneous requests sent with this key; 0 means unlimited.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// Tags are free-form labels that requests can require with X-Route-Tags or routing.key-tags.
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// ActiveWindows restricts when the key may serve requests, e.g. "mon-fri 18:00-08:00 Europe/Berlin".
+	ActiveWindows []string `yaml:"active-windows,omitempty" json:"active-windows,omitempty"`
+
+	// RPM and TPM cap requests and tokens per minute for this key, overriding rate-limits; 0 means unlimited.
+	RPM int `yaml:"rpm,omitempty" json:"rpm,omitempty"`
+	TPM int `yaml:"tpm,omitempty" json:"tpm,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/gpt-5-codex").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -370,6 +583,19 @@ type GeminiKey struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// MaxConcurrency caps simultaneous requests sent with this key; 0 means unlimited.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// Tags are free-form labels that requests can require with X-Route-Tags or routing.key-tags.
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// ActiveWindows restricts when the key may serve requests, e.g. "mon-fri 18:00-08:00 Europe/Berlin".
+	ActiveWindows []string `yaml:"active-windows,omitempty" json:"active-windows,omitempty"`
+
+	// RPM and TPM cap requests and tokens per minute for this key, overriding rate-limits; 0 means unlimited.
+	RPM int `yaml:"rpm,omitempty" json:"rpm,omitempty"`
+	TPM int `yaml:"tpm,omitempty" json:"tpm,omitempty"`
+
 	// Prefix optionally namespaces models for this credential (e.g., "teamA/gemini-3-pro-preview").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -437,6 +663,19 @@ type OpenAICompatibilityAPIKey struct {
 
 	// ProxyURL overrides the global proxy setting for this API key if provided.
 	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// MaxConcurrency caps simultaneous requests sent with this key; 0 means unlimited.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// Tags are free-form labels that requests can require with X-Route-Tags or routing.key-tags.
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// ActiveWindows restricts when the key may serve requests, e.g. "mon-fri 18:00-08:00 Europe/Berlin".
+	ActiveWindows []string `yaml:"active-windows,omitempty" json:"active-windows,omitempty"`
+
+	// RPM and TPM cap requests and tokens per minute for this key, overriding rate-limits; 0 means unlimited.
+	RPM int `yaml:"rpm,omitempty" json:"rpm,omitempty"`
+	TPM int `yaml:"tpm,omitempty" json:"tpm,omitempty"`
 }
 
 // OpenAICompatibilityModel represents a model configuration for OpenAI compatibility,
@@ -577,6 +816,34 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Validate raw payload rules and drop invalid entries.
 	cfg.SanitizePayloadRules()
 
+	// Normalize model fallback chains.
+	cfg.SanitizeModelFallbacks()
+
+	// Normalize preview model mappings used by quota-exceeded.switch-preview-model.
+	cfg.SanitizePreviewModels()
+
+	// Normalize provider rate limits.
+	cfg.SanitizeRateLimits()
+
+	// Drop circuit breaker rules without status codes and clamp negative values.
+	cfg.SanitizeCircuitBreaker()
+
+	// Normalize routing strategy overrides.
+	cfg.SanitizeRoutingOverrides()
+
+	// Normalize provider weights.
+	cfg.SanitizeProviderWeights()
+
+	// Normalize spillover policies.
+	cfg.SanitizeSpillover()
+
+	// Normalize shadow mirroring rules.
+	cfg.SanitizeMirrors()
+
+	// Normalize structured client keys.
+	cfg.SanitizeClientKeys()
+	cfg.SanitizeClientCerts()
+
 	if cfg.legacyMigrationPending {
 		fmt.Println("Detected legacy configuration keys, attempting to persist the normalized config...")
 		if !optional && configFile != "" {
@@ -648,6 +915,325 @@ func payloadRawString(value any) ([]byte, bool) {
 	}
 }
 
+// SanitizeModelFallbacks trims model fallback entries, drops entries without a model or
+// fallbacks, removes fallbacks that repeat the source model, and deduplicates each chain
+// case-insensitively while preserving order.
+func (cfg *Config) SanitizeModelFallbacks() {
+	if cfg == nil || len(cfg.ModelFallbacks) == 0 {
+		return
+	}
+	out := make([]ModelFallback, 0, len(cfg.ModelFallbacks))
+	for _, entry := range cfg.ModelFallbacks {
+		model := strings.TrimSpace(entry.Model)
+		if model == "" || len(entry.Fallbacks) == 0 {
+			continue
+		}
+		seen := make(map[string]struct{}, len(entry.Fallbacks))
+		chain := make([]string, 0, len(entry.Fallbacks))
+		for _, raw := range entry.Fallbacks {
+			fallback := strings.TrimSpace(raw)
+			if fallback == "" || strings.EqualFold(fallback, model) {
+				continue
+			}
+			key := strings.ToLower(fallback)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			chain = append(chain, fallback)
+		}
+		if len(chain) == 0 {
+			continue
+		}
+		out = append(out, ModelFallback{Model: model, Fallbacks: chain})
+	}
+	cfg.ModelFallbacks = out
+}
+
+// SanitizeRoutingOverrides trims and lowercases override patterns and drops entries without a
+// strategy or without any pattern.
+func (cfg *Config) SanitizeRoutingOverrides() {
+	if cfg == nil || len(cfg.Routing.Overrides) == 0 {
+		return
+	}
+	out := make([]RoutingOverride, 0, len(cfg.Routing.Overrides))
+	for _, entry := range cfg.Routing.Overrides {
+		entry.Model = strings.ToLower(strings.TrimSpace(entry.Model))
+		entry.Provider = strings.ToLower(strings.TrimSpace(entry.Provider))
+		entry.Strategy = strings.TrimSpace(entry.Strategy)
+		if entry.Strategy == "" || (entry.Model == "" && entry.Provider == "") {
+			continue
+		}
+		out = append(out, entry)
+	}
+	if len(out) == 0 {
+		out = nil
+	}
+	cfg.Routing.Overrides = out
+}
+
+// SanitizeProviderWeights lowercases models and provider names, drops non-positive weights and
+// removes entries without a model or any positive weight.
+func (cfg *Config) SanitizeProviderWeights() {
+	if cfg == nil || len(cfg.Routing.ProviderWeights) == 0 {
+		return
+	}
+	out := make([]ProviderWeight, 0, len(cfg.Routing.ProviderWeights))
+	for _, entry := range cfg.Routing.ProviderWeights {
+		model := strings.ToLower(strings.TrimSpace(entry.Model))
+		if model == "" {
+			continue
+		}
+		weights := make(map[string]int, len(entry.Weights))
+		for provider, weight := range entry.Weights {
+			provider = strings.ToLower(strings.TrimSpace(provider))
+			if provider == "" || weight <= 0 {
+				continue
+			}
+			weights[provider] = weight
+		}
+		if len(weights) == 0 {
+			continue
+		}
+		out = append(out, ProviderWeight{Model: model, Weights: weights})
+	}
+	if len(out) == 0 {
+		out = nil
+	}
+	cfg.Routing.ProviderWeights = out
+}
+
+// SanitizeSpillover lowercases provider names, clamps negative thresholds and error rates above
+// one, and drops policies without any threshold.
+func (cfg *Config) SanitizeSpillover() {
+	if cfg == nil || len(cfg.Routing.Spillover) == 0 {
+		return
+	}
+	out := make(map[string]SpilloverPolicy, len(cfg.Routing.Spillover))
+	for rawProvider, policy := range cfg.Routing.Spillover {
+		provider := strings.ToLower(strings.TrimSpace(rawProvider))
+		if provider == "" {
+			continue
+		}
+		if policy.InFlight < 0 {
+			policy.InFlight = 0
+		}
+		if policy.ErrorRate < 0 {
+			policy.ErrorRate = 0
+		}
+		if policy.ErrorRate > 1 {
+			policy.ErrorRate = 1
+		}
+		if policy.InFlight == 0 && policy.ErrorRate == 0 {
+			continue
+		}
+		out[provider] = policy
+	}
+	if len(out) == 0 {
+		out = nil
+	}
+	cfg.Routing.Spillover = out
+}
+
+// SanitizeClientKeys trims client keys and their model patterns, clears negative limits and drops
+// entries without a key or repeating an earlier key.
+func (cfg *Config) SanitizeClientKeys() {
+	if cfg == nil {
+		return
+	}
+	cfg.ClientRateLimit = sanitizeClientRateLimit(cfg.ClientRateLimit)
+	if len(cfg.ClientKeys) == 0 {
+		return
+	}
+	seen := make(map[string]struct{}, len(cfg.ClientKeys))
+	out := make([]ClientKey, 0, len(cfg.ClientKeys))
+	for _, entry := range cfg.ClientKeys {
+		entry.Key = strings.TrimSpace(entry.Key)
+		if entry.Key == "" {
+			continue
+		}
+		if _, exists := seen[entry.Key]; exists {
+			continue
+		}
+		seen[entry.Key] = struct{}{}
+		entry.ClientPolicy = sanitizeClientPolicy(entry.ClientPolicy)
+		out = append(out, entry)
+	}
+	cfg.ClientKeys = out
+}
+
+// SanitizeClientCerts trims client certificate mappings and their policies and drops entries
+// without a subject or SAN pattern.
+func (cfg *Config) SanitizeClientCerts() {
+	if cfg == nil || len(cfg.ClientCerts) == 0 {
+		return
+	}
+	out := make([]ClientCert, 0, len(cfg.ClientCerts))
+	for _, entry := range cfg.ClientCerts {
+		entry.Subject = strings.TrimSpace(entry.Subject)
+		entry.SAN = strings.TrimSpace(entry.SAN)
+		if entry.Subject == "" && entry.SAN == "" {
+			continue
+		}
+		entry.Principal = strings.TrimSpace(entry.Principal)
+		entry.ClientPolicy = sanitizeClientPolicy(entry.ClientPolicy)
+		out = append(out, entry)
+	}
+	if len(out) == 0 {
+		out = nil
+	}
+	cfg.ClientCerts = out
+}
+
+func sanitizeClientPolicy(policy ClientPolicy) ClientPolicy {
+	policy.Label = strings.TrimSpace(policy.Label)
+	policy.AllowedModels = trimModelPatterns(policy.AllowedModels)
+	policy.DeniedModels = trimModelPatterns(policy.DeniedModels)
+	if policy.Limits.RequestsPerDay < 0 {
+		policy.Limits.RequestsPerDay = 0
+	}
+	if policy.Limits.TokensPerDay < 0 {
+		policy.Limits.TokensPerDay = 0
+	}
+	if policy.Limits.TokensPerMonth < 0 {
+		policy.Limits.TokensPerMonth = 0
+	}
+	policy.RateLimit = sanitizeClientRateLimit(policy.RateLimit)
+	return policy
+}
+
+func sanitizeClientRateLimit(limit ClientRateLimit) ClientRateLimit {
+	if limit.RPM < 0 {
+		limit.RPM = 0
+	}
+	if limit.MaxConcurrent < 0 {
+		limit.MaxConcurrent = 0
+	}
+	return limit
+}
+
+func trimModelPatterns(patterns []string) []string {
+	out := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			out = append(out, pattern)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// SanitizeMirrors trims mirroring rules, clamps sample rates above one and drops rules without a
+// model pattern, a target or a positive sample rate.
+func (cfg *Config) SanitizeMirrors() {
+	if cfg == nil || len(cfg.Mirrors) == 0 {
+		return
+	}
+	out := make([]MirrorRule, 0, len(cfg.Mirrors))
+	for _, rule := range cfg.Mirrors {
+		rule.Model = strings.TrimSpace(rule.Model)
+		rule.Target = strings.TrimSpace(rule.Target)
+		if rule.Model == "" || rule.Target == "" || rule.SampleRate <= 0 {
+			continue
+		}
+		if rule.SampleRate > 1 {
+			rule.SampleRate = 1
+		}
+		keys := make([]string, 0, len(rule.ClientKeys))
+		for _, key := range rule.ClientKeys {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+		rule.ClientKeys = keys
+		out = append(out, rule)
+	}
+	cfg.Mirrors = out
+}
+
+// SanitizePreviewModels trims preview model mappings and drops empty or self-referencing entries.
+func (cfg *Config) SanitizePreviewModels() {
+	if cfg == nil || len(cfg.QuotaExceeded.PreviewModels) == 0 {
+		return
+	}
+	out := make(map[string]string, len(cfg.QuotaExceeded.PreviewModels))
+	for rawModel, rawPreview := range cfg.QuotaExceeded.PreviewModels {
+		model := strings.TrimSpace(rawModel)
+		preview := strings.TrimSpace(rawPreview)
+		if model == "" || preview == "" || strings.EqualFold(model, preview) {
+			continue
+		}
+		out[model] = preview
+	}
+	cfg.QuotaExceeded.PreviewModels = out
+}
+
+// SanitizeRateLimits lower-cases provider keys and drops entries without a positive limit.
+func (cfg *Config) SanitizeRateLimits() {
+	if cfg == nil || len(cfg.RateLimits) == 0 {
+		return
+	}
+	out := make(map[string]RateLimit, len(cfg.RateLimits))
+	for rawProvider, limit := range cfg.RateLimits {
+		provider := strings.ToLower(strings.TrimSpace(rawProvider))
+		if limit.RPM < 0 {
+			limit.RPM = 0
+		}
+		if limit.TPM < 0 {
+			limit.TPM = 0
+		}
+		if provider == "" || (limit.RPM == 0 && limit.TPM == 0) {
+			continue
+		}
+		out[provider] = limit
+	}
+	cfg.RateLimits = out
+}
+
+// SanitizeCircuitBreaker normalizes provider names, drops rules without valid status codes and
+// clamps negative thresholds and intervals.
+func (cfg *Config) SanitizeCircuitBreaker() {
+	if cfg == nil {
+		return
+	}
+	if cfg.CircuitBreaker.ProbeTimeout < 0 {
+		cfg.CircuitBreaker.ProbeTimeout = 0
+	}
+	if len(cfg.CircuitBreaker.Rules) == 0 {
+		return
+	}
+	out := make([]CircuitBreakerRule, 0, len(cfg.CircuitBreaker.Rules))
+	for _, rule := range cfg.CircuitBreaker.Rules {
+		rule.Provider = strings.ToLower(strings.TrimSpace(rule.Provider))
+		if rule.Provider == "*" {
+			rule.Provider = ""
+		}
+		codes := make([]int, 0, len(rule.Status))
+		for _, code := range rule.Status {
+			if code >= 100 && code <= 599 {
+				codes = append(codes, code)
+			}
+		}
+		if len(codes) == 0 {
+			continue
+		}
+		rule.Status = codes
+		if rule.FailureThreshold < 1 {
+			rule.FailureThreshold = 1
+		}
+		if rule.OpenInterval < 0 {
+			rule.OpenInterval = 0
+		}
+		if rule.MaxOpenInterval < rule.OpenInterval {
+			rule.MaxOpenInterval = rule.OpenInterval
+		}
+		out = append(out, rule)
+	}
+	cfg.CircuitBreaker.Rules = out
+}
+
 // SanitizeOAuthModelAlias normalizes and deduplicates global OAuth model name aliases.
 // It trims whitespace, normalizes channel keys to lower-case, drops empty entries,
 // allows multiple aliases per upstream name, and ensures aliases are unique within each channel.
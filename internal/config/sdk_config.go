@@ -20,6 +20,17 @@ type SDKConfig struct {
 	// APIKeys is a list of keys for authenticating clients to this proxy server.
 	APIKeys []string `yaml:"api-keys" json:"api-keys"`
 
+	// ClientKeys lists client keys with a label and model restrictions. They authenticate like
+	// APIKeys, which stay unrestricted.
+	ClientKeys []ClientKey `yaml:"client-keys,omitempty" json:"client-keys,omitempty"`
+
+	// ClientCerts maps TLS client certificates verified against tls.client-ca to client identities
+	// with the same policies as ClientKeys.
+	ClientCerts []ClientCert `yaml:"client-certs,omitempty" json:"client-certs,omitempty"`
+
+	// ClientRateLimit throttles each authenticated client key; client-keys entries may override it.
+	ClientRateLimit ClientRateLimit `yaml:"client-rate-limit,omitempty" json:"client-rate-limit,omitempty"`
+
 	// Access holds request authentication provider configuration.
 	Access AccessConfig `yaml:"auth,omitempty" json:"auth,omitempty"`
 
@@ -29,6 +40,94 @@ type SDKConfig struct {
 	// NonStreamKeepAliveInterval controls how often blank lines are emitted for non-streaming responses.
 	// <= 0 disables keep-alives. Value is in seconds.
 	NonStreamKeepAliveInterval int `yaml:"nonstream-keepalive-interval,omitempty" json:"nonstream-keepalive-interval,omitempty"`
+
+	// Mirrors copies a sample of requests to a candidate model in the background and records both
+	// responses for comparison. The first matching rule applies.
+	Mirrors []MirrorRule `yaml:"mirrors,omitempty" json:"mirrors,omitempty"`
+}
+
+// MirrorRule sends shadow copies of requests for matching models to a target model.
+type MirrorRule struct {
+	// Model is the requested model pattern; '*' matches any substring.
+	Model string `yaml:"model" json:"model"`
+
+	// Target is the model that receives the shadow copy.
+	Target string `yaml:"target" json:"target"`
+
+	// SampleRate is the fraction of matching requests mirrored, between 0 and 1.
+	SampleRate float64 `yaml:"sample-rate" json:"sample-rate"`
+
+	// ClientKeys limits mirroring to requests authenticated with these client API keys; empty
+	// mirrors requests of every key.
+	ClientKeys []string `yaml:"client-keys,omitempty" json:"client-keys,omitempty"`
+}
+
+// ClientKey is a client API key with the policy applied to its requests.
+type ClientKey struct {
+	// Key is the secret presented by the client.
+	Key string `yaml:"key" json:"key"`
+
+	ClientPolicy `yaml:",inline"`
+}
+
+// ClientCert maps verified TLS client certificates to a client identity and its policy. An entry
+// matches a certificate when every pattern it sets matches.
+type ClientCert struct {
+	// Subject matches the common name or the distinguished name of the certificate subject; '*'
+	// matches any substring.
+	Subject string `yaml:"subject,omitempty" json:"subject,omitempty"`
+
+	// SAN matches any DNS name, email address or URI subject alternative name; '*' matches any
+	// substring.
+	SAN string `yaml:"san,omitempty" json:"san,omitempty"`
+
+	// Principal names the client in usage statistics and limits. Defaults to the matched SAN, or
+	// the common name of the subject.
+	Principal string `yaml:"principal,omitempty" json:"principal,omitempty"`
+
+	ClientPolicy `yaml:",inline"`
+}
+
+// ClientPolicy restricts the models and consumption of an authenticated client.
+type ClientPolicy struct {
+	// Label names the client in logs and usage statistics.
+	Label string `yaml:"label,omitempty" json:"label,omitempty"`
+
+	// AllowedModels lists the model patterns the client may use; '*' matches any substring. Empty
+	// allows every model.
+	AllowedModels []string `yaml:"allowed-models,omitempty" json:"allowed-models,omitempty"`
+
+	// DeniedModels lists model patterns the client may not use; they win over AllowedModels.
+	DeniedModels []string `yaml:"denied-models,omitempty" json:"denied-models,omitempty"`
+
+	// Limits caps the consumption of the client. Enforcement is best-effort: counts are per
+	// instance and only saved with the periodic runtime state.
+	Limits ClientKeyLimits `yaml:"limits,omitempty" json:"limits,omitempty"`
+
+	// RateLimit overrides the non-zero fields of the default client rate limit for the client.
+	RateLimit ClientRateLimit `yaml:"rate-limit,omitempty" json:"rate-limit,omitempty"`
+}
+
+// ClientRateLimit throttles a client key at the HTTP layer. Zero disables the corresponding limit.
+type ClientRateLimit struct {
+	// RPM is the maximum number of requests per minute.
+	RPM int `yaml:"rpm,omitempty" json:"rpm,omitempty"`
+
+	// MaxConcurrent is the maximum number of requests in flight at once.
+	MaxConcurrent int `yaml:"max-concurrent,omitempty" json:"max-concurrent,omitempty"`
+}
+
+// ClientKeyLimits caps what a client key may consume. Days and months follow UTC; zero fields
+// are unlimited.
+type ClientKeyLimits struct {
+	// RequestsPerDay caps the successful requests per day.
+	RequestsPerDay int64 `yaml:"requests-per-day,omitempty" json:"requests-per-day,omitempty"`
+
+	// TokensPerDay caps the tokens used per day.
+	TokensPerDay int64 `yaml:"tokens-per-day,omitempty" json:"tokens-per-day,omitempty"`
+
+	// TokensPerMonth caps the tokens used per calendar month.
+	TokensPerMonth int64 `yaml:"tokens-per-month,omitempty" json:"tokens-per-month,omitempty"`
 }
 
 // StreamingConfig holds server streaming behavior configuration.
@@ -71,6 +170,17 @@ const (
 	// AccessProviderTypeConfigAPIKey is the built-in provider validating inline API keys.
 	AccessProviderTypeConfigAPIKey = "config-api-key"
 
+	// AccessProviderTypeJWT is the built-in provider validating signed bearer tokens against a JWKS.
+	AccessProviderTypeJWT = "jwt"
+
+	// AccessProviderTypeClientCert is the built-in provider authenticating verified TLS client
+	// certificates mapped by client-certs.
+	AccessProviderTypeClientCert = "client-cert"
+
+	// DefaultClientCertProviderName names the client-cert provider added for client-certs when no
+	// providers are configured.
+	DefaultClientCertProviderName = "client-cert-inline"
+
 	// DefaultAccessProviderName is applied when no provider name is supplied.
 	DefaultAccessProviderName = "config-inline"
 )
@@ -91,6 +201,42 @@ func (c *SDKConfig) ConfigAPIKeyProvider() *AccessProvider {
 	return nil
 }
 
+// MakeInlineClientCertProvider returns the client-cert provider implied by client-certs, or nil
+// when no certificates are mapped.
+func (c *SDKConfig) MakeInlineClientCertProvider() *AccessProvider {
+	if c == nil || len(c.ClientCerts) == 0 {
+		return nil
+	}
+	return &AccessProvider{Name: DefaultClientCertProviderName, Type: AccessProviderTypeClientCert}
+}
+
+// InlineAPIKeys returns the plain api-keys followed by the keys of client-keys, without
+// duplicates.
+func (c *SDKConfig) InlineAPIKeys() []string {
+	if c == nil {
+		return nil
+	}
+	if len(c.ClientKeys) == 0 {
+		return c.APIKeys
+	}
+	keys := make([]string, 0, len(c.APIKeys)+len(c.ClientKeys))
+	seen := make(map[string]struct{}, cap(keys))
+	add := func(key string) {
+		if _, ok := seen[key]; ok || key == "" {
+			return
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	for _, key := range c.APIKeys {
+		add(key)
+	}
+	for _, entry := range c.ClientKeys {
+		add(entry.Key)
+	}
+	return keys
+}
+
 // MakeInlineAPIKeyProvider constructs an inline API key provider configuration.
 // It returns nil when no keys are supplied.
 func MakeInlineAPIKeyProvider(keys []string) *AccessProvider {
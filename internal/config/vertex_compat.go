@@ -17,6 +17,19 @@ type VertexCompatKey struct {
 	// Higher values are preferred; defaults to 0.
 	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
 
+	// MaxConcurrency caps simultaneous requests sent with this key; 0 means unlimited.
+	MaxConcurrency int `yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+
+	// Tags are free-form labels that requests can require with X-Route-Tags or routing.key-tags.
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+
+	// ActiveWindows restricts when the key may serve requests, e.g. "mon-fri 18:00-08:00 Europe/Berlin".
+	ActiveWindows []string `yaml:"active-windows,omitempty" json:"active-windows,omitempty"`
+
+	// RPM and TPM cap requests and tokens per minute for this key, overriding rate-limits; 0 means unlimited.
+	RPM int `yaml:"rpm,omitempty" json:"rpm,omitempty"`
+	TPM int `yaml:"tpm,omitempty" json:"tpm,omitempty"`
+
 	// Prefix optionally namespaces model aliases for this credential (e.g., "teamA/vertex-pro").
 	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
 
@@ -0,0 +1,226 @@
+// Package mirror records shadow traffic comparisons: requests copied to a candidate model in the
+// background, with both responses, latencies and token usage kept in memory for review through
+// the management API.
+package mirror
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+const (
+	// DefaultLogSize bounds the comparisons kept in memory; the oldest are dropped first.
+	DefaultLogSize = 200
+	// MaxResponseBytes truncates recorded responses.
+	MaxResponseBytes = 64 << 10
+)
+
+func init() {
+	coreusage.RegisterPlugin(usagePlugin{})
+}
+
+// Usage is the token usage reported for one side of a comparison.
+type Usage struct {
+	InputTokens     int64 `json:"input_tokens"`
+	OutputTokens    int64 `json:"output_tokens"`
+	ReasoningTokens int64 `json:"reasoning_tokens"`
+	CachedTokens    int64 `json:"cached_tokens"`
+	TotalTokens     int64 `json:"total_tokens"`
+}
+
+// Result is one side of a comparison: the response served to the client or the shadow response.
+type Result struct {
+	Model     string `json:"model"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+	Response  string `json:"response,omitempty"`
+	// Truncated marks responses cut to the recording limit.
+	Truncated bool  `json:"truncated,omitempty"`
+	Usage     Usage `json:"usage"`
+
+	usage *Probe
+}
+
+// Comparison pairs the response served for a request with the response of its shadow copy.
+type Comparison struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Primary   Result    `json:"primary"`
+	Mirror    Result    `json:"mirror"`
+}
+
+// Probe collects the usage records emitted for the request whose context carries it. Usage is
+// delivered asynchronously, so it may still grow after the response was recorded.
+type Probe struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+type probeContextKey struct{}
+
+// WithProbe returns ctx carrying a new usage probe.
+func WithProbe(ctx context.Context) (context.Context, *Probe) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	probe := &Probe{}
+	return context.WithValue(ctx, probeContextKey{}, probe), probe
+}
+
+// Usage returns the usage collected so far.
+func (p *Probe) Usage() Usage {
+	if p == nil {
+		return Usage{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.usage
+}
+
+type usagePlugin struct{}
+
+// HandleUsage implements coreusage.Plugin.
+func (usagePlugin) HandleUsage(ctx context.Context, record coreusage.Record) {
+	if ctx == nil {
+		return
+	}
+	probe, ok := ctx.Value(probeContextKey{}).(*Probe)
+	if !ok || probe == nil {
+		return
+	}
+	probe.mu.Lock()
+	probe.usage.InputTokens += record.Detail.InputTokens
+	probe.usage.OutputTokens += record.Detail.OutputTokens
+	probe.usage.ReasoningTokens += record.Detail.ReasoningTokens
+	probe.usage.CachedTokens += record.Detail.CachedTokens
+	probe.usage.TotalTokens += record.Detail.TotalTokens
+	probe.mu.Unlock()
+}
+
+// NewResult builds a comparison side from a response payload, truncating it to the recording
+// limit. probe may be nil.
+func NewResult(model string, latency time.Duration, payload []byte, err error, probe *Probe) Result {
+	result := Result{Model: model, LatencyMs: latency.Milliseconds(), usage: probe}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if len(payload) > MaxResponseBytes {
+		payload = payload[:MaxResponseBytes]
+		result.Truncated = true
+	}
+	result.Response = string(payload)
+	return result
+}
+
+// Log keeps the latest comparisons in memory.
+type Log struct {
+	mu      sync.Mutex
+	size    int
+	entries []Comparison
+}
+
+// NewLog creates a log keeping up to size comparisons; non-positive sizes use DefaultLogSize.
+func NewLog(size int) *Log {
+	if size <= 0 {
+		size = DefaultLogSize
+	}
+	return &Log{size: size}
+}
+
+var defaultLog = NewLog(DefaultLogSize)
+
+// DefaultLog returns the log shared by the API handlers and the management API.
+func DefaultLog() *Log { return defaultLog }
+
+// Add appends a comparison, dropping the oldest one when the log is full.
+func (l *Log) Add(comparison Comparison) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) >= l.size {
+		copy(l.entries, l.entries[1:])
+		l.entries = l.entries[:len(l.entries)-1]
+	}
+	l.entries = append(l.entries, comparison)
+}
+
+// Entries returns up to limit comparisons, newest first, with the usage collected so far. A
+// non-positive limit returns all of them.
+func (l *Log) Entries(limit int) []Comparison {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limit <= 0 || limit > len(l.entries) {
+		limit = len(l.entries)
+	}
+	out := make([]Comparison, 0, limit)
+	for i := len(l.entries) - 1; i >= 0 && len(out) < limit; i-- {
+		entry := l.entries[i]
+		if entry.Primary.usage != nil {
+			entry.Primary.Usage = entry.Primary.usage.Usage()
+		}
+		if entry.Mirror.usage != nil {
+			entry.Mirror.Usage = entry.Mirror.usage.Usage()
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// Clear removes every comparison.
+func (l *Log) Clear() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.entries = nil
+	l.mu.Unlock()
+}
+
+var (
+	sampleMu  sync.Mutex
+	sampleRng = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Select returns the first rule matching model and clientKey, sampled by its rate.
+func Select(rules []config.MirrorRule, model, clientKey string) (config.MirrorRule, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	for _, rule := range rules {
+		if !util.MatchWildcard(strings.ToLower(rule.Model), model) {
+			continue
+		}
+		if len(rule.ClientKeys) > 0 && !containsString(rule.ClientKeys, clientKey) {
+			continue
+		}
+		if rule.SampleRate < 1 {
+			sampleMu.Lock()
+			sampled := sampleRng.Float64() < rule.SampleRate
+			sampleMu.Unlock()
+			if !sampled {
+				return config.MirrorRule{}, false
+			}
+		}
+		return rule, true
+	}
+	return config.MirrorRule{}, false
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+// Package mtls verifies TLS client certificates against a CA bundle that can be reloaded while
+// the server keeps running.
+package mtls
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Client certificate verification modes accepted by tls.client-auth.
+const (
+	ModeNone     = "none"
+	ModeOptional = "optional"
+	ModeRequire  = "require"
+)
+
+// ParseMode normalizes a tls.client-auth value; empty selects ModeNone.
+func ParseMode(mode string) (string, error) {
+	switch normalized := strings.ToLower(strings.TrimSpace(mode)); normalized {
+	case "", ModeNone:
+		return ModeNone, nil
+	case ModeOptional, ModeRequire:
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("unsupported tls.client-auth %q (want none, optional or require)", mode)
+	}
+}
+
+// Verifier holds the CAs trusted to issue client certificates.
+type Verifier struct {
+	mu   sync.RWMutex
+	path string
+	hash [sha256.Size]byte
+	pool *x509.CertPool
+}
+
+var defaultVerifier = &Verifier{}
+
+// Default returns the verifier shared by the server and the config watcher.
+func Default() *Verifier { return defaultVerifier }
+
+// Path returns the path of the loaded CA bundle.
+func (v *Verifier) Path() string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.path
+}
+
+// Load reads the PEM CA bundle at path and reports whether the trusted CAs changed. An empty path
+// clears them. On error the previous CAs stay in effect.
+func (v *Verifier) Load(path string) (bool, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		changed := v.pool != nil
+		v.path, v.pool, v.hash = "", nil, [sha256.Size]byte{}
+		return changed, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("read client CA bundle: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	v.mu.RLock()
+	unchanged := v.path == path && v.hash == hash && v.pool != nil
+	v.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return false, fmt.Errorf("client CA bundle %s contains no PEM certificates", path)
+	}
+	v.mu.Lock()
+	v.path, v.pool, v.hash = path, pool, hash
+	v.mu.Unlock()
+	return true, nil
+}
+
+// Reload re-reads the loaded CA bundle and reports whether the trusted CAs changed.
+func (v *Verifier) Reload() (bool, error) {
+	path := v.Path()
+	if path == "" {
+		return false, nil
+	}
+	return v.Load(path)
+}
+
+// Verify checks that certs, leaf first, chain to a trusted CA and allow client authentication.
+func (v *Verifier) Verify(certs []*x509.Certificate) error {
+	if len(certs) == 0 {
+		return errors.New("no client certificate")
+	}
+	v.mu.RLock()
+	pool := v.pool
+	v.mu.RUnlock()
+	if pool == nil {
+		return errors.New("no client CA bundle loaded")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+// ServerConfig returns server TLS settings that request client certificates in mode and verify
+// them against the CAs current at handshake time, so reloads apply to new connections. Optional
+// mode accepts connections without a certificate but rejects invalid ones.
+func (v *Verifier) ServerConfig(mode string) *tls.Config {
+	clientAuth := tls.RequestClientCert
+	if mode == ModeRequire {
+		clientAuth = tls.RequireAnyClientCert
+	}
+	return &tls.Config{
+		ClientAuth: clientAuth,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 && mode != ModeRequire {
+				return nil
+			}
+			if err := v.Verify(state.PeerCertificates); err != nil {
+				return fmt.Errorf("client certificate rejected: %w", err)
+			}
+			return nil
+		},
+	}
+}
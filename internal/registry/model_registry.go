@@ -966,6 +966,69 @@ func (r *ModelRegistry) GetModelProviders(modelID string) []string {
 	return result
 }
 
+// GetPreviewModel returns the preview sibling of the given model, such as "qwen3-max-preview"
+// for "qwen3-max" or "gemini-2.5-flash-preview-05-20" for "gemini-2.5-flash".
+// Currently registered models are preferred over static definitions; among several dated
+// previews the lexically greatest (newest) ID wins. Returns "" when no sibling exists.
+func (r *ModelRegistry) GetPreviewModel(modelID string) string {
+	modelID = strings.TrimSpace(modelID)
+	if modelID == "" || isPreviewModelID(modelID) {
+		return ""
+	}
+
+	r.mutex.RLock()
+	registered := make([]string, 0)
+	for id, registration := range r.models {
+		if registration == nil || registration.Count <= 0 {
+			continue
+		}
+		if isPreviewSibling(modelID, id) {
+			registered = append(registered, id)
+		}
+	}
+	r.mutex.RUnlock()
+	if best := pickPreviewSibling(modelID, registered); best != "" {
+		return best
+	}
+
+	static := make([]string, 0)
+	for _, channel := range []string{"claude", "gemini", "vertex", "gemini-cli", "aistudio", "codex", "qwen", "iflow"} {
+		for _, model := range GetStaticModelDefinitionsByChannel(channel) {
+			if model != nil && isPreviewSibling(modelID, model.ID) {
+				static = append(static, model.ID)
+			}
+		}
+	}
+	return pickPreviewSibling(modelID, static)
+}
+
+func isPreviewModelID(modelID string) bool {
+	lower := strings.ToLower(modelID)
+	return strings.HasSuffix(lower, "-preview") || strings.Contains(lower, "-preview-")
+}
+
+func isPreviewSibling(modelID, candidate string) bool {
+	exact := modelID + "-preview"
+	return strings.EqualFold(candidate, exact) || strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(exact)+"-")
+}
+
+func pickPreviewSibling(modelID string, candidates []string) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	exact := modelID + "-preview"
+	best := ""
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, exact) {
+			return candidate
+		}
+		if candidate > best {
+			best = candidate
+		}
+	}
+	return best
+}
+
 // GetModelInfo returns ModelInfo, prioritizing provider-specific definition if available.
 func (r *ModelRegistry) GetModelInfo(modelID, provider string) *ModelInfo {
 	r.mutex.RLock()
@@ -23,6 +23,8 @@ type usageReporter struct {
 	apiKey      string
 	source      string
 	requestedAt time.Time
+	fallback    cliproxyauth.ModelFallbackInfo
+	affinity    string
 	once        sync.Once
 }
 
@@ -39,6 +41,10 @@ func newUsageReporter(ctx context.Context, provider, model string, auth *cliprox
 		reporter.authID = auth.ID
 		reporter.authIndex = auth.EnsureIndex()
 	}
+	if info, ok := cliproxyauth.ModelFallbackFromContext(ctx); ok {
+		reporter.fallback = info
+	}
+	reporter.affinity = cliproxyauth.AffinityFromContext(ctx)
 	return reporter
 }
 
@@ -74,15 +80,18 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, detail usage.Det
 	}
 	r.once.Do(func() {
 		usage.PublishRecord(ctx, usage.Record{
-			Provider:    r.provider,
-			Model:       r.model,
-			Source:      r.source,
-			APIKey:      r.apiKey,
-			AuthID:      r.authID,
-			AuthIndex:   r.authIndex,
-			RequestedAt: r.requestedAt,
-			Failed:      failed,
-			Detail:      detail,
+			Provider:     r.provider,
+			Model:        r.model,
+			Source:       r.source,
+			APIKey:       r.apiKey,
+			AuthID:       r.authID,
+			AuthIndex:    r.authIndex,
+			RequestedAt:  r.requestedAt,
+			Failed:       failed,
+			Detail:       detail,
+			FallbackFrom: r.fallback.RequestedModel,
+			FallbackHop:  r.fallback.Hop,
+			Affinity:     r.affinity,
 		})
 	})
 }
@@ -97,15 +106,18 @@ func (r *usageReporter) ensurePublished(ctx context.Context) {
 	}
 	r.once.Do(func() {
 		usage.PublishRecord(ctx, usage.Record{
-			Provider:    r.provider,
-			Model:       r.model,
-			Source:      r.source,
-			APIKey:      r.apiKey,
-			AuthID:      r.authID,
-			AuthIndex:   r.authIndex,
-			RequestedAt: r.requestedAt,
-			Failed:      false,
-			Detail:      usage.Detail{},
+			Provider:     r.provider,
+			Model:        r.model,
+			Source:       r.source,
+			APIKey:       r.apiKey,
+			AuthID:       r.authID,
+			AuthIndex:    r.authIndex,
+			RequestedAt:  r.requestedAt,
+			Failed:       false,
+			Detail:       usage.Detail{},
+			FallbackFrom: r.fallback.RequestedModel,
+			FallbackHop:  r.fallback.Hop,
+			Affinity:     r.affinity,
 		})
 	})
 }
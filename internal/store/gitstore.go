@@ -31,8 +31,18 @@ type GitTokenStore struct {
 	remote    string
 	username  string
 	password  string
+
+	// runtimeStatePushed is when the runtime state snapshot was last committed and pushed;
+	// runtimeStateDirty reports a snapshot written to the working tree since then.
+	runtimeStatePushed time.Time
+	runtimeStateDirty  bool
 }
 
+// gitRuntimeStatePushInterval is the minimum time between commits of the runtime state snapshot.
+// Snapshots saved in between only update the working tree, so the periodic sync does not grow the
+// remote history; FlushRuntimeState commits the latest snapshot on shutdown.
+const gitRuntimeStatePushInterval = 30 * time.Minute
+
 // NewGitTokenStore creates a token store that saves credentials to disk through the
 // TokenStorage implementation embedded in the token record.
 func NewGitTokenStore(remote, username, password string) *GitTokenStore {
@@ -676,6 +686,87 @@ func (s *GitTokenStore) PersistConfig(_ context.Context) error {
 	return s.commitAndPushLocked("Update config", rel)
 }
 
+// runtimeStatePath returns the runtime state snapshot path next to the managed config file.
+func (s *GitTokenStore) runtimeStatePath() string {
+	s.dirLock.RLock()
+	defer s.dirLock.RUnlock()
+	if s.configDir == "" {
+		return ""
+	}
+	return filepath.Join(s.configDir, "runtime-state.json")
+}
+
+// LoadRuntimeState reads the runtime state snapshot from the working tree.
+func (s *GitTokenStore) LoadRuntimeState(_ context.Context) ([]byte, error) {
+	if err := s.EnsureRepository(); err != nil {
+		return nil, err
+	}
+	path := s.runtimeStatePath()
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("git token store: read runtime state: %w", err)
+	}
+	return data, nil
+}
+
+// SaveRuntimeState writes the runtime state snapshot to the working tree. It is committed and
+// pushed at most once per gitRuntimeStatePushInterval; FlushRuntimeState commits it on demand.
+func (s *GitTokenStore) SaveRuntimeState(_ context.Context, data []byte) error {
+	if err := s.EnsureRepository(); err != nil {
+		return err
+	}
+	path := s.runtimeStatePath()
+	if path == "" {
+		return fmt.Errorf("git token store: config path not configured")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("git token store: create runtime state dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("git token store: write runtime state: %w", err)
+	}
+	s.runtimeStateDirty = true
+	if !s.runtimeStatePushed.IsZero() && time.Since(s.runtimeStatePushed) < gitRuntimeStatePushInterval {
+		return nil
+	}
+	return s.pushRuntimeStateLocked(path)
+}
+
+// FlushRuntimeState commits and pushes the runtime state snapshot written since the last push.
+func (s *GitTokenStore) FlushRuntimeState(_ context.Context) error {
+	path := s.runtimeStatePath()
+	if path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.runtimeStateDirty {
+		return nil
+	}
+	return s.pushRuntimeStateLocked(path)
+}
+
+func (s *GitTokenStore) pushRuntimeStateLocked(path string) error {
+	rel, err := s.relativeToRepo(path)
+	if err != nil {
+		return err
+	}
+	if err = s.commitAndPushLocked("Update runtime state", rel); err != nil {
+		return err
+	}
+	s.runtimeStatePushed = time.Now()
+	s.runtimeStateDirty = false
+	return nil
+}
+
 func ensureEmptyFile(path string) error {
 	if _, err := os.Stat(path); err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
@@ -23,8 +23,9 @@ import (
 )
 
 const (
-	objectStoreConfigKey  = "config/config.yaml"
-	objectStoreAuthPrefix = "auths"
+	objectStoreConfigKey       = "config/config.yaml"
+	objectStoreAuthPrefix      = "auths"
+	objectStoreRuntimeStateKey = "state/runtime-state.json"
 )
 
 // ObjectStoreConfig captures configuration for the object storage-backed token store.
@@ -324,6 +325,33 @@ func (s *ObjectTokenStore) PersistConfig(ctx context.Context) error {
 	return s.putObject(ctx, objectStoreConfigKey, data, "application/x-yaml")
 }
 
+// LoadRuntimeState fetches the runtime state snapshot from the bucket.
+func (s *ObjectTokenStore) LoadRuntimeState(ctx context.Context) ([]byte, error) {
+	object, err := s.client.GetObject(ctx, s.cfg.Bucket, s.prefixedKey(objectStoreRuntimeStateKey), minio.GetObjectOptions{})
+	if err != nil {
+		if isObjectNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("object store: fetch runtime state: %w", err)
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		if isObjectNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("object store: read runtime state: %w", err)
+	}
+	return data, nil
+}
+
+// SaveRuntimeState uploads the runtime state snapshot to the bucket.
+func (s *ObjectTokenStore) SaveRuntimeState(ctx context.Context, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.putObject(ctx, objectStoreRuntimeStateKey, data, "application/json")
+}
+
 func (s *ObjectTokenStore) ensureBucket(ctx context.Context) error {
 	exists, err := s.client.BucketExists(ctx, s.cfg.Bucket)
 	if err != nil {
@@ -23,6 +23,7 @@ const (
 	defaultConfigTable = "config_store"
 	defaultAuthTable   = "auth_store"
 	defaultConfigKey   = "config"
+	runtimeStateKey    = "runtime-state"
 )
 
 // PostgresStoreConfig captures configuration required to initialize a Postgres-backed store.
@@ -536,6 +537,33 @@ func (s *PostgresStore) persistConfig(ctx context.Context, data []byte) error {
 	return nil
 }
 
+// LoadRuntimeState reads the runtime state snapshot stored alongside the configuration.
+func (s *PostgresStore) LoadRuntimeState(ctx context.Context) ([]byte, error) {
+	query := fmt.Sprintf("SELECT content FROM %s WHERE id = $1", s.fullTableName(s.cfg.ConfigTable))
+	var content string
+	if err := s.db.QueryRowContext(ctx, query, runtimeStateKey).Scan(&content); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("postgres store: load runtime state: %w", err)
+	}
+	return []byte(content), nil
+}
+
+// SaveRuntimeState upserts the runtime state snapshot into the config table.
+func (s *PostgresStore) SaveRuntimeState(ctx context.Context, data []byte) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, content, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (id)
+		DO UPDATE SET content = EXCLUDED.content, updated_at = NOW()
+	`, s.fullTableName(s.cfg.ConfigTable))
+	if _, err := s.db.ExecContext(ctx, query, runtimeStateKey, string(data)); err != nil {
+		return fmt.Errorf("postgres store: upsert runtime state: %w", err)
+	}
+	return nil
+}
+
 func (s *PostgresStore) deleteConfigRecord(ctx context.Context) error {
 	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", s.fullTableName(s.cfg.ConfigTable))
 	if _, err := s.db.ExecContext(ctx, query, defaultConfigKey); err != nil {
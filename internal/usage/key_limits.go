@@ -0,0 +1,238 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+// Names of the per-key limits, matching their configuration keys.
+const (
+	LimitRequestsPerDay = "requests-per-day"
+	LimitTokensPerDay   = "tokens-per-day"
+	LimitTokensPerMonth = "tokens-per-month"
+)
+
+var defaultKeyUsage = NewKeyUsageTracker()
+
+func init() {
+	coreusage.RegisterPlugin(defaultKeyUsage)
+}
+
+// GetKeyUsageTracker returns the shared per-key consumption tracker.
+func GetKeyUsageTracker() *KeyUsageTracker { return defaultKeyUsage }
+
+// KeyLimits caps what a client key may consume. Zero fields are unlimited.
+type KeyLimits struct {
+	RequestsPerDay int64
+	TokensPerDay   int64
+	TokensPerMonth int64
+}
+
+// KeyUsage reports what a client key consumed in the current UTC day and month.
+type KeyUsage struct {
+	Key             string `json:"key"`
+	Day             string `json:"day"`
+	Month           string `json:"month"`
+	RequestsToday   int64  `json:"requests_today"`
+	TokensToday     int64  `json:"tokens_today"`
+	TokensThisMonth int64  `json:"tokens_this_month"`
+}
+
+// KeyLimitExceeded describes a limit a client key has used up.
+type KeyLimitExceeded struct {
+	Limit   string
+	Max     int64
+	Used    int64
+	ResetAt time.Time
+}
+
+// KeyUsageTracker counts the successful requests and tokens of each client key per UTC day and
+// month. It implements coreusage.Plugin, so counts trail the requests they belong to.
+//
+// Counts live in memory. The service saves them with the auth runtime state, so a restart loses at
+// most the requests since the last periodic save, and each replica of a multi-instance deployment
+// enforces the caps on its own traffic only. Caps are therefore best-effort, not exact quotas.
+// Shadow requests sent by mirror rules carry no client key and are not counted.
+type KeyUsageTracker struct {
+	mu   sync.Mutex
+	keys map[string]*KeyUsage
+}
+
+// NewKeyUsageTracker constructs an empty tracker.
+func NewKeyUsageTracker() *KeyUsageTracker {
+	return &KeyUsageTracker{keys: make(map[string]*KeyUsage)}
+}
+
+// HandleUsage implements coreusage.Plugin. Failed requests and records without a client key are
+// not counted.
+func (t *KeyUsageTracker) HandleUsage(_ context.Context, record coreusage.Record) {
+	if record.Failed {
+		return
+	}
+	t.Add(record.APIKey, normaliseDetail(record.Detail).TotalTokens, time.Now())
+}
+
+// Add counts one request using tokens for key at now.
+func (t *KeyUsageTracker) Add(key string, tokens int64, now time.Time) {
+	key = strings.TrimSpace(key)
+	if t == nil || key == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.keys[key]
+	if !ok {
+		entry = &KeyUsage{Key: key}
+		t.keys[key] = entry
+	}
+	rollKeyUsage(entry, now)
+	entry.RequestsToday++
+	if tokens > 0 {
+		entry.TokensToday += tokens
+		entry.TokensThisMonth += tokens
+	}
+}
+
+// Usage returns the consumption of key in the windows containing now.
+func (t *KeyUsageTracker) Usage(key string, now time.Time) KeyUsage {
+	if t == nil {
+		return KeyUsage{Key: key}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.keys[key]
+	if !ok {
+		usage := KeyUsage{Key: key}
+		rollKeyUsage(&usage, now)
+		return usage
+	}
+	rollKeyUsage(entry, now)
+	return *entry
+}
+
+// Snapshot returns the consumption of every tracked key in the windows containing now, ordered by
+// key.
+func (t *KeyUsageTracker) Snapshot(now time.Time) []KeyUsage {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]KeyUsage, 0, len(t.keys))
+	for _, entry := range t.keys {
+		rollKeyUsage(entry, now)
+		out = append(out, *entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// Check returns the first of limits key has used up at now.
+func (t *KeyUsageTracker) Check(key string, limits KeyLimits, now time.Time) (KeyLimitExceeded, bool) {
+	if limits == (KeyLimits{}) {
+		return KeyLimitExceeded{}, false
+	}
+	usage := t.Usage(key, now)
+	now = now.UTC()
+	nextDay := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	switch {
+	case limits.RequestsPerDay > 0 && usage.RequestsToday >= limits.RequestsPerDay:
+		return KeyLimitExceeded{Limit: LimitRequestsPerDay, Max: limits.RequestsPerDay, Used: usage.RequestsToday, ResetAt: nextDay}, true
+	case limits.TokensPerDay > 0 && usage.TokensToday >= limits.TokensPerDay:
+		return KeyLimitExceeded{Limit: LimitTokensPerDay, Max: limits.TokensPerDay, Used: usage.TokensToday, ResetAt: nextDay}, true
+	case limits.TokensPerMonth > 0 && usage.TokensThisMonth >= limits.TokensPerMonth:
+		return KeyLimitExceeded{Limit: LimitTokensPerMonth, Max: limits.TokensPerMonth, Used: usage.TokensThisMonth, ResetAt: nextMonth}, true
+	}
+	return KeyLimitExceeded{}, false
+}
+
+// Reset clears the consumption of key and reports whether it was tracked.
+func (t *KeyUsageTracker) Reset(key string) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.keys[key]
+	delete(t.keys, key)
+	return ok
+}
+
+// ResetAll clears the consumption of every key.
+func (t *KeyUsageTracker) ResetAll() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.keys = make(map[string]*KeyUsage)
+	t.mu.Unlock()
+}
+
+// CaptureRuntimeState returns the consumption of the current windows for saving across restarts.
+func (t *KeyUsageTracker) CaptureRuntimeState() (json.RawMessage, error) {
+	var keep []KeyUsage
+	for _, usage := range t.Snapshot(time.Now()) {
+		if usage.RequestsToday > 0 || usage.TokensToday > 0 || usage.TokensThisMonth > 0 {
+			keep = append(keep, usage)
+		}
+	}
+	if len(keep) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(keep)
+}
+
+// RestoreRuntimeState adds saved consumption to the current counts. Windows that have since ended
+// are dropped.
+func (t *KeyUsageTracker) RestoreRuntimeState(data json.RawMessage) error {
+	if t == nil || len(data) == 0 {
+		return nil
+	}
+	var saved []KeyUsage
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, usage := range saved {
+		key := strings.TrimSpace(usage.Key)
+		if key == "" {
+			continue
+		}
+		rollKeyUsage(&usage, now)
+		entry, ok := t.keys[key]
+		if !ok {
+			entry = &KeyUsage{Key: key}
+			t.keys[key] = entry
+		}
+		rollKeyUsage(entry, now)
+		entry.RequestsToday += usage.RequestsToday
+		entry.TokensToday += usage.TokensToday
+		entry.TokensThisMonth += usage.TokensThisMonth
+	}
+	return nil
+}
+
+// rollKeyUsage clears the counters of windows that ended before now.
+func rollKeyUsage(entry *KeyUsage, now time.Time) {
+	now = now.UTC()
+	day := now.Format("2006-01-02")
+	month := now.Format("2006-01")
+	if entry.Day != day {
+		entry.Day = day
+		entry.RequestsToday = 0
+		entry.TokensToday = 0
+	}
+	if entry.Month != month {
+		entry.Month = month
+		entry.TokensThisMonth = 0
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
 )
 
@@ -65,6 +66,9 @@ type RequestStatistics struct {
 	failureCount  int64
 	totalTokens   int64
 
+	affinityHits   int64
+	affinityMisses int64
+
 	apis map[string]*apiStats
 
 	requestsByDay  map[string]int64
@@ -94,6 +98,12 @@ type RequestDetail struct {
 	AuthIndex string     `json:"auth_index"`
 	Tokens    TokenStats `json:"tokens"`
 	Failed    bool       `json:"failed"`
+	// FallbackFrom records the originally requested model when a model fallback served the request.
+	FallbackFrom string `json:"fallback_from,omitempty"`
+	// FallbackHop records the position of the served model in the fallback chain.
+	FallbackHop int `json:"fallback_hop,omitempty"`
+	// Affinity records the sticky routing outcome ("hit" or "miss") when the sticky strategy applied.
+	Affinity string `json:"affinity,omitempty"`
 }
 
 // TokenStats captures the token usage breakdown for a request.
@@ -112,6 +122,10 @@ type StatisticsSnapshot struct {
 	FailureCount  int64 `json:"failure_count"`
 	TotalTokens   int64 `json:"total_tokens"`
 
+	// AffinityHits and AffinityMisses count sticky routing outcomes.
+	AffinityHits   int64 `json:"affinity_hits"`
+	AffinityMisses int64 `json:"affinity_misses"`
+
 	APIs map[string]APISnapshot `json:"apis"`
 
 	RequestsByDay  map[string]int64 `json:"requests_by_day"`
@@ -190,6 +204,7 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 		s.failureCount++
 	}
 	s.totalTokens += totalTokens
+	s.recordAffinity(record.Affinity)
 
 	stats, ok := s.apis[statsKey]
 	if !ok {
@@ -197,11 +212,14 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 		s.apis[statsKey] = stats
 	}
 	s.updateAPIStats(stats, modelName, RequestDetail{
-		Timestamp: timestamp,
-		Source:    record.Source,
-		AuthIndex: record.AuthIndex,
-		Tokens:    detail,
-		Failed:    failed,
+		Timestamp:    timestamp,
+		Source:       record.Source,
+		AuthIndex:    record.AuthIndex,
+		Tokens:       detail,
+		Failed:       failed,
+		FallbackFrom: record.FallbackFrom,
+		FallbackHop:  record.FallbackHop,
+		Affinity:     record.Affinity,
 	})
 
 	s.requestsByDay[dayKey]++
@@ -210,6 +228,15 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 	s.tokensByHour[hourKey] += totalTokens
 }
 
+func (s *RequestStatistics) recordAffinity(outcome string) {
+	switch outcome {
+	case coreauth.AffinityHit:
+		s.affinityHits++
+	case coreauth.AffinityMiss:
+		s.affinityMisses++
+	}
+}
+
 func (s *RequestStatistics) updateAPIStats(stats *apiStats, model string, detail RequestDetail) {
 	stats.TotalRequests++
 	stats.TotalTokens += detail.Tokens.TotalTokens
@@ -237,6 +264,8 @@ func (s *RequestStatistics) Snapshot() StatisticsSnapshot {
 	result.SuccessCount = s.successCount
 	result.FailureCount = s.failureCount
 	result.TotalTokens = s.totalTokens
+	result.AffinityHits = s.affinityHits
+	result.AffinityMisses = s.affinityMisses
 
 	result.APIs = make(map[string]APISnapshot, len(s.apis))
 	for apiName, stats := range s.apis {
@@ -363,6 +392,7 @@ func (s *RequestStatistics) recordImported(apiName, modelName string, stats *api
 		s.successCount++
 	}
 	s.totalTokens += totalTokens
+	s.recordAffinity(detail.Affinity)
 
 	s.updateAPIStats(stats, modelName, detail)
 
@@ -0,0 +1,28 @@
+package util
+
+import "testing"
+
+func TestMatchWildcard(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"gpt-5", "gpt-5", true},
+		{"gpt-5", "gpt-5-mini", false},
+		{"gpt-*", "gpt-5-mini", true},
+		{"*-mini", "gpt-5-mini", true},
+		{"gemini-*-pro", "gemini-2.5-pro", true},
+		{"gemini-*-pro", "gemini-2.5-flash", false},
+		{"a*b*c", "a-c-b", false},
+		{"ab*ba", "aba", false},
+		{"*", "", true},
+		{"GPT-*", "gpt-5", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := MatchWildcard(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("MatchWildcard(%q, %q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
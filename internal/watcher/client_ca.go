@@ -0,0 +1,84 @@
+// client_ca.go reloads the TLS client CA bundle when its file changes.
+package watcher
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/mtls"
+	log "github.com/sirupsen/logrus"
+)
+
+// syncClientCAWatch watches the directory of the tls.client-ca bundle of cfg, so edits and atomic
+// replacements of the file are seen, and stops watching the directory of a previous bundle.
+func (w *Watcher) syncClientCAWatch(cfg *config.Config) {
+	path := ""
+	if cfg != nil {
+		path = strings.TrimSpace(cfg.TLS.ClientCA)
+	}
+	if path != "" {
+		if abs, errAbs := filepath.Abs(path); errAbs == nil {
+			path = abs
+		}
+		path = w.normalizeAuthPath(path)
+	}
+
+	w.clientsMutex.Lock()
+	previousDir := w.clientCADir
+	if path == w.clientCAPath {
+		w.clientsMutex.Unlock()
+		return
+	}
+	w.clientCAPath = path
+	w.clientCADir = ""
+	if path != "" {
+		w.clientCADir = filepath.Dir(path)
+	}
+	newDir := w.clientCADir
+	w.clientsMutex.Unlock()
+
+	authDir := w.normalizeAuthPath(w.authDir)
+	if previousDir != "" && previousDir != newDir && previousDir != authDir {
+		if errRemove := w.watcher.Remove(previousDir); errRemove != nil {
+			log.Debugf("failed to stop watching client CA directory %s: %v", previousDir, errRemove)
+		}
+	}
+	if newDir == "" || newDir == previousDir || newDir == authDir {
+		return
+	}
+	if errAdd := w.watcher.Add(newDir); errAdd != nil {
+		log.Errorf("failed to watch client CA directory %s: %v", newDir, errAdd)
+		return
+	}
+	log.Debugf("watching client CA bundle: %s", path)
+}
+
+// isClientCAPath reports whether normalizedPath is the watched client CA bundle.
+func (w *Watcher) isClientCAPath(normalizedPath string) bool {
+	w.clientsMutex.RLock()
+	defer w.clientsMutex.RUnlock()
+	return w.clientCAPath != "" && normalizedPath == w.clientCAPath
+}
+
+func (w *Watcher) scheduleClientCAReload() {
+	w.configReloadMu.Lock()
+	defer w.configReloadMu.Unlock()
+	if w.clientCAReloadTimer != nil {
+		w.clientCAReloadTimer.Stop()
+	}
+	w.clientCAReloadTimer = time.AfterFunc(configReloadDebounce, func() {
+		w.configReloadMu.Lock()
+		w.clientCAReloadTimer = nil
+		w.configReloadMu.Unlock()
+		changed, errReload := mtls.Default().Reload()
+		if errReload != nil {
+			log.Errorf("failed to reload client CA bundle, keeping the previous one: %v", errReload)
+			return
+		}
+		if changed {
+			log.Infof("client CA bundle reloaded: %s", mtls.Default().Path())
+		}
+	})
+}
@@ -23,6 +23,10 @@ func (w *Watcher) stopConfigReloadTimer() {
 		w.configReloadTimer.Stop()
 		w.configReloadTimer = nil
 	}
+	if w.clientCAReloadTimer != nil {
+		w.clientCAReloadTimer.Stop()
+		w.clientCAReloadTimer = nil
+	}
 	w.configReloadMu.Unlock()
 }
 
@@ -103,6 +107,7 @@ func (w *Watcher) reloadConfig() bool {
 	w.oldConfigYaml, _ = yaml.Marshal(newConfig)
 	w.config = newConfig
 	w.clientsMutex.Unlock()
+	w.syncClientCAWatch(newConfig)
 
 	var affectedOAuthProviders []string
 	if oldConfig != nil {
@@ -65,6 +65,9 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.QuotaExceeded.SwitchPreviewModel != newCfg.QuotaExceeded.SwitchPreviewModel {
 		changes = append(changes, fmt.Sprintf("quota-exceeded.switch-preview-model: %t -> %t", oldCfg.QuotaExceeded.SwitchPreviewModel, newCfg.QuotaExceeded.SwitchPreviewModel))
 	}
+	if !reflect.DeepEqual(oldCfg.QuotaExceeded.PreviewModels, newCfg.QuotaExceeded.PreviewModels) {
+		changes = append(changes, fmt.Sprintf("quota-exceeded.preview-models: updated (%d -> %d entries)", len(oldCfg.QuotaExceeded.PreviewModels), len(newCfg.QuotaExceeded.PreviewModels)))
+	}
 
 	// API keys (redacted) and counts
 	if len(oldCfg.APIKeys) != len(newCfg.APIKeys) {
@@ -215,6 +218,18 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if entries, _ := DiffOAuthModelAliasChanges(oldCfg.OAuthModelAlias, newCfg.OAuthModelAlias); len(entries) > 0 {
 		changes = append(changes, entries...)
 	}
+	if !reflect.DeepEqual(oldCfg.ModelFallbacks, newCfg.ModelFallbacks) {
+		changes = append(changes, fmt.Sprintf("model-fallbacks: updated (%d -> %d entries)", len(oldCfg.ModelFallbacks), len(newCfg.ModelFallbacks)))
+	}
+	if !reflect.DeepEqual(oldCfg.RateLimits, newCfg.RateLimits) {
+		changes = append(changes, fmt.Sprintf("rate-limits: updated (%d -> %d providers)", len(oldCfg.RateLimits), len(newCfg.RateLimits)))
+	}
+	if oldCfg.CircuitBreaker.ProbeTimeout != newCfg.CircuitBreaker.ProbeTimeout {
+		changes = append(changes, fmt.Sprintf("circuit-breaker.probe-timeout: %d -> %d", oldCfg.CircuitBreaker.ProbeTimeout, newCfg.CircuitBreaker.ProbeTimeout))
+	}
+	if !reflect.DeepEqual(oldCfg.CircuitBreaker.Rules, newCfg.CircuitBreaker.Rules) {
+		changes = append(changes, fmt.Sprintf("circuit-breaker.rules: updated (%d -> %d rules)", len(oldCfg.CircuitBreaker.Rules), len(newCfg.CircuitBreaker.Rules)))
+	}
 
 	// Remote management (never print the key)
 	if oldCfg.RemoteManagement.AllowRemote != newCfg.RemoteManagement.AllowRemote {
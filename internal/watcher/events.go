@@ -39,6 +39,11 @@ func (w *Watcher) start(ctx context.Context) error {
 	}
 	log.Debugf("watching auth directory: %s", w.authDir)
 
+	w.clientsMutex.RLock()
+	cfg := w.config
+	w.clientsMutex.RUnlock()
+	w.syncClientCAWatch(cfg)
+
 	go w.processEvents(ctx)
 
 	w.reloadClients(true, nil, false)
@@ -73,6 +78,11 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 	isConfigEvent := normalizedName == normalizedConfigPath && event.Op&configOps != 0
 	authOps := fsnotify.Create | fsnotify.Write | fsnotify.Remove | fsnotify.Rename
 	isAuthJSON := strings.HasPrefix(normalizedName, normalizedAuthDir) && strings.HasSuffix(normalizedName, ".json") && event.Op&authOps != 0
+	if event.Op&configOps != 0 && w.isClientCAPath(normalizedName) {
+		log.Debugf("client CA bundle change detected: %s %s", event.Op.String(), event.Name)
+		w.scheduleClientCAReload()
+		return
+	}
 	if !isConfigEvent && !isAuthJSON {
 		// Ignore unrelated files (e.g., cookie snapshots *.cookie) and other noise.
 		return
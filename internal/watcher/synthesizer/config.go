@@ -63,6 +63,12 @@ func (s *ConfigSynthesizer) synthesizeGeminiKeys(ctx *SynthesisContext) []*corea
 		if entry.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(entry.Priority)
 		}
+		if entry.MaxConcurrency > 0 {
+			attrs["max_concurrency"] = strconv.Itoa(entry.MaxConcurrency)
+		}
+		addRateLimitAttrs(attrs, entry.RPM, entry.TPM)
+		addTagsAttr(attrs, entry.Tags)
+		addActiveWindowsAttr(attrs, entry.ActiveWindows)
 		if base != "" {
 			attrs["base_url"] = base
 		}
@@ -110,6 +116,12 @@ func (s *ConfigSynthesizer) synthesizeClaudeKeys(ctx *SynthesisContext) []*corea
 		if ck.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(ck.Priority)
 		}
+		if ck.MaxConcurrency > 0 {
+			attrs["max_concurrency"] = strconv.Itoa(ck.MaxConcurrency)
+		}
+		addRateLimitAttrs(attrs, ck.RPM, ck.TPM)
+		addTagsAttr(attrs, ck.Tags)
+		addActiveWindowsAttr(attrs, ck.ActiveWindows)
 		if base != "" {
 			attrs["base_url"] = base
 		}
@@ -157,6 +169,12 @@ func (s *ConfigSynthesizer) synthesizeCodexKeys(ctx *SynthesisContext) []*coreau
 		if ck.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(ck.Priority)
 		}
+		if ck.MaxConcurrency > 0 {
+			attrs["max_concurrency"] = strconv.Itoa(ck.MaxConcurrency)
+		}
+		addRateLimitAttrs(attrs, ck.RPM, ck.TPM)
+		addTagsAttr(attrs, ck.Tags)
+		addActiveWindowsAttr(attrs, ck.ActiveWindows)
 		if ck.BaseURL != "" {
 			attrs["base_url"] = ck.BaseURL
 		}
@@ -215,6 +233,12 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if compat.Priority != 0 {
 				attrs["priority"] = strconv.Itoa(compat.Priority)
 			}
+			if entry.MaxConcurrency > 0 {
+				attrs["max_concurrency"] = strconv.Itoa(entry.MaxConcurrency)
+			}
+			addRateLimitAttrs(attrs, entry.RPM, entry.TPM)
+			addTagsAttr(attrs, entry.Tags)
+			addActiveWindowsAttr(attrs, entry.ActiveWindows)
 			if key != "" {
 				attrs["api_key"] = key
 			}
@@ -294,6 +318,12 @@ func (s *ConfigSynthesizer) synthesizeVertexCompat(ctx *SynthesisContext) []*cor
 		if compat.Priority != 0 {
 			attrs["priority"] = strconv.Itoa(compat.Priority)
 		}
+		if compat.MaxConcurrency > 0 {
+			attrs["max_concurrency"] = strconv.Itoa(compat.MaxConcurrency)
+		}
+		addRateLimitAttrs(attrs, compat.RPM, compat.TPM)
+		addTagsAttr(attrs, compat.Tags)
+		addActiveWindowsAttr(attrs, compat.ActiveWindows)
 		if key != "" {
 			attrs["api_key"] = key
 		}
@@ -130,6 +130,53 @@ func (s *FileSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth, e
 			log.Warnf("auth weight < 0: %s", full)
 		}
 		a.Attributes["weight"] = strconv.Itoa(weight)
+		for _, key := range []string{"rpm", "tpm"} {
+			raw, ok := metadata[key]
+			if !ok {
+				continue
+			}
+			if limit, ok := readMetadataIntValue(raw); ok && limit >= 0 {
+				if limit > 0 {
+					a.Attributes[key] = strconv.Itoa(limit)
+				}
+			} else {
+				log.Warnf("auth metadata %s invalid: %s", key, full)
+			}
+		}
+		for _, key := range []string{"max-concurrency", "max_concurrency"} {
+			raw, ok := metadata[key]
+			if !ok {
+				continue
+			}
+			if limit, ok := readMetadataIntValue(raw); ok && limit >= 0 {
+				if limit > 0 {
+					a.Attributes["max_concurrency"] = strconv.Itoa(limit)
+				}
+			} else {
+				log.Warnf("auth metadata max-concurrency invalid: %s", full)
+			}
+			break
+		}
+		if raw, ok := metadata["tags"]; ok {
+			if tags, ok := readMetadataStringList(raw); ok {
+				addTagsAttr(a.Attributes, tags)
+			} else {
+				log.Warnf("auth metadata tags invalid: %s", full)
+			}
+		}
+		for _, key := range []string{"active_windows", "active-windows"} {
+			raw, ok := metadata[key]
+			if !ok {
+				continue
+			}
+			if windows, ok := readMetadataStringList(raw); ok {
+				addActiveWindowsAttr(a.Attributes, windows)
+			} else {
+				log.Errorf("auth metadata active-windows invalid, credential disabled until fixed: %s", full)
+				a.Attributes["active_windows"] = fmt.Sprint(raw)
+			}
+			break
+		}
 		ApplyAuthExcludedModelsMeta(a, cfg, nil, "oauth")
 		if provider == "gemini-cli" {
 			if virtuals := SynthesizeGeminiVirtualAuths(a, metadata, now); len(virtuals) > 0 {
@@ -206,6 +253,28 @@ func readMetadataIntValue(raw any) (int, bool) {
 	}
 }
 
+// readMetadataStringList reads a list of strings or a comma-separated string.
+func readMetadataStringList(raw any) ([]string, bool) {
+	switch v := raw.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			value, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, value)
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
 // SynthesizeGeminiVirtualAuths creates virtual Auth entries for multi-project Gemini credentials.
 // It disables the primary auth and creates one virtual auth per project.
 func SynthesizeGeminiVirtualAuths(primary *coreauth.Auth, metadata map[string]any, now time.Time) []*coreauth.Auth {
@@ -249,6 +318,22 @@ func SynthesizeGeminiVirtualAuths(primary *coreauth.Auth, metadata map[string]an
 		if authPath != "" {
 			attrs["path"] = authPath
 		}
+		// Projects share the account-wide limit; the manager counts them together.
+		if limit := primary.Attributes["max_concurrency"]; limit != "" {
+			attrs["max_concurrency"] = limit
+		}
+		// Rate limits apply to each project separately.
+		for _, key := range []string{"rpm", "tpm"} {
+			if limit := primary.Attributes[key]; limit != "" {
+				attrs[key] = limit
+			}
+		}
+		if tags := primary.Attributes["tags"]; tags != "" {
+			attrs["tags"] = tags
+		}
+		if windows := primary.Attributes["active_windows"]; windows != "" {
+			attrs["active_windows"] = windows
+		}
 		metadataCopy := map[string]any{
 			"email":             email,
 			"project_id":        projectID,
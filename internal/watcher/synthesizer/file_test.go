@@ -158,6 +158,72 @@ func TestFileSynthesizer_Synthesize_WritesPriorityAndWeight(t *testing.T) {
 	}
 }
 
+func TestFileSynthesizer_Synthesize_WritesMaxConcurrency(t *testing.T) {
+	tempDir := t.TempDir()
+
+	authData := map[string]any{
+		"type":            "codex",
+		"max-concurrency": 2,
+	}
+	data, _ := json.Marshal(authData)
+	err := os.WriteFile(filepath.Join(tempDir, "max-concurrency.json"), data, 0644)
+	if err != nil {
+		t.Fatalf("failed to write auth file: %v", err)
+	}
+
+	synth := NewFileSynthesizer()
+	ctx := &SynthesisContext{
+		Config:      &config.Config{},
+		AuthDir:     tempDir,
+		Now:         time.Now(),
+		IDGenerator: NewStableIDGenerator(),
+	}
+
+	auths, err := synth.Synthesize(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(auths) != 1 {
+		t.Fatalf("expected 1 auth, got %d", len(auths))
+	}
+	if auths[0].Attributes["max_concurrency"] != "2" {
+		t.Errorf("expected max_concurrency 2, got %q", auths[0].Attributes["max_concurrency"])
+	}
+}
+
+func TestFileSynthesizer_Synthesize_WritesTags(t *testing.T) {
+	tempDir := t.TempDir()
+
+	authData := map[string]any{
+		"type": "claude",
+		"tags": []string{"Team-A", " eu ", "team-a"},
+	}
+	data, _ := json.Marshal(authData)
+	err := os.WriteFile(filepath.Join(tempDir, "tags.json"), data, 0644)
+	if err != nil {
+		t.Fatalf("failed to write auth file: %v", err)
+	}
+
+	synth := NewFileSynthesizer()
+	ctx := &SynthesisContext{
+		Config:      &config.Config{},
+		AuthDir:     tempDir,
+		Now:         time.Now(),
+		IDGenerator: NewStableIDGenerator(),
+	}
+
+	auths, err := synth.Synthesize(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(auths) != 1 {
+		t.Fatalf("expected 1 auth, got %d", len(auths))
+	}
+	if auths[0].Attributes["tags"] != "eu,team-a" {
+		t.Errorf("expected tags eu,team-a, got %q", auths[0].Attributes["tags"])
+	}
+}
+
 func TestFileSynthesizer_Synthesize_DefaultWeight(t *testing.T) {
 	tempDir := t.TempDir()
 
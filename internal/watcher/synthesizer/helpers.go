@@ -5,11 +5,13 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/diff"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
 )
 
 // StableIDGenerator generates stable, deterministic IDs for auth entries.
@@ -108,3 +110,44 @@ func addConfigHeadersToAttrs(headers map[string]string, attrs map[string]string)
 		attrs["header:"+key] = val
 	}
 }
+
+// addRateLimitAttrs records per-credential rpm/tpm limits; zero leaves the provider default in effect.
+func addRateLimitAttrs(attrs map[string]string, rpm, tpm int) {
+	if attrs == nil {
+		return
+	}
+	if rpm > 0 {
+		attrs["rpm"] = strconv.Itoa(rpm)
+	}
+	if tpm > 0 {
+		attrs["tpm"] = strconv.Itoa(tpm)
+	}
+}
+
+// addTagsAttr records the normalized credential tags as a comma-separated "tags" attribute.
+func addTagsAttr(attrs map[string]string, tags []string) {
+	if attrs == nil {
+		return
+	}
+	if normalized := coreauth.NormalizeTags(tags); len(normalized) > 0 {
+		attrs["tags"] = strings.Join(normalized, ",")
+	}
+}
+
+// addActiveWindowsAttr records the active windows schedule as the "active_windows" attribute.
+// Schedules that do not parse are recorded verbatim, which keeps the credential inactive and
+// reported as "invalid_active_windows" rather than silently usable around the clock.
+func addActiveWindowsAttr(attrs map[string]string, windows []string) {
+	if attrs == nil || len(windows) == 0 {
+		return
+	}
+	schedule, err := coreauth.ParseActiveWindows(windows)
+	if err != nil {
+		log.Errorf("active-windows of %s invalid, credential disabled until fixed: %v", attrs["source"], err)
+		attrs["active_windows"] = strings.Join(windows, ";")
+		return
+	}
+	if schedule != nil {
+		attrs["active_windows"] = schedule.String()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
@@ -262,3 +263,15 @@ func TestAddConfigHeadersToAttrs(t *testing.T) {
 		})
 	}
 }
+
+func TestAddActiveWindowsAttr_KeepsInvalidSchedules(t *testing.T) {
+	attrs := map[string]string{"source": "config:claude[0]"}
+	addActiveWindowsAttr(attrs, []string{"mon-fri 09:00-17:00 UTC", "sat 25:00-26:00"})
+	auth := &coreauth.Auth{Attributes: attrs}
+	if attrs["active_windows"] == "" || auth.ActiveWindowsError() == nil {
+		t.Fatalf("active_windows = %q, want the invalid schedule kept", attrs["active_windows"])
+	}
+	if _, inactive := auth.NextActivation(time.Now()); !inactive {
+		t.Fatalf("credential with invalid active windows is active")
+	}
+}
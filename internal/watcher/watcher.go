@@ -51,6 +51,11 @@ type Watcher struct {
 	storePersister    storePersister
 	mirroredAuthDir   string
 	oldConfigYaml     []byte
+
+	// clientCAPath is the watched tls.client-ca bundle and clientCADir its watched directory.
+	clientCAPath        string
+	clientCADir         string
+	clientCAReloadTimer *time.Timer
 }
 
 // AuthUpdateAction represents the type of change detected in auth sources.
@@ -0,0 +1,21 @@
+package access
+
+// Result.Metadata keys through which providers cap the consumption of a client. Values are
+// decimal integers; absent or zero is unlimited.
+const (
+	// MetadataRequestsPerDay caps the successful requests per UTC day.
+	MetadataRequestsPerDay = "requests-per-day"
+	// MetadataTokensPerDay caps the tokens used per UTC day.
+	MetadataTokensPerDay = "tokens-per-day"
+	// MetadataTokensPerMonth caps the tokens used per UTC calendar month.
+	MetadataTokensPerMonth = "tokens-per-month"
+)
+
+// Result.Metadata keys through which providers override the default HTTP rate limit of a client.
+// Values are decimal integers; absent or zero keeps the default.
+const (
+	// MetadataRPM caps the requests per minute.
+	MetadataRPM = "rpm"
+	// MetadataMaxConcurrent caps the requests in flight at once.
+	MetadataMaxConcurrent = "max-concurrent"
+)
@@ -0,0 +1,48 @@
+package access
+
+import (
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+)
+
+// Result.Metadata keys through which providers restrict the models a client may use. Pattern
+// lists are comma-separated and '*' matches any substring.
+const (
+	// MetadataLabel names the authenticated client key.
+	MetadataLabel = "label"
+	// MetadataAllowedModels lists the model patterns the client may use; absent allows every model.
+	MetadataAllowedModels = "allowed-models"
+	// MetadataDeniedModels lists the model patterns the client may not use.
+	MetadataDeniedModels = "denied-models"
+)
+
+// ModelAllowed reports whether the access metadata of a client permits model. Denied patterns
+// win over allowed ones. Matching is case-insensitive.
+func ModelAllowed(metadata map[string]string, model string) bool {
+	if len(metadata) == 0 {
+		return true
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	if matchAnyModelPattern(metadata[MetadataDeniedModels], model) {
+		return false
+	}
+	allowed := strings.TrimSpace(metadata[MetadataAllowedModels])
+	return allowed == "" || matchAnyModelPattern(allowed, model)
+}
+
+func matchAnyModelPattern(patterns, model string) bool {
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if util.MatchWildcard(pattern, model) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPattern reports whether value matches pattern case-insensitively, where '*' matches any
+// substring.
+func MatchPattern(pattern, value string) bool {
+	return util.MatchWildcard(strings.ToLower(pattern), strings.ToLower(value))
+}
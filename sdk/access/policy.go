@@ -0,0 +1,35 @@
+package access
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// ApplyPolicyMetadata records policy in the Result.Metadata of an authenticated client, using the
+// keys read by the model restrictions, spending limits and rate limits.
+func ApplyPolicyMetadata(metadata map[string]string, policy config.ClientPolicy) {
+	if metadata == nil {
+		return
+	}
+	if policy.Label != "" {
+		metadata[MetadataLabel] = policy.Label
+	}
+	if len(policy.AllowedModels) > 0 {
+		metadata[MetadataAllowedModels] = strings.Join(policy.AllowedModels, ",")
+	}
+	if len(policy.DeniedModels) > 0 {
+		metadata[MetadataDeniedModels] = strings.Join(policy.DeniedModels, ",")
+	}
+	setLimit := func(key string, limit int64) {
+		if limit > 0 {
+			metadata[key] = strconv.FormatInt(limit, 10)
+		}
+	}
+	setLimit(MetadataRequestsPerDay, policy.Limits.RequestsPerDay)
+	setLimit(MetadataTokensPerDay, policy.Limits.TokensPerDay)
+	setLimit(MetadataTokensPerMonth, policy.Limits.TokensPerMonth)
+	setLimit(MetadataRPM, int64(policy.RateLimit.RPM))
+	setLimit(MetadataMaxConcurrent, int64(policy.RateLimit.MaxConcurrent))
+}
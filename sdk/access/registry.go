@@ -75,7 +75,10 @@ func BuildProviders(root *config.SDKConfig) ([]Provider, error) {
 		providers = append(providers, provider)
 	}
 	if len(providers) == 0 {
-		if inline := config.MakeInlineAPIKeyProvider(root.APIKeys); inline != nil {
+		for _, inline := range []*config.AccessProvider{config.MakeInlineAPIKeyProvider(root.InlineAPIKeys()), root.MakeInlineClientCertProvider()} {
+			if inline == nil {
+				continue
+			}
 			provider, err := BuildProvider(inline, root)
 			if err != nil {
 				return nil, err
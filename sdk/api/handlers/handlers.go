@@ -8,8 +8,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -47,6 +49,20 @@ type ErrorDetail struct {
 
 const idempotencyKeyMetadataKey = "idempotency_key"
 
+// PriorityClassHeader carries the priority class of a request waiting in the cooldown queue.
+const PriorityClassHeader = "X-Priority-Class"
+
+// RouteTagsHeader restricts a request to credentials carrying all of the comma-separated tags.
+const RouteTagsHeader = "X-Route-Tags"
+
+// Response headers reporting that a configured model fallback served the request.
+const (
+	headerModelFallbackFrom   = "X-Model-Fallback-From"
+	headerModelFallbackModel  = "X-Model-Fallback-Model"
+	headerModelFallbackHop    = "X-Model-Fallback-Hop"
+	headerModelFallbackReason = "X-Model-Fallback-Reason"
+)
+
 const (
 	defaultStreamingKeepAliveSeconds = 0
 	defaultStreamingBootstrapRetries = 0
@@ -144,15 +160,33 @@ func requestExecutionMetadata(ctx context.Context) map[string]any {
 	// Idempotency-Key is an optional client-supplied header used to correlate retries.
 	// It is forwarded as execution metadata; when absent we generate a UUID.
 	key := ""
+	meta := make(map[string]any, 4)
 	if ctx != nil {
 		if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil && ginCtx.Request != nil {
 			key = strings.TrimSpace(ginCtx.GetHeader("Idempotency-Key"))
+			// The client key, priority class and route tags steer credential selection.
+			if apiKey, exists := ginCtx.Get("apiKey"); exists {
+				if value, okString := apiKey.(string); okString && value != "" {
+					meta[coreexecutor.ClientAPIKeyMetadataKey] = value
+				}
+			}
+			if class := strings.TrimSpace(ginCtx.GetHeader(PriorityClassHeader)); class != "" {
+				meta[coreexecutor.PriorityClassMetadataKey] = class
+			}
+			if tags := strings.TrimSpace(ginCtx.GetHeader(RouteTagsHeader)); tags != "" {
+				meta[coreexecutor.RouteTagsMetadataKey] = tags
+			}
+			// The model policy of the client also applies to fallback and mirror targets.
+			if access := accessMetadata(ginCtx); len(access) > 0 {
+				meta[coreexecutor.AccessMetadataKey] = access
+			}
 		}
 	}
 	if key == "" {
 		key = uuid.NewString()
 	}
-	return map[string]any{idempotencyKeyMetadataKey: key}
+	meta[idempotencyKeyMetadataKey] = key
+	return meta
 }
 
 func mergeMetadata(base, overlay map[string]any) map[string]any {
@@ -381,6 +415,9 @@ func appendAPIResponse(c *gin.Context, data []byte) {
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
+	if errMsg == nil {
+		errMsg = modelAccessError(ctx, normalizedModel)
+	}
 	if errMsg != nil {
 		return nil, errMsg
 	}
@@ -397,7 +434,10 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 		SourceFormat:    sdktranslator.FromString(handlerType),
 	}
 	opts.Metadata = reqMeta
+	ctx, shadow := h.startMirror(ctx, normalizedModel, req, opts)
+	ctx = withModelFallbackHeaders(ctx, nil)
 	resp, err := h.AuthManager.Execute(ctx, providers, req, opts)
+	shadow.finish(resp.Payload, err)
 	if err != nil {
 		status := http.StatusInternalServerError
 		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
@@ -420,6 +460,9 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
+	if errMsg == nil {
+		errMsg = modelAccessError(ctx, normalizedModel)
+	}
 	if errMsg != nil {
 		return nil, errMsg
 	}
@@ -459,6 +502,9 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
 	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
+	if errMsg == nil {
+		errMsg = modelAccessError(ctx, normalizedModel)
+	}
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
@@ -478,8 +524,14 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		SourceFormat:    sdktranslator.FromString(handlerType),
 	}
 	opts.Metadata = reqMeta
+	ctx, shadow := h.startMirror(ctx, normalizedModel, req, opts)
+	// servedByFallback records whether the current stream comes from a model fallback hop, so a
+	// failing hop does not walk the fallback chain again.
+	var servedByFallback atomic.Bool
+	ctx = withModelFallbackHeaders(ctx, func(coreauth.ModelFallbackInfo) { servedByFallback.Store(true) })
 	chunks, err := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 	if err != nil {
+		shadow.finish(nil, err)
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		status := http.StatusInternalServerError
 		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
@@ -502,9 +554,12 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	go func() {
 		defer close(dataChan)
 		defer close(errChan)
+		var streamFailure error
+		defer func() { shadow.finish(nil, streamFailure) }()
 		sentPayload := false
 		bootstrapRetries := 0
 		maxBootstrapRetries := StreamingBootstrapRetries(h.Cfg)
+		fallbackAttempted := false
 
 		sendErr := func(msg *interfaces.ErrorMessage) bool {
 			if ctx == nil {
@@ -528,6 +583,7 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 			case <-ctx.Done():
 				return false
 			case dataChan <- chunk:
+				shadow.capture(chunk)
 				return true
 			}
 		}
@@ -570,12 +626,23 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 					if !sentPayload {
 						if bootstrapRetries < maxBootstrapRetries && bootstrapEligible(streamErr) {
 							bootstrapRetries++
+							servedByFallback.Store(false)
 							retryChunks, retryErr := h.AuthManager.ExecuteStream(ctx, providers, req, opts)
 							if retryErr == nil {
 								chunks = retryChunks
 								continue outer
 							}
 							streamErr = retryErr
+							// ExecuteStream already walked the fallback chain before failing.
+							fallbackAttempted = true
+						}
+						if !fallbackAttempted && !servedByFallback.Load() {
+							fallbackAttempted = true
+							fallbackChunks, fallbackErr := h.AuthManager.ExecuteStreamModelFallback(ctx, req, opts, streamErr)
+							if fallbackErr == nil {
+								chunks = fallbackChunks
+								continue outer
+							}
 						}
 					}
 
@@ -591,6 +658,7 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 							addon = hdr.Clone()
 						}
 					}
+					streamFailure = streamErr
 					_ = sendErr(&interfaces.ErrorMessage{StatusCode: status, Error: streamErr, Addon: addon})
 					return
 				}
@@ -606,6 +674,34 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	return dataChan, errChan
 }
 
+// withModelFallbackHeaders registers an observer that reports the model fallback hop serving
+// the request through response headers, as long as the headers have not been written yet. The
+// optional onHop is told about every hop as well.
+func withModelFallbackHeaders(ctx context.Context, onHop func(coreauth.ModelFallbackInfo)) context.Context {
+	var ginCtx *gin.Context
+	if ctx != nil {
+		ginCtx, _ = ctx.Value("gin").(*gin.Context)
+	}
+	if ginCtx == nil && onHop == nil {
+		return ctx
+	}
+	return coreauth.WithModelFallbackObserver(ctx, func(info coreauth.ModelFallbackInfo) {
+		if onHop != nil {
+			onHop(info)
+		}
+		if ginCtx == nil || ginCtx.Writer == nil || ginCtx.Writer.Written() {
+			return
+		}
+		header := ginCtx.Writer.Header()
+		header.Set(headerModelFallbackFrom, info.RequestedModel)
+		header.Set(headerModelFallbackModel, info.ServedModel)
+		header.Set(headerModelFallbackHop, strconv.Itoa(info.Hop))
+		if info.Reason != "" {
+			header.Set(headerModelFallbackReason, info.Reason)
+		}
+	})
+}
+
 func statusFromError(err error) int {
 	if err == nil {
 		return 0
@@ -619,6 +715,12 @@ func statusFromError(err error) int {
 }
 
 func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string, normalizedModel string, err *interfaces.ErrorMessage) {
+	return ResolveModelProviders(modelName)
+}
+
+// ResolveModelProviders resolves the "auto" model and returns the providers serving modelName
+// together with the normalized model name the auth manager routes on.
+func ResolveModelProviders(modelName string) (providers []string, normalizedModel string, err *interfaces.ErrorMessage) {
 	resolvedModelName := modelName
 	initialSuffix := thinking.ParseSuffix(modelName)
 	if initialSuffix.ModelName == "auto" {
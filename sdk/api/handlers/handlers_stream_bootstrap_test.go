@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"sync"
 	"testing"
+	"time"
 
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
@@ -130,3 +132,83 @@ func TestExecuteStreamWithAuthManager_RetriesBeforeFirstByte(t *testing.T) {
 		t.Fatalf("expected 2 stream attempts, got %d", executor.Calls())
 	}
 }
+
+// failingStreamExecutor records the model of each stream and fails it before the first byte.
+type failingStreamExecutor struct {
+	failOnceStreamExecutor
+	models []string
+}
+
+func (e *failingStreamExecutor) Identifier() string { return "fallback-stream" }
+
+func (e *failingStreamExecutor) ExecuteStream(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (<-chan coreexecutor.StreamChunk, error) {
+	e.mu.Lock()
+	e.models = append(e.models, req.Model)
+	e.mu.Unlock()
+	ch := make(chan coreexecutor.StreamChunk, 1)
+	ch <- coreexecutor.StreamChunk{Err: &coreauth.Error{Code: "rate_limited", Message: "rate limited", HTTPStatus: http.StatusTooManyRequests}}
+	close(ch)
+	return ch, nil
+}
+
+func TestExecuteStreamWithAuthManager_DoesNotRewalkFallbackChain(t *testing.T) {
+	const primary = "stream-fallback-primary"
+	const backup = "stream-fallback-backup"
+
+	executor := &failingStreamExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	manager.SetConfig(&internalconfig.Config{ModelFallbacks: []internalconfig.ModelFallback{
+		{Model: primary, Fallbacks: []string{backup}},
+	}})
+	cooling := &coreauth.Auth{
+		ID:       "stream-fallback-primary-auth",
+		Provider: "fallback-stream",
+		Status:   coreauth.StatusActive,
+		ModelStates: map[string]*coreauth.ModelState{
+			primary: {
+				Unavailable:    true,
+				Status:         coreauth.StatusError,
+				NextRetryAfter: time.Now().Add(time.Hour),
+				Quota:          coreauth.QuotaState{Exceeded: true, NextRecoverAt: time.Now().Add(time.Hour)},
+			},
+		},
+	}
+	// Two backup credentials, so walking the chain again would reach a credential that has not failed yet.
+	backupAuths := []*coreauth.Auth{
+		{ID: "stream-fallback-backup-a", Provider: "fallback-stream", Status: coreauth.StatusActive},
+		{ID: "stream-fallback-backup-b", Provider: "fallback-stream", Status: coreauth.StatusActive},
+	}
+	for _, auth := range append([]*coreauth.Auth{cooling}, backupAuths...) {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("manager.Register(%s): %v", auth.ID, err)
+		}
+	}
+	registry.GetGlobalRegistry().RegisterClient(cooling.ID, cooling.Provider, []*registry.ModelInfo{{ID: primary}})
+	for _, auth := range backupAuths {
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: backup}})
+	}
+	t.Cleanup(func() {
+		registry.GetGlobalRegistry().UnregisterClient(cooling.ID)
+		for _, auth := range backupAuths {
+			registry.GetGlobalRegistry().UnregisterClient(auth.ID)
+		}
+	})
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, manager)
+	dataChan, errChan := handler.ExecuteStreamWithAuthManager(context.Background(), "openai", primary, []byte(`{}`), "")
+	for range dataChan {
+	}
+	var failed bool
+	for msg := range errChan {
+		failed = failed || msg != nil
+	}
+	if !failed {
+		t.Fatalf("expected the failing fallback stream to surface an error")
+	}
+	executor.mu.Lock()
+	defer executor.mu.Unlock()
+	if len(executor.models) != 1 || executor.models[0] != backup {
+		t.Fatalf("streamed models = %v, want the fallback hop once", executor.models)
+	}
+}
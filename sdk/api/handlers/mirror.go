@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/mirror"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// mirrorTimeout bounds a shadow request; no client waits for it.
+const mirrorTimeout = 10 * time.Minute
+
+// shadowRequest pairs a served request with its background copy until both responses are known.
+type shadowRequest struct {
+	model    string
+	started  time.Time
+	probe    *mirror.Probe
+	mirrored chan mirror.Result
+	once     sync.Once
+
+	mu       sync.Mutex
+	captured []byte
+}
+
+// startMirror dispatches a background copy of the request to the target of the first mirror rule
+// matching model. It returns ctx carrying the usage probe of the served request and the shadow to
+// finish with the served response, or a nil shadow when the request is not mirrored. The copy
+// always runs non-streaming and its response never reaches the client. It runs outside the client
+// request, so its usage carries no client key and is exempt from the key's limits.
+func (h *BaseAPIHandler) startMirror(ctx context.Context, model string, req coreexecutor.Request, opts coreexecutor.Options) (context.Context, *shadowRequest) {
+	if h == nil || h.Cfg == nil || len(h.Cfg.Mirrors) == 0 || h.AuthManager == nil {
+		return ctx, nil
+	}
+	clientKey, _ := opts.Metadata[coreexecutor.ClientAPIKeyMetadataKey].(string)
+	rule, ok := mirror.Select(allowedMirrorRules(h.Cfg.Mirrors, opts), model, clientKey)
+	if !ok {
+		return ctx, nil
+	}
+	providers, target, errMsg := h.getRequestDetails(rule.Target)
+	if errMsg != nil {
+		log.Debugf("mirror: skipping target %s: %v", rule.Target, errMsg.Error)
+		return ctx, nil
+	}
+
+	shadowReq := coreexecutor.Request{Model: target, Payload: cloneBytes(req.Payload)}
+	shadowOpts := opts
+	shadowOpts.Stream = false
+	shadowOpts.OriginalRequest = cloneBytes(opts.OriginalRequest)
+	shadowOpts.Metadata = mergeMetadata(opts.Metadata, map[string]any{
+		coreexecutor.RequestedModelMetadataKey: target,
+		idempotencyKeyMetadataKey:              uuid.NewString(),
+	})
+
+	shadow := &shadowRequest{model: model, started: time.Now(), mirrored: make(chan mirror.Result, 1)}
+	ctx, shadow.probe = mirror.WithProbe(ctx)
+	go func() {
+		shadowCtx, cancel := context.WithTimeout(context.Background(), mirrorTimeout)
+		defer cancel()
+		shadowCtx, probe := mirror.WithProbe(shadowCtx)
+		started := time.Now()
+		resp, err := h.AuthManager.Execute(shadowCtx, providers, shadowReq, shadowOpts)
+		shadow.mirrored <- mirror.NewResult(target, time.Since(started), resp.Payload, err, probe)
+	}()
+	return ctx, shadow
+}
+
+// allowedMirrorRules drops the rules whose target the model policy of the client carried in opts
+// does not permit.
+func allowedMirrorRules(rules []config.MirrorRule, opts coreexecutor.Options) []config.MirrorRule {
+	access, _ := opts.Metadata[coreexecutor.AccessMetadataKey].(map[string]string)
+	if access[sdkaccess.MetadataAllowedModels] == "" && access[sdkaccess.MetadataDeniedModels] == "" {
+		return rules
+	}
+	allowed := make([]config.MirrorRule, 0, len(rules))
+	for _, rule := range rules {
+		if sdkaccess.ModelAllowed(access, thinking.ParseSuffix(rule.Target).ModelName) {
+			allowed = append(allowed, rule)
+		}
+	}
+	return allowed
+}
+
+// capture records a streamed chunk of the served response, up to the recording limit.
+func (s *shadowRequest) capture(chunk []byte) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if room := mirror.MaxResponseBytes + 1 - len(s.captured); room > 0 {
+		if len(chunk) > room {
+			chunk = chunk[:room]
+		}
+		s.captured = append(s.captured, chunk...)
+	}
+	s.mu.Unlock()
+}
+
+// finish records the comparison once the shadow response arrives, without blocking the caller.
+// A nil payload uses the streamed chunks captured so far.
+func (s *shadowRequest) finish(payload []byte, err error) {
+	if s == nil {
+		return
+	}
+	s.once.Do(func() {
+		if payload == nil {
+			s.mu.Lock()
+			payload = s.captured
+			s.mu.Unlock()
+		}
+		primary := mirror.NewResult(s.model, time.Since(s.started), payload, err, s.probe)
+		go func() {
+			mirror.DefaultLog().Add(mirror.Comparison{
+				ID:        uuid.NewString(),
+				Timestamp: s.started,
+				Primary:   primary,
+				Mirror:    <-s.mirrored,
+			})
+		}()
+	})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/mirror"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+type echoModelExecutor struct{}
+
+func (echoModelExecutor) Identifier() string { return "mirror-test" }
+
+func (echoModelExecutor) Execute(_ context.Context, _ *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{Payload: []byte("served:" + req.Model)}, nil
+}
+
+func (echoModelExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (<-chan coreexecutor.StreamChunk, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "ExecuteStream not implemented"}
+}
+
+func (echoModelExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (echoModelExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (echoModelExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func TestExecuteWithAuthManager_MirrorsToTargetModel(t *testing.T) {
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(echoModelExecutor{})
+	auth := &coreauth.Auth{ID: "mirror-auth", Provider: "mirror-test", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "mirror-source"}, {ID: "mirror-target"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	mirror.DefaultLog().Clear()
+	t.Cleanup(mirror.DefaultLog().Clear)
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{
+		Mirrors: []sdkconfig.MirrorRule{
+			{Model: "mirror-*", Target: "mirror-target", SampleRate: 1, ClientKeys: []string{"team-b"}},
+			{Model: "mirror-s*", Target: "mirror-target", SampleRate: 1},
+		},
+	}, manager)
+	resp, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "mirror-source", []byte(`{"model":"mirror-source"}`), "")
+	if errMsg != nil {
+		t.Fatalf("ExecuteWithAuthManager() error = %v", errMsg.Error)
+	}
+	if string(resp) != "served:mirror-source" {
+		t.Fatalf("response = %q, want the source model response", resp)
+	}
+
+	var entries []mirror.Comparison
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if entries = mirror.DefaultLog().Entries(0); len(entries) > 0 {
+			break
+		}
+	}
+	if len(entries) != 1 {
+		t.Fatalf("comparisons = %d, want 1", len(entries))
+	}
+	if entries[0].Primary.Response != "served:mirror-source" || entries[0].Mirror.Response != "served:mirror-target" {
+		t.Fatalf("comparison = %+v", entries[0])
+	}
+	if entries[0].Mirror.Model != "mirror-target" || entries[0].Primary.Model != "mirror-source" {
+		t.Fatalf("comparison models = %s / %s", entries[0].Primary.Model, entries[0].Mirror.Model)
+	}
+}
+
+func TestAllowedMirrorRules_SkipsTargetsDeniedToClient(t *testing.T) {
+	rules := []sdkconfig.MirrorRule{
+		{Model: "gpt-*", Target: "claude-opus-4(high)", SampleRate: 1},
+		{Model: "gpt-*", Target: "gpt-5-mini", SampleRate: 1},
+	}
+	opts := coreexecutor.Options{Metadata: map[string]any{
+		coreexecutor.AccessMetadataKey: map[string]string{sdkaccess.MetadataAllowedModels: "gpt-*"},
+	}}
+	rule, ok := mirror.Select(allowedMirrorRules(rules, opts), "gpt-5", "")
+	if !ok || rule.Target != "gpt-5-mini" {
+		t.Fatalf("selected rule = %+v (ok=%v), want the gpt-5-mini target", rule, ok)
+	}
+	if got := allowedMirrorRules(rules, coreexecutor.Options{}); len(got) != len(rules) {
+		t.Fatalf("unrestricted client kept %d rules, want %d", len(got), len(rules))
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+)
+
+// accessMetadata returns the metadata the access provider attached to the authenticated client.
+func accessMetadata(c *gin.Context) map[string]string {
+	if c == nil {
+		return nil
+	}
+	raw, exists := c.Get("accessMetadata")
+	if !exists {
+		return nil
+	}
+	metadata, _ := raw.(map[string]string)
+	return metadata
+}
+
+// modelAccessError rejects requests for models the client key may not use, before any routing.
+func modelAccessError(ctx context.Context, model string) *interfaces.ErrorMessage {
+	if ctx == nil {
+		return nil
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok {
+		return nil
+	}
+	baseModel := strings.TrimSpace(thinking.ParseSuffix(model).ModelName)
+	if sdkaccess.ModelAllowed(accessMetadata(ginCtx), baseModel) {
+		return nil
+	}
+	return &interfaces.ErrorMessage{StatusCode: http.StatusForbidden, Error: fmt.Errorf("model %s is not allowed for this API key", baseModel)}
+}
+
+// FilterModelsForClient drops the models the client key of c may not use from a model listing.
+// Models are identified by their "id", or their "name" without the "models/" prefix.
+func (h *BaseAPIHandler) FilterModelsForClient(c *gin.Context, models []map[string]any) []map[string]any {
+	metadata := accessMetadata(c)
+	if metadata[sdkaccess.MetadataAllowedModels] == "" && metadata[sdkaccess.MetadataDeniedModels] == "" {
+		return models
+	}
+	filtered := make([]map[string]any, 0, len(models))
+	for _, model := range models {
+		id, _ := model["id"].(string)
+		if id == "" {
+			name, _ := model["name"].(string)
+			id = strings.TrimPrefix(name, "models/")
+		}
+		if sdkaccess.ModelAllowed(metadata, id) {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func clientKeyContext(metadata map[string]string) (*gin.Context, context.Context) {
+	gin.SetMode(gin.TestMode)
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	ginCtx.Set("accessMetadata", metadata)
+	return ginCtx, context.WithValue(context.Background(), "gin", ginCtx)
+}
+
+func TestExecuteWithAuthManager_RejectsDeniedModel(t *testing.T) {
+	registry.GetGlobalRegistry().RegisterClient("model-access-auth", "openai", []*registry.ModelInfo{{ID: "gpt-5-pro"}, {ID: "claude-sonnet-4"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient("model-access-auth") })
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, coreauth.NewManager(nil, nil, nil))
+	_, ctx := clientKeyContext(map[string]string{
+		sdkaccess.MetadataAllowedModels: "gpt-*",
+		sdkaccess.MetadataDeniedModels:  "gpt-5-pro*",
+	})
+
+	_, errMsg := handler.ExecuteWithAuthManager(ctx, "openai", "gpt-5-pro(high)", []byte(`{}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("denied model error = %+v, want 403", errMsg)
+	}
+	_, errMsg = handler.ExecuteWithAuthManager(ctx, "openai", "claude-sonnet-4", []byte(`{}`), "")
+	if errMsg == nil || errMsg.StatusCode != http.StatusForbidden {
+		t.Fatalf("model outside allowlist error = %+v, want 403", errMsg)
+	}
+}
+
+func TestFilterModelsForClient(t *testing.T) {
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, nil)
+	models := []map[string]any{
+		{"id": "gpt-5"},
+		{"id": "gpt-5-pro"},
+		{"name": "models/gemini-2.5-pro"},
+		{"id": "claude-sonnet-4"},
+	}
+
+	ginCtx, _ := clientKeyContext(map[string]string{
+		sdkaccess.MetadataAllowedModels: "GPT-*,gemini-*",
+		sdkaccess.MetadataDeniedModels:  "*-pro",
+	})
+	filtered := handler.FilterModelsForClient(ginCtx, models)
+	if len(filtered) != 1 || filtered[0]["id"] != "gpt-5" {
+		t.Fatalf("filtered models = %v, want only gpt-5", filtered)
+	}
+
+	plain, _ := clientKeyContext(nil)
+	if got := handler.FilterModelsForClient(plain, models); len(got) != len(models) {
+		t.Fatalf("unrestricted key saw %d models, want %d", len(got), len(models))
+	}
+}
@@ -15,6 +15,10 @@ import (
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 )
 
+// runtimeStateFileName is the auth-directory file holding the runtime state snapshot. It has no
+// .json suffix so neither the store nor the watcher treats it as an auth file.
+const runtimeStateFileName = ".runtime-state"
+
 // FileTokenStore persists token records and auth metadata using the filesystem as backing storage.
 type FileTokenStore struct {
 	mu      sync.Mutex
@@ -143,6 +147,44 @@ func (s *FileTokenStore) List(ctx context.Context) ([]*cliproxyauth.Auth, error)
 	return entries, nil
 }
 
+// LoadRuntimeState reads the runtime state snapshot from the auth directory.
+func (s *FileTokenStore) LoadRuntimeState(ctx context.Context) ([]byte, error) {
+	dir := s.baseDirSnapshot()
+	if dir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, runtimeStateFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("auth filestore: read runtime state failed: %w", err)
+	}
+	return data, nil
+}
+
+// SaveRuntimeState writes the runtime state snapshot to the auth directory.
+func (s *FileTokenStore) SaveRuntimeState(ctx context.Context, data []byte) error {
+	dir := s.baseDirSnapshot()
+	if dir == "" {
+		return fmt.Errorf("auth filestore: directory not configured")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("auth filestore: create dir failed: %w", err)
+	}
+	path := filepath.Join(dir, runtimeStateFileName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("auth filestore: write runtime state failed: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("auth filestore: rename runtime state failed: %w", err)
+	}
+	return nil
+}
+
 // Delete removes the auth file.
 func (s *FileTokenStore) Delete(ctx context.Context, id string) error {
 	id = strings.TrimSpace(id)
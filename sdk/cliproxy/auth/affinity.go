@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync/atomic"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// AffinityHit marks a request routed to the credential its conversation was pinned to.
+	AffinityHit = "hit"
+	// AffinityMiss marks a request with an affinity key that had to be (re)pinned.
+	AffinityMiss = "miss"
+)
+
+type affinityContextKey struct{}
+
+// affinityProbe carries the sticky routing outcome of the latest pick of a request.
+type affinityProbe struct {
+	outcome atomic.Value
+}
+
+// withAffinityProbe returns ctx carrying a probe the selector reports affinity outcomes into.
+// An existing probe is reused so retries and fallback hops report into the same request.
+func withAffinityProbe(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Value(affinityContextKey{}).(*affinityProbe); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, affinityContextKey{}, &affinityProbe{})
+}
+
+func recordAffinity(ctx context.Context, outcome string) {
+	if ctx == nil {
+		return
+	}
+	if probe, ok := ctx.Value(affinityContextKey{}).(*affinityProbe); ok && probe != nil {
+		probe.outcome.Store(outcome)
+	}
+}
+
+// AffinityFromContext returns the sticky routing outcome (AffinityHit or AffinityMiss) of the
+// credential currently serving the request, or "" when sticky routing did not apply.
+func AffinityFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	probe, ok := ctx.Value(affinityContextKey{}).(*affinityProbe)
+	if !ok || probe == nil {
+		return ""
+	}
+	outcome, _ := probe.outcome.Load().(string)
+	return outcome
+}
+
+// stickyAffinityKey derives the conversation key used by StickySelector from the inbound request.
+// Explicit client identifiers win: Claude metadata.user_id, then the Codex prompt_cache_key.
+// Otherwise the system prompt and first user message are hashed, which stay constant across
+// the turns of one conversation. An empty key disables stickiness for the request.
+func stickyAffinityKey(payload []byte) string {
+	if len(payload) == 0 || !gjson.ValidBytes(payload) {
+		return ""
+	}
+	if userID := strings.TrimSpace(gjson.GetBytes(payload, "metadata.user_id").String()); userID != "" {
+		return "user:" + userID
+	}
+	if cacheKey := strings.TrimSpace(gjson.GetBytes(payload, "prompt_cache_key").String()); cacheKey != "" {
+		return "cache:" + cacheKey
+	}
+	system, firstUser := conversationSeed(payload)
+	if system == "" && firstUser == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(system + "\x00" + firstUser))
+	return "prompt:" + hex.EncodeToString(sum[:16])
+}
+
+// conversationSeed extracts the raw system prompt and first user message across the
+// Claude, OpenAI chat, OpenAI Responses and Gemini request shapes.
+func conversationSeed(payload []byte) (system, firstUser string) {
+	for _, path := range []string{"system", "instructions", "systemInstruction", "system_instruction"} {
+		if value := gjson.GetBytes(payload, path); value.Exists() {
+			system = value.Raw
+			break
+		}
+	}
+	for _, path := range []string{"messages", "input", "contents"} {
+		value := gjson.GetBytes(payload, path)
+		if !value.Exists() {
+			continue
+		}
+		if value.Type == gjson.String {
+			firstUser = value.Raw
+			break
+		}
+		value.ForEach(func(_, item gjson.Result) bool {
+			role := item.Get("role").String()
+			switch role {
+			case "system", "developer":
+				if system == "" {
+					system = item.Get("content").Raw
+				}
+			case "user", "":
+				if path == "contents" {
+					firstUser = item.Get("parts").Raw
+				} else {
+					firstUser = item.Get("content").Raw
+				}
+				return false
+			}
+			return true
+		})
+		if firstUser != "" {
+			break
+		}
+	}
+	return system, firstUser
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// Reasons a credential is not passed to the selector, as reported by routing explanations.
+const (
+	skipDisabled       = "disabled"
+	skipNoExecutor     = "executor_not_registered"
+	skipModel          = "model_not_supported"
+	skipTags           = "tags_mismatch"
+	skipProbeInFlight  = "circuit_probe_in_flight"
+	skipMaxConcurrency = "max_concurrency"
+	skipRateLimited    = "rate_limited"
+)
+
+// candidateFilter evaluates credentials for one request the way dispatch does, so request
+// routing and routing explanations agree on which credentials reach the selector.
+type candidateFilter struct {
+	m        *Manager
+	model    string
+	modelKey string
+	tags     []string
+	estimate int64
+	now      time.Time
+	registry *registry.ModelRegistry
+}
+
+// newCandidateFilter prepares the checks for a request for model. Callers must hold m.mu.
+func (m *Manager) newCandidateFilter(model string, opts cliproxyexecutor.Options, now time.Time) candidateFilter {
+	modelKey := strings.TrimSpace(model)
+	// Always use base model name (without thinking suffix) for auth matching.
+	if modelKey != "" {
+		if parsed := thinking.ParseSuffix(modelKey); parsed.ModelName != "" {
+			modelKey = strings.TrimSpace(parsed.ModelName)
+		}
+	}
+	return candidateFilter{
+		m:        m,
+		model:    model,
+		modelKey: modelKey,
+		tags:     m.routeTags(opts),
+		estimate: estimateRequestTokens(opts.OriginalRequest),
+		now:      now,
+		registry: registry.GetGlobalRegistry(),
+	}
+}
+
+// skipReason reports why auth is not passed to the selector, or "" when it is. For rate-limited
+// credentials it also returns how long until the limits allow the request.
+func (f candidateFilter) skipReason(auth *Auth) (string, time.Duration) {
+	m := f.m
+	switch {
+	case auth.Disabled:
+		return skipDisabled, 0
+	case m.executors[strings.TrimSpace(strings.ToLower(auth.Provider))] == nil:
+		return skipNoExecutor, 0
+	case f.modelKey != "" && f.registry != nil && !f.registry.ClientSupportsModel(auth.ID, f.modelKey):
+		return skipModel, 0
+	case !authHasTags(auth, f.tags):
+		return skipTags, 0
+	case m.breaker.probing(auth.ID, f.model, f.now):
+		return skipProbeInFlight, 0
+	case m.concurrency.saturated(auth):
+		return skipMaxConcurrency, 0
+	}
+	rpm, tpm := m.rateLimitsOf(auth)
+	if wait := m.rateLimits.wait(auth.ID, rpm, tpm, f.estimate, f.now); wait > 0 {
+		return skipRateLimited, wait
+	}
+	return "", 0
+}
+
+// candidatePool collects the credentials passed to the selector and counts why others were
+// skipped, to explain an empty pool.
+type candidatePool struct {
+	auths         []*Auth
+	saturated     int
+	tagMismatched int
+	rateWait      time.Duration
+}
+
+func (p *candidatePool) add(auth *Auth, reason string, wait time.Duration) {
+	switch reason {
+	case "":
+		p.auths = append(p.auths, auth)
+	case skipTags:
+		p.tagMismatched++
+	case skipMaxConcurrency:
+		p.saturated++
+	case skipRateLimited:
+		if p.rateWait == 0 || wait < p.rateWait {
+			p.rateWait = wait
+		}
+	}
+}
+
+// emptyError explains why no credential was collected.
+func (p *candidatePool) emptyError(tags []string) error {
+	switch {
+	case p.rateWait > 0:
+		return newRateLimitedError(p.rateWait)
+	case p.saturated > 0:
+		return newSaturatedError("all credentials are at max concurrency")
+	case p.tagMismatched > 0:
+		return newTagsMismatchError(tags)
+	default:
+		return &Error{Code: "auth_not_found", Message: "no auth available"}
+	}
+}
+
+// runSelector lets selector choose among candidates, widening the priority groups it may draw
+// from when spillover applies. With dryRun the selector is only peeked. Callers must hold m.mu.
+func (m *Manager) runSelector(ctx context.Context, selector Selector, provider, model string, opts cliproxyexecutor.Options, candidates []*Auth, now time.Time, dryRun bool) (*Auth, error) {
+	ctx = m.spilloverContext(ctx, candidates, model, now)
+	if dryRun {
+		return peekSelector(ctx, selector, provider, model, opts, candidates)
+	}
+	return selector.Pick(ctx, provider, model, opts, candidates)
+}
@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+// defaultCircuitProbeTimeout bounds an unanswered half-open probe when circuit-breaker.probe-timeout is unset.
+const defaultCircuitProbeTimeout = 2 * time.Minute
+
+// circuitPolicy decides when a circuit opens and for how long.
+type circuitPolicy struct {
+	threshold int
+	open      time.Duration
+	maxOpen   time.Duration
+	// reason is recorded when the model is suspended in the registry; empty leaves it listed.
+	reason string
+	// transient policies honour the disable-cooling switch of the credential.
+	transient bool
+}
+
+// defaultCircuitPolicy returns the built-in policy for statusCode, matching the cooldowns used
+// before the breaker was configurable.
+func defaultCircuitPolicy(statusCode int) (circuitPolicy, bool) {
+	switch statusCode {
+	case 401:
+		return circuitPolicy{threshold: 1, open: 30 * time.Minute, maxOpen: 30 * time.Minute, reason: "unauthorized"}, true
+	case 402, 403:
+		return circuitPolicy{threshold: 1, open: 30 * time.Minute, maxOpen: 30 * time.Minute, reason: "payment_required"}, true
+	case 404:
+		return circuitPolicy{threshold: 1, open: 12 * time.Hour, maxOpen: 12 * time.Hour, reason: "not_found"}, true
+	case 408, 500, 502, 503, 504:
+		return circuitPolicy{threshold: 1, open: time.Minute, maxOpen: time.Minute, transient: true}, true
+	default:
+		return circuitPolicy{}, false
+	}
+}
+
+// circuitPolicyFor resolves the policy of statusCode for provider. Configured rules win over the
+// built-in defaults; the suspension reason of the default is kept so registry listings stay accurate,
+// and transient statuses still honour disable-cooling.
+func (m *Manager) circuitPolicyFor(provider string, statusCode int) (circuitPolicy, bool) {
+	policy, ok := defaultCircuitPolicy(statusCode)
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil {
+		return policy, ok
+	}
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for _, rule := range cfg.CircuitBreaker.Rules {
+		if rule.Provider != "" && rule.Provider != provider {
+			continue
+		}
+		for _, code := range rule.Status {
+			if code != statusCode {
+				continue
+			}
+			threshold := rule.FailureThreshold
+			if threshold < 1 {
+				threshold = 1
+			}
+			open := time.Duration(rule.OpenInterval) * time.Second
+			maxOpen := time.Duration(rule.MaxOpenInterval) * time.Second
+			if maxOpen < open {
+				maxOpen = open
+			}
+			return circuitPolicy{threshold: threshold, open: open, maxOpen: maxOpen, reason: policy.reason, transient: policy.transient}, true
+		}
+	}
+	return policy, ok
+}
+
+func (m *Manager) circuitProbeTimeout() time.Duration {
+	if cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config); cfg != nil && cfg.CircuitBreaker.ProbeTimeout > 0 {
+		return time.Duration(cfg.CircuitBreaker.ProbeTimeout) * time.Second
+	}
+	return defaultCircuitProbeTimeout
+}
+
+// CircuitState is the state of the circuit of one auth and model.
+type CircuitState string
+
+const (
+	// CircuitClosed lets all traffic through.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejects traffic until the open interval elapses.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe request through.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+type circuit struct {
+	failures   int
+	open       bool
+	openFor    time.Duration
+	openUntil  time.Time
+	probeUntil time.Time
+}
+
+func (c *circuit) state(now time.Time) CircuitState {
+	switch {
+	case !c.open:
+		return CircuitClosed
+	case now.Before(c.openUntil):
+		return CircuitOpen
+	default:
+		return CircuitHalfOpen
+	}
+}
+
+// circuitBreaker tracks consecutive failures per auth and model.
+type circuitBreaker struct {
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{circuits: make(map[string]*circuit)}
+}
+
+func circuitKey(authID, model string) string {
+	return authID + "|" + model
+}
+
+// state reports the circuit state of authID and model.
+func (b *circuitBreaker) state(authID, model string, now time.Time) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c := b.circuits[circuitKey(authID, model)]; c != nil {
+		return c.state(now)
+	}
+	return CircuitClosed
+}
+
+// probing reports whether a half-open circuit already has its probe in flight.
+func (b *circuitBreaker) probing(authID, model string, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[circuitKey(authID, model)]
+	return c != nil && c.state(now) == CircuitHalfOpen && now.Before(c.probeUntil)
+}
+
+// tryProbe admits a request on authID and model. Closed circuits always admit; a half-open
+// circuit admits one probe at a time, until the probe reports or timeout elapses.
+func (b *circuitBreaker) tryProbe(authID, model string, timeout time.Duration, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[circuitKey(authID, model)]
+	if c == nil || c.state(now) != CircuitHalfOpen {
+		return true
+	}
+	if now.Before(c.probeUntil) {
+		return false
+	}
+	c.probeUntil = now.Add(timeout)
+	return true
+}
+
+// cancelProbe clears the in-flight probe of authID and model, either because it was never
+// dispatched or because its result was inconclusive, so the next request may probe again.
+func (b *circuitBreaker) cancelProbe(authID, model string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c := b.circuits[circuitKey(authID, model)]; c != nil {
+		c.probeUntil = time.Time{}
+	}
+}
+
+// recordSuccess closes the circuit of authID and model.
+func (b *circuitBreaker) recordSuccess(authID, model string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.circuits, circuitKey(authID, model))
+}
+
+// recordFailure counts a failure under policy and returns the time the circuit stays open until,
+// or the zero time when it is still closed. A failed probe reopens the circuit with twice the
+// previous interval, capped at the policy maximum.
+func (b *circuitBreaker) recordFailure(authID, model string, policy circuitPolicy, now time.Time) time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := circuitKey(authID, model)
+	c := b.circuits[key]
+	if policy.open <= 0 {
+		if c != nil {
+			c.probeUntil = time.Time{}
+		}
+		return time.Time{}
+	}
+	if c == nil {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	c.probeUntil = time.Time{}
+	c.failures++
+	switch {
+	case c.open && now.Before(c.openUntil):
+		// A request dispatched before the circuit opened; the interval is already running.
+		return c.openUntil
+	case c.open:
+		c.openFor *= 2
+		if c.openFor < policy.open {
+			c.openFor = policy.open
+		}
+		if c.openFor > policy.maxOpen {
+			c.openFor = policy.maxOpen
+		}
+	case c.failures >= policy.threshold:
+		c.open = true
+		c.openFor = policy.open
+	default:
+		return time.Time{}
+	}
+	c.openUntil = now.Add(c.openFor)
+	return c.openUntil
+}
+
+// CircuitState returns the circuit breaker state of auth for model.
+func (m *Manager) CircuitState(authID, model string) CircuitState {
+	if m == nil {
+		return CircuitClosed
+	}
+	return m.breaker.state(authID, model, time.Now())
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	t.Parallel()
+
+	breaker := newCircuitBreaker()
+	policy := circuitPolicy{threshold: 2, open: time.Minute, maxOpen: 3 * time.Minute}
+	now := time.Now()
+
+	if until := breaker.recordFailure("a", "m", policy, now); !until.IsZero() {
+		t.Fatalf("circuit opened below threshold, until = %v", until)
+	}
+	until := breaker.recordFailure("a", "m", policy, now)
+	if want := now.Add(time.Minute); !until.Equal(want) {
+		t.Fatalf("open until = %v, want %v", until, want)
+	}
+	if state := breaker.state("a", "m", now); state != CircuitOpen {
+		t.Fatalf("state = %s, want %s", state, CircuitOpen)
+	}
+
+	halfOpen := now.Add(time.Minute)
+	if !breaker.tryProbe("a", "m", time.Minute, halfOpen) {
+		t.Fatalf("expected first half-open request to probe")
+	}
+	if breaker.tryProbe("a", "m", time.Minute, halfOpen) || !breaker.probing("a", "m", halfOpen) {
+		t.Fatalf("expected a single probe while one is in flight")
+	}
+	if !breaker.tryProbe("a", "m", time.Minute, halfOpen.Add(2*time.Minute)) {
+		t.Fatalf("expected a timed out probe to let another request probe")
+	}
+
+	// A failed probe doubles the interval up to the cap.
+	until = breaker.recordFailure("a", "m", policy, halfOpen)
+	if want := halfOpen.Add(2 * time.Minute); !until.Equal(want) {
+		t.Fatalf("reopened until = %v, want %v", until, want)
+	}
+	until = breaker.recordFailure("a", "m", policy, until)
+	if got := until.Sub(halfOpen.Add(2 * time.Minute)); got != 3*time.Minute {
+		t.Fatalf("reopened interval = %v, want capped at 3m", got)
+	}
+
+	breaker.recordSuccess("a", "m")
+	if state := breaker.state("a", "m", now); state != CircuitClosed {
+		t.Fatalf("state after success = %s, want %s", state, CircuitClosed)
+	}
+}
+
+func TestManager_MarkResult_CircuitBreakerRules(t *testing.T) {
+	const model = "circuit-test-model"
+
+	m := NewManager(nil, nil, nil)
+	m.SetConfig(&internalconfig.Config{CircuitBreaker: internalconfig.CircuitBreakerConfig{
+		Rules: []internalconfig.CircuitBreakerRule{{Provider: "claude", Status: []int{403}, FailureThreshold: 2, OpenInterval: 60}},
+	}})
+	for _, auth := range []*Auth{
+		{ID: "cb-claude", Provider: "claude", Status: StatusActive},
+		{ID: "cb-codex", Provider: "codex", Status: StatusActive},
+	} {
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("register auth %s: %v", auth.ID, errRegister)
+		}
+	}
+	forbidden := &Error{Message: "forbidden", HTTPStatus: 403}
+	retryAfter := func(id string) time.Duration {
+		auth, _ := m.GetByID(id)
+		if auth == nil || auth.ModelStates[model] == nil || auth.ModelStates[model].NextRetryAfter.IsZero() {
+			return 0
+		}
+		return time.Until(auth.ModelStates[model].NextRetryAfter)
+	}
+
+	m.MarkResult(context.Background(), Result{AuthID: "cb-claude", Provider: "claude", Model: model, Error: forbidden})
+	if wait := retryAfter("cb-claude"); wait != 0 {
+		t.Fatalf("first 403 opened the circuit for %v, want closed below threshold", wait)
+	}
+	m.MarkResult(context.Background(), Result{AuthID: "cb-claude", Provider: "claude", Model: model, Error: forbidden})
+	if wait := retryAfter("cb-claude"); wait <= 0 || wait > time.Minute {
+		t.Fatalf("second 403 open interval = %v, want (0, 1m]", wait)
+	}
+
+	// Providers without a matching rule keep the built-in policy.
+	m.MarkResult(context.Background(), Result{AuthID: "cb-codex", Provider: "codex", Model: model, Error: forbidden})
+	if wait := retryAfter("cb-codex"); wait <= 29*time.Minute {
+		t.Fatalf("default 403 open interval = %v, want 30m", wait)
+	}
+
+	m.MarkResult(context.Background(), Result{AuthID: "cb-claude", Provider: "claude", Model: model, Success: true})
+	if state := m.CircuitState("cb-claude", model); state != CircuitClosed {
+		t.Fatalf("state after success = %s, want %s", state, CircuitClosed)
+	}
+}
+
+func TestManager_MarkResult_CircuitBreakerRulesWithoutModel(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetConfig(&internalconfig.Config{CircuitBreaker: internalconfig.CircuitBreakerConfig{
+		Rules: []internalconfig.CircuitBreakerRule{{Provider: "claude", Status: []int{401}, FailureThreshold: 2, OpenInterval: 60}},
+	}})
+	for _, auth := range []*Auth{
+		{ID: "cb-auth-claude", Provider: "claude", Status: StatusActive},
+		{ID: "cb-auth-codex", Provider: "codex", Status: StatusActive},
+	} {
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("register auth %s: %v", auth.ID, errRegister)
+		}
+	}
+	unauthorized := &Error{Message: "unauthorized", HTTPStatus: 401}
+	retryAfter := func(id string) time.Duration {
+		auth, _ := m.GetByID(id)
+		if auth == nil || auth.NextRetryAfter.IsZero() {
+			return 0
+		}
+		return time.Until(auth.NextRetryAfter)
+	}
+
+	m.MarkResult(context.Background(), Result{AuthID: "cb-auth-claude", Provider: "claude", Error: unauthorized})
+	if wait := retryAfter("cb-auth-claude"); wait != 0 {
+		t.Fatalf("first 401 cooled the auth down for %v, want none below threshold", wait)
+	}
+	m.MarkResult(context.Background(), Result{AuthID: "cb-auth-claude", Provider: "claude", Error: unauthorized})
+	if wait := retryAfter("cb-auth-claude"); wait <= 0 || wait > time.Minute {
+		t.Fatalf("second 401 cooldown = %v, want (0, 1m]", wait)
+	}
+
+	m.MarkResult(context.Background(), Result{AuthID: "cb-auth-codex", Provider: "codex", Error: unauthorized})
+	if wait := retryAfter("cb-auth-codex"); wait <= 29*time.Minute {
+		t.Fatalf("default 401 cooldown = %v, want 30m", wait)
+	}
+
+	m.MarkResult(context.Background(), Result{AuthID: "cb-auth-claude", Provider: "claude", Success: true})
+	if state := m.CircuitState("cb-auth-claude", ""); state != CircuitClosed {
+		t.Fatalf("state after success = %s, want %s", state, CircuitClosed)
+	}
+}
+
+func TestManager_MarkResult_CircuitBreakerRulesHonourDisableCooling(t *testing.T) {
+	const model = "circuit-cooling-model"
+
+	m := NewManager(nil, nil, nil)
+	m.SetConfig(&internalconfig.Config{CircuitBreaker: internalconfig.CircuitBreakerConfig{
+		Rules: []internalconfig.CircuitBreakerRule{{Status: []int{503}, FailureThreshold: 1, OpenInterval: 120}},
+	}})
+	for _, auth := range []*Auth{
+		{ID: "cb-no-cooling", Provider: "claude", Status: StatusActive, Metadata: map[string]any{"disable_cooling": true}},
+		{ID: "cb-cooling", Provider: "claude", Status: StatusActive},
+	} {
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("register auth %s: %v", auth.ID, errRegister)
+		}
+	}
+	unavailable := &Error{Message: "unavailable", HTTPStatus: 503}
+	for _, id := range []string{"cb-no-cooling", "cb-cooling"} {
+		m.MarkResult(context.Background(), Result{AuthID: id, Provider: "claude", Model: model, Error: unavailable})
+		m.MarkResult(context.Background(), Result{AuthID: id, Provider: "claude", Error: unavailable})
+	}
+
+	if state := m.CircuitState("cb-no-cooling", model); state != CircuitClosed {
+		t.Fatalf("model circuit with cooling disabled = %s, want %s", state, CircuitClosed)
+	}
+	if auth, _ := m.GetByID("cb-no-cooling"); !auth.NextRetryAfter.IsZero() {
+		t.Fatalf("auth with cooling disabled cooled down until %v", auth.NextRetryAfter)
+	}
+	if state := m.CircuitState("cb-cooling", model); state != CircuitOpen {
+		t.Fatalf("model circuit = %s, want %s", state, CircuitOpen)
+	}
+	if auth, _ := m.GetByID("cb-cooling"); time.Until(auth.NextRetryAfter) <= time.Minute {
+		t.Fatalf("auth cooldown = %v, want the configured 2m", time.Until(auth.NextRetryAfter))
+	}
+}
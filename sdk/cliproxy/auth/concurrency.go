@@ -0,0 +1,277 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+const (
+	// defaultConcurrencyQueueSize bounds waiting requests when routing.concurrency-queue-size is unset.
+	defaultConcurrencyQueueSize = 64
+	// defaultConcurrencyQueueTimeout bounds the wait when routing.concurrency-queue-timeout is unset.
+	defaultConcurrencyQueueTimeout = 30 * time.Second
+)
+
+// maxConcurrencyOf returns the max-concurrency attribute of auth; zero means unlimited.
+func maxConcurrencyOf(auth *Auth) int {
+	if auth == nil || auth.Attributes == nil {
+		return 0
+	}
+	raw := strings.TrimSpace(auth.Attributes["max_concurrency"])
+	if raw == "" {
+		return 0
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed < 0 {
+		return 0
+	}
+	return parsed
+}
+
+// concurrencyKey groups the per-project virtual auths of one Gemini CLI account so the
+// account-wide limit is shared by all of its projects.
+func concurrencyKey(auth *Auth) string {
+	if parent := geminiVirtualParent(auth); parent != "" {
+		return parent
+	}
+	return auth.ID
+}
+
+// concurrencyLimiter enforces max-concurrency per credential and queues requests while every
+// candidate is saturated.
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	inflight map[string]int
+	waiting  int
+	released chan struct{}
+}
+
+func newConcurrencyLimiter() *concurrencyLimiter {
+	return &concurrencyLimiter{inflight: make(map[string]int), released: make(chan struct{})}
+}
+
+// saturated reports whether auth already serves its max-concurrency requests.
+func (l *concurrencyLimiter) saturated(auth *Auth) bool {
+	limit := maxConcurrencyOf(auth)
+	if limit <= 0 {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inflight[concurrencyKey(auth)] >= limit
+}
+
+// tryAcquire reserves a slot on auth. Credentials without a limit always succeed.
+func (l *concurrencyLimiter) tryAcquire(auth *Auth) bool {
+	limit := maxConcurrencyOf(auth)
+	if limit <= 0 {
+		return true
+	}
+	key := concurrencyKey(auth)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inflight[key] >= limit {
+		return false
+	}
+	l.inflight[key]++
+	return true
+}
+
+// release frees the slot reserved by tryAcquire and wakes queued requests.
+func (l *concurrencyLimiter) release(auth *Auth) {
+	if maxConcurrencyOf(auth) <= 0 {
+		return
+	}
+	key := concurrencyKey(auth)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inflight[key] <= 1 {
+		delete(l.inflight, key)
+	} else {
+		l.inflight[key]--
+	}
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// signal returns a channel closed on the next release. Callers grab it before checking
+// saturation so a release in between is not missed.
+func (l *concurrencyLimiter) signal() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.released
+}
+
+// enqueue admits a waiting request unless size requests already wait.
+func (l *concurrencyLimiter) enqueue(size int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.waiting >= size {
+		return false
+	}
+	l.waiting++
+	return true
+}
+
+func (l *concurrencyLimiter) dequeue() {
+	l.mu.Lock()
+	if l.waiting > 0 {
+		l.waiting--
+	}
+	l.mu.Unlock()
+}
+
+// beginAttempt records a dispatched attempt on auth and returns the func that ends it, freeing
+// the max-concurrency slot reserved when auth was picked. The func may be called more than once.
+func (m *Manager) beginAttempt(auth *Auth, model string) func() {
+	releaseLoad := m.loadStats.acquire(auth.ID, model)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			releaseLoad()
+			m.concurrency.release(auth)
+		})
+	}
+}
+
+// InFlightAuth returns the number of requests holding a max-concurrency slot of auth.
+func (m *Manager) InFlightAuth(auth *Auth) int {
+	if m == nil || auth == nil {
+		return 0
+	}
+	m.concurrency.mu.Lock()
+	defer m.concurrency.mu.Unlock()
+	return m.concurrency.inflight[concurrencyKey(auth)]
+}
+
+func (m *Manager) concurrencyQueueSettings() (int, time.Duration) {
+	size, timeout := defaultConcurrencyQueueSize, defaultConcurrencyQueueTimeout
+	if cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config); cfg != nil {
+		if cfg.Routing.ConcurrencyQueueSize != 0 {
+			size = cfg.Routing.ConcurrencyQueueSize
+		}
+		if cfg.Routing.ConcurrencyQueueTimeout > 0 {
+			timeout = time.Duration(cfg.Routing.ConcurrencyQueueTimeout) * time.Second
+		}
+	}
+	return size, timeout
+}
+
+func newSaturatedError(message string) *Error {
+	return &Error{Code: "auth_saturated", Message: message, Retryable: true, HTTPStatus: http.StatusTooManyRequests}
+}
+
+// admissionWait reports whether err means every candidate is saturated or rate limited, and for
+// rate limits how long until one of them can accept the request.
+func admissionWait(err error) (time.Duration, bool) {
+	var rateErr *rateLimitedError
+	if errors.As(err, &rateErr) && rateErr != nil {
+		return rateErr.retryIn, true
+	}
+	var authErr *Error
+	return 0, errors.As(err, &authErr) && authErr != nil && authErr.Code == "auth_saturated"
+}
+
+// acquirePicked runs pick over candidates, claims the half-open probe of model when the circuit
+// of the chosen auth is half-open, reserves a concurrency slot and charges its rate limits with
+// estimate tokens. A candidate that lost its probe, last slot or budget to a concurrent request
+// since filtering is dropped and pick runs again on the rest.
+func (m *Manager) acquirePicked(candidates []*Auth, model string, estimate int64, pick func([]*Auth) (*Auth, error)) (*Auth, error) {
+	var rateWait time.Duration
+	probeTimeout := m.circuitProbeTimeout()
+	for {
+		selected, errPick := pick(candidates)
+		if errPick != nil {
+			return nil, errPick
+		}
+		if selected == nil {
+			return nil, &Error{Code: "auth_not_found", Message: "selector returned no auth"}
+		}
+		now := time.Now()
+		if m.breaker.tryProbe(selected.ID, model, probeTimeout, now) {
+			if m.concurrency.tryAcquire(selected) {
+				rpm, tpm := m.rateLimitsOf(selected)
+				wait, ok := m.rateLimits.tryConsume(selected.ID, rpm, tpm, estimate, now)
+				if ok {
+					return selected, nil
+				}
+				m.concurrency.release(selected)
+				if rateWait == 0 || wait < rateWait {
+					rateWait = wait
+				}
+			}
+			m.breaker.cancelProbe(selected.ID, model)
+		}
+		remaining := make([]*Auth, 0, len(candidates)-1)
+		for _, candidate := range candidates {
+			if candidate.ID != selected.ID {
+				remaining = append(remaining, candidate)
+			}
+		}
+		if len(remaining) == 0 {
+			if rateWait > 0 {
+				return nil, newRateLimitedError(rateWait)
+			}
+			return nil, newSaturatedError("all credentials are at max concurrency")
+		}
+		candidates = remaining
+	}
+}
+
+// waitForAdmission runs pick until it stops failing because every candidate is at
+// max-concurrency or over its rate limits. Meanwhile the request waits in the bounded queue until
+// a slot is released or a rate limit refills, the queue timeout elapses, or ctx is done.
+func waitForAdmission[T any](ctx context.Context, m *Manager, pick func() (T, error)) (T, error) {
+	var zero T
+	signal := m.concurrency.signal()
+	out, err := pick()
+	retryIn, waiting := admissionWait(err)
+	if !waiting {
+		return out, err
+	}
+
+	size, timeout := m.concurrencyQueueSettings()
+	if size < 0 || !m.concurrency.enqueue(size) {
+		return zero, err
+	}
+	defer m.concurrency.dequeue()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		var retry <-chan time.Time
+		var retryTimer *time.Timer
+		if retryIn > 0 {
+			retryTimer = time.NewTimer(retryIn)
+			retry = retryTimer.C
+		}
+		select {
+		case <-ctx.Done():
+			stopTimer(retryTimer)
+			return zero, ctx.Err()
+		case <-deadline.C:
+			stopTimer(retryTimer)
+			return zero, err
+		case <-signal:
+		case <-retry:
+		}
+		stopTimer(retryTimer)
+		signal = m.concurrency.signal()
+		out, err = pick()
+		if retryIn, waiting = admissionWait(err); !waiting {
+			return out, err
+		}
+	}
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// blockingExecutor holds every Execute call until release is closed.
+type blockingExecutor struct {
+	recordingExecutor
+	started chan string
+	release chan struct{}
+}
+
+func (e *blockingExecutor) Execute(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.started <- auth.ID
+	select {
+	case <-e.release:
+	case <-ctx.Done():
+		return cliproxyexecutor.Response{}, ctx.Err()
+	}
+	return e.recordingExecutor.Execute(ctx, auth, req, opts)
+}
+
+func TestManager_Execute_MaxConcurrencySkipsSaturatedAndQueues(t *testing.T) {
+	const model = "max-concurrency-test-model"
+
+	executor := &blockingExecutor{
+		recordingExecutor: recordingExecutor{provider: "claude"},
+		started:           make(chan string, 4),
+		release:           make(chan struct{}),
+	}
+	m := NewManager(nil, &FillFirstSelector{}, nil)
+	m.RegisterExecutor(executor)
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{ConcurrencyQueueSize: 1, ConcurrencyQueueTimeout: 5}})
+
+	for _, id := range []string{"mc-auth-a", "mc-auth-b"} {
+		auth := &Auth{ID: id, Provider: "claude", Status: StatusActive, Attributes: map[string]string{"max_concurrency": "1"}}
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("register auth %s: %v", id, errRegister)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "claude", []*registry.ModelInfo{{ID: model}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	errs := make(chan error, 3)
+	run := func() {
+		_, errExec := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{})
+		errs <- errExec
+	}
+	go run()
+	go run()
+	first, second := <-executor.started, <-executor.started
+	if first == second {
+		t.Fatalf("expected saturated auth to be skipped, both requests went to %s", first)
+	}
+
+	// Both credentials are saturated: the third request queues, a fourth overflows the queue.
+	go run()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		m.concurrency.mu.Lock()
+		waiting := m.concurrency.waiting
+		m.concurrency.mu.Unlock()
+		if waiting == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected third request to wait in the queue")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	_, errOverflow := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{})
+	if status := statusCodeFromError(errOverflow); status != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 when the queue is full, got %d (%v)", status, errOverflow)
+	}
+
+	close(executor.release)
+	<-executor.started
+	for i := 0; i < 3; i++ {
+		if errExec := <-errs; errExec != nil {
+			t.Fatalf("Execute() error = %v", errExec)
+		}
+	}
+	for _, id := range []string{"mc-auth-a", "mc-auth-b"} {
+		if inflight := m.InFlightAuth(&Auth{ID: id, Attributes: map[string]string{"max_concurrency": "1"}}); inflight != 0 {
+			t.Fatalf("InFlightAuth(%s) = %d after completion, want 0", id, inflight)
+		}
+	}
+}
@@ -84,6 +84,13 @@ type Result struct {
 	RetryAfter *time.Duration
 	// Error describes the failure when Success is false.
 	Error *Error
+	// Latency is the time to first byte of a successful streaming execution; zero when unknown.
+	Latency time.Duration
+	// Duration is the response time of a successful non-streaming execution; zero when unknown.
+	Duration time.Duration
+	// Abandoned marks an attempt cancelled because a hedged attempt answered first.
+	// It is reported to hooks but leaves the auth state untouched.
+	Abandoned bool
 }
 
 // Selector chooses an auth candidate for execution.
@@ -91,6 +98,13 @@ type Selector interface {
 	Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error)
 }
 
+// SelectorPeeker is implemented by selectors that can report the credential Pick would return
+// without advancing cursors, pinning conversations or recording any other state. Routing
+// explanations only name a selected credential for selectors that implement it.
+type SelectorPeeker interface {
+	Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error)
+}
+
 // Hook captures lifecycle callbacks for observing auth changes.
 type Hook interface {
 	// OnAuthRegistered fires when a new auth is registered.
@@ -121,8 +135,10 @@ type Manager struct {
 	hook      Hook
 	mu        sync.RWMutex
 	auths     map[string]*Auth
-	// providerOffsets tracks per-model provider rotation state for multi-provider routing.
-	providerOffsets map[string]int
+	// selectorOverrides replace selector for requests matching their model or provider pattern.
+	selectorOverrides []SelectorOverride
+	// providerBalancer splits multi-provider models across providers by configured weight.
+	providerBalancer *providerBalancer
 
 	// Retry controls request retry behavior.
 	requestRetry     atomic.Int32
@@ -139,11 +155,36 @@ type Manager struct {
 	// It is initialized in NewManager; never Load() before first Store().
 	runtimeConfig atomic.Value
 
+	// loadStats tracks in-flight requests and latency per auth+model for load-aware selectors.
+	loadStats *LoadStats
+	// concurrency enforces the max-concurrency attribute of each auth.
+	concurrency *concurrencyLimiter
+	// rateLimits keeps the RPM/TPM token buckets of each auth.
+	rateLimits *rateLimiter
+	// breaker tracks the circuit of each auth+model after upstream errors.
+	breaker *circuitBreaker
+	// hedgeLatency keeps recent non-streaming latencies per model for the learned hedge delay.
+	hedgeLatency *latencyWindow
+	// cooldownQueue orders requests waiting for a credential to recover from cooldown.
+	cooldownQueue *cooldownQueue
+
 	// Optional HTTP RoundTripper provider injected by host.
 	rtProvider RoundTripperProvider
 
 	// Auto refresh state
 	refreshCancel context.CancelFunc
+
+	// pendingRuntime holds saved runtime state not yet handed to a registered auth.
+	pendingRuntime map[string]*authRuntimeState
+	// runtimeMu guards runtimeSaved, the last runtime state snapshot written to the store.
+	runtimeMu     sync.Mutex
+	runtimeSaved  []byte
+	runtimeCancel context.CancelFunc
+
+	// runtimeExtensions and pendingExtensions, guarded by runtimeMu, hold the registered runtime
+	// state extensions and loaded extension state not yet claimed by one.
+	runtimeExtensions map[string]RuntimeStateExtension
+	pendingExtensions map[string]json.RawMessage
 }
 
 // NewManager constructs a manager with optional custom selector and hook.
@@ -155,12 +196,21 @@ func NewManager(store Store, selector Selector, hook Hook) *Manager {
 		hook = NoopHook{}
 	}
 	manager := &Manager{
-		store:           store,
-		executors:       make(map[string]ProviderExecutor),
-		selector:        selector,
-		hook:            hook,
-		auths:           make(map[string]*Auth),
-		providerOffsets: make(map[string]int),
+		store:            store,
+		executors:        make(map[string]ProviderExecutor),
+		selector:         selector,
+		hook:             hook,
+		auths:            make(map[string]*Auth),
+		providerBalancer: newProviderBalancer(),
+		loadStats:        NewLoadStats(),
+		concurrency:      newConcurrencyLimiter(),
+		rateLimits:       newRateLimiter(),
+		breaker:          newCircuitBreaker(),
+		hedgeLatency:     newLatencyWindow(),
+		cooldownQueue:    newCooldownQueue(),
+	}
+	if consumer, ok := selector.(LoadStatsConsumer); ok {
+		consumer.SetLoadStats(manager.loadStats)
 	}
 	// atomic.Value requires non-nil initial value.
 	manager.runtimeConfig.Store(&internalconfig.Config{})
@@ -175,11 +225,22 @@ func (m *Manager) SetSelector(selector Selector) {
 	if selector == nil {
 		selector = &RoundRobinSelector{}
 	}
+	if consumer, ok := selector.(LoadStatsConsumer); ok {
+		consumer.SetLoadStats(m.loadStats)
+	}
 	m.mu.Lock()
 	m.selector = selector
 	m.mu.Unlock()
 }
 
+// LoadStats returns the in-flight and latency tracker fed by request execution.
+func (m *Manager) LoadStats() *LoadStats {
+	if m == nil {
+		return nil
+	}
+	return m.loadStats
+}
+
 // SetStore swaps the underlying persistence store.
 func (m *Manager) SetStore(store Store) {
 	m.mu.Lock()
@@ -414,8 +475,11 @@ func (m *Manager) Register(ctx context.Context, auth *Auth) (*Auth, error) {
 		auth.ID = uuid.NewString()
 	}
 	auth.EnsureIndex()
+	stored := auth.Clone()
 	m.mu.Lock()
-	m.auths[auth.ID] = auth.Clone()
+	m.restorePendingRuntimeLocked(stored)
+	m.auths[auth.ID] = stored
+	auth = stored.Clone()
 	m.mu.Unlock()
 	m.rebuildAPIKeyModelAliasFromRuntimeConfig()
 	_ = m.persist(ctx, auth)
@@ -429,12 +493,18 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 		return nil, nil
 	}
 	m.mu.Lock()
-	if existing, ok := m.auths[auth.ID]; ok && existing != nil && !auth.indexAssigned && auth.Index == "" {
-		auth.Index = existing.Index
-		auth.indexAssigned = existing.indexAssigned
+	if existing, ok := m.auths[auth.ID]; ok && existing != nil {
+		if !auth.indexAssigned && auth.Index == "" {
+			auth.Index = existing.Index
+			auth.indexAssigned = existing.indexAssigned
+		}
+		carryOverProjectState(existing, auth)
 	}
 	auth.EnsureIndex()
-	m.auths[auth.ID] = auth.Clone()
+	stored := auth.Clone()
+	m.restorePendingRuntimeLocked(stored)
+	m.auths[auth.ID] = stored
+	auth = stored.Clone()
 	m.mu.Unlock()
 	m.rebuildAPIKeyModelAliasFromRuntimeConfig()
 	_ = m.persist(ctx, auth)
@@ -461,6 +531,7 @@ func (m *Manager) Load(ctx context.Context) error {
 		auth.EnsureIndex()
 		m.auths[auth.ID] = auth.Clone()
 	}
+	m.loadRuntimeStateLocked(ctx)
 	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
 	if cfg == nil {
 		cfg = &internalconfig.Config{}
@@ -471,12 +542,21 @@ func (m *Manager) Load(ctx context.Context) error {
 
 // Execute performs a non-streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
+// When every credential for the model is exhausted, the configured model fallbacks are tried in order.
 func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
 
+	resp, errExec := m.executeWithRetries(ctx, normalized, req, opts)
+	if errExec == nil {
+		return resp, nil
+	}
+	return executeModelFallbacks(ctx, m, req, opts, errExec, m.executeWithRetries)
+}
+
+func (m *Manager) executeWithRetries(ctx context.Context, normalized []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
 	_, maxWait := m.retrySettings()
 
 	var lastErr error
@@ -533,12 +613,21 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req clip
 
 // ExecuteStream performs a streaming execution using the configured selector and executor.
 // It supports multiple providers for the same model and round-robins the starting provider per model.
+// When every credential for the model is exhausted, the configured model fallbacks are tried in order.
 func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
 	normalized := m.normalizeProviders(providers)
 	if len(normalized) == 0 {
 		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
 
+	chunks, errStream := m.executeStreamWithRetries(ctx, normalized, req, opts)
+	if errStream == nil {
+		return chunks, nil
+	}
+	return executeModelFallbacks(ctx, m, req, opts, errStream, m.executeStreamWithRetries)
+}
+
+func (m *Manager) executeStreamWithRetries(ctx context.Context, normalized []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
 	_, maxWait := m.retrySettings()
 
 	var lastErr error
@@ -568,10 +657,12 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 	}
 	routeModel := req.Model
 	opts = ensureRequestedModelMetadata(opts, routeModel)
+	ctx = withAffinityProbe(ctx)
 	tried := make(map[string]struct{})
 	var lastErr error
+	var quotaFailed *Auth
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, errPick := m.pickNextMixedAfterQuota(ctx, providers, routeModel, opts, tried, quotaFailed)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -583,35 +674,24 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 		debugLogAuthSelection(entry, auth, provider, req.Model)
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+		var out attemptOutcome
+		if delay, hedge := m.hedgeDelay(routeModel); hedge {
+			out = m.executeHedged(ctx, providers, routeModel, req, opts, tried, delay, auth, executor, provider)
+		} else {
+			out = m.executeAttempt(ctx, auth, executor, provider, routeModel, req, opts)
 		}
-		execReq := req
-		execReq.Model = rewriteModelForAuth(routeModel, auth)
-		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
-		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
-		resp, errExec := executor.Execute(execCtx, auth, execReq, opts)
-		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
-		if errExec != nil {
-			if errCtx := execCtx.Err(); errCtx != nil {
+		if out.err != nil {
+			if errCtx := out.ctx.Err(); errCtx != nil {
 				return cliproxyexecutor.Response{}, errCtx
 			}
-			result.Error = &Error{Message: errExec.Error()}
-			var se cliproxyexecutor.StatusError
-			if errors.As(errExec, &se) && se != nil {
-				result.Error.HTTPStatus = se.StatusCode()
-			}
-			if ra := retryAfterFromError(errExec); ra != nil {
-				result.RetryAfter = ra
-			}
-			m.MarkResult(execCtx, result)
-			lastErr = errExec
+			m.MarkResult(out.ctx, out.result)
+			lastErr = out.err
+			quotaFailed = quotaFailedProjectAuth(out.auth, out.result.Error)
 			continue
 		}
-		m.MarkResult(execCtx, result)
-		return resp, nil
+		m.hedgeLatency.observe(routeModel, out.result.Duration)
+		m.MarkResult(out.ctx, out.result)
+		return out.resp, nil
 	}
 }
 
@@ -621,10 +701,12 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 	}
 	routeModel := req.Model
 	opts = ensureRequestedModelMetadata(opts, routeModel)
+	ctx = withAffinityProbe(ctx)
 	tried := make(map[string]struct{})
 	var lastErr error
+	var quotaFailed *Auth
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, errPick := m.pickNextMixedAfterQuota(ctx, providers, routeModel, opts, tried, quotaFailed)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -641,11 +723,15 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
 			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
 		}
+		execCtx = m.withRateReservation(execCtx, auth, estimateRequestTokens(opts.OriginalRequest))
+		execCtx = m.withQuotaReport(execCtx, auth)
 		execReq := req
 		execReq.Model = rewriteModelForAuth(routeModel, auth)
 		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
 		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
+		release := m.beginAttempt(auth, routeModel)
 		resp, errExec := executor.CountTokens(execCtx, auth, execReq, opts)
+		release()
 		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
 		if errExec != nil {
 			if errCtx := execCtx.Err(); errCtx != nil {
@@ -661,6 +747,7 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 			}
 			m.MarkResult(execCtx, result)
 			lastErr = errExec
+			quotaFailed = quotaFailedProjectAuth(auth, result.Error)
 			continue
 		}
 		m.MarkResult(execCtx, result)
@@ -674,10 +761,12 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 	}
 	routeModel := req.Model
 	opts = ensureRequestedModelMetadata(opts, routeModel)
+	ctx = withAffinityProbe(ctx)
 	tried := make(map[string]struct{})
 	var lastErr error
+	var quotaFailed *Auth
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, errPick := m.pickNextMixedAfterQuota(ctx, providers, routeModel, opts, tried, quotaFailed)
 		if errPick != nil {
 			if lastErr != nil {
 				return nil, lastErr
@@ -694,12 +783,17 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
 			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
 		}
+		execCtx = m.withRateReservation(execCtx, auth, estimateRequestTokens(opts.OriginalRequest))
+		execCtx = m.withQuotaReport(execCtx, auth)
 		execReq := req
 		execReq.Model = rewriteModelForAuth(routeModel, auth)
 		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
 		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
+		release := m.beginAttempt(auth, routeModel)
+		started := time.Now()
 		chunks, errStream := executor.ExecuteStream(execCtx, auth, execReq, opts)
 		if errStream != nil {
+			release()
 			if errCtx := execCtx.Err(); errCtx != nil {
 				return nil, errCtx
 			}
@@ -712,14 +806,20 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 			result.RetryAfter = retryAfterFromError(errStream)
 			m.MarkResult(execCtx, result)
 			lastErr = errStream
+			quotaFailed = quotaFailedProjectAuth(auth, rerr)
 			continue
 		}
 		out := make(chan cliproxyexecutor.StreamChunk)
 		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan cliproxyexecutor.StreamChunk) {
 			defer close(out)
+			defer release()
 			var failed bool
+			var firstByte time.Duration
 			forward := true
 			for chunk := range streamChunks {
+				if firstByte == 0 && len(chunk.Payload) > 0 {
+					firstByte = time.Since(started)
+				}
 				if chunk.Err != nil && !failed {
 					failed = true
 					rerr := &Error{Message: chunk.Err.Error()}
@@ -743,7 +843,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 				}
 			}
 			if !failed {
-				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: true})
+				m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: true, Latency: firstByte})
 			}
 		}(execCtx, auth.Clone(), provider, chunks)
 		return out, nil
@@ -1085,7 +1185,7 @@ func (m *Manager) closestCooldownWait(providers []string, model string, attempt
 			continue
 		}
 		blocked, reason, next := isAuthBlockedForModel(auth, model, now)
-		if !blocked || next.IsZero() || reason == blockReasonDisabled {
+		if !blocked || next.IsZero() || reason == blockReasonDisabled || reason == blockReasonInactive {
 			continue
 		}
 		wait := next.Sub(now)
@@ -1136,6 +1236,11 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	if result.AuthID == "" {
 		return
 	}
+	if result.Abandoned {
+		m.breaker.cancelProbe(result.AuthID, result.Model)
+		m.hook.OnResult(ctx, result)
+		return
+	}
 
 	shouldResumeModel := false
 	shouldSuspendModel := false
@@ -1143,6 +1248,12 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	clearModelQuota := false
 	setModelQuota := false
 
+	if result.Success {
+		m.loadStats.observeLatency(result.AuthID, result.Model, result.Latency)
+		m.loadStats.observeDuration(result.AuthID, result.Model, result.Duration)
+	}
+	m.loadStats.observeOutcome(result.AuthID, result.Model, !result.Success)
+
 	m.mu.Lock()
 	if auth, ok := m.auths[result.AuthID]; ok && auth != nil {
 		now := time.Now()
@@ -1151,6 +1262,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 			if result.Model != "" {
 				state := ensureModelState(auth, result.Model)
 				resetModelState(state, now)
+				m.breaker.recordSuccess(auth.ID, result.Model)
 				updateAggregatedAvailability(auth, now)
 				if !hasModelError(auth, now) {
 					auth.LastError = nil
@@ -1162,6 +1274,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				clearModelQuota = true
 			} else {
 				clearAuthStateOnSuccess(auth, now)
+				m.breaker.recordSuccess(auth.ID, "")
 			}
 		} else {
 			if result.Model != "" {
@@ -1177,23 +1290,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				}
 
 				statusCode := statusCodeFromResult(result.Error)
-				switch statusCode {
-				case 401:
-					next := now.Add(30 * time.Minute)
-					state.NextRetryAfter = next
-					suspendReason = "unauthorized"
-					shouldSuspendModel = true
-				case 402, 403:
-					next := now.Add(30 * time.Minute)
-					state.NextRetryAfter = next
-					suspendReason = "payment_required"
-					shouldSuspendModel = true
-				case 404:
-					next := now.Add(12 * time.Hour)
-					state.NextRetryAfter = next
-					suspendReason = "not_found"
-					shouldSuspendModel = true
-				case 429:
+				if statusCode == 429 {
 					var next time.Time
 					backoffLevel := state.Quota.BackoffLevel
 					if result.RetryAfter != nil {
@@ -1215,22 +1312,24 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 					suspendReason = "quota"
 					shouldSuspendModel = true
 					setModelQuota = true
-				case 408, 500, 502, 503, 504:
-					if quotaCooldownDisabledForAuth(auth) {
-						state.NextRetryAfter = time.Time{}
-					} else {
-						next := now.Add(1 * time.Minute)
-						state.NextRetryAfter = next
+					m.breaker.cancelProbe(auth.ID, result.Model)
+				} else if policy, ok := m.circuitPolicyFor(auth.Provider, statusCode); ok && !(policy.transient && quotaCooldownDisabledForAuth(auth)) {
+					// The circuit stays closed until the failure threshold is reached.
+					state.NextRetryAfter = m.breaker.recordFailure(auth.ID, result.Model, policy, now)
+					if !state.NextRetryAfter.IsZero() && policy.reason != "" {
+						suspendReason = policy.reason
+						shouldSuspendModel = true
 					}
-				default:
+				} else {
 					state.NextRetryAfter = time.Time{}
+					m.breaker.cancelProbe(auth.ID, result.Model)
 				}
 
 				auth.Status = StatusError
 				auth.UpdatedAt = now
 				updateAggregatedAvailability(auth, now)
 			} else {
-				applyAuthFailureState(auth, result.Error, result.RetryAfter, now)
+				m.applyAuthFailureState(auth, result.Error, result.RetryAfter, now)
 			}
 		}
 
@@ -1430,7 +1529,9 @@ func statusCodeFromResult(err *Error) int {
 	return err.StatusCode()
 }
 
-func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Duration, now time.Time) {
+// applyAuthFailureState records a failure that is not tied to a model. Quota errors back off like
+// per-model quota errors; other failures go through the circuit breaker policy of their status.
+func (m *Manager) applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Duration, now time.Time) {
 	if auth == nil {
 		return
 	}
@@ -1447,13 +1548,10 @@ func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Durati
 	switch statusCode {
 	case 401:
 		auth.StatusMessage = "unauthorized"
-		auth.NextRetryAfter = now.Add(30 * time.Minute)
 	case 402, 403:
 		auth.StatusMessage = "payment_required"
-		auth.NextRetryAfter = now.Add(30 * time.Minute)
 	case 404:
 		auth.StatusMessage = "not_found"
-		auth.NextRetryAfter = now.Add(12 * time.Hour)
 	case 429:
 		auth.StatusMessage = "quota exhausted"
 		auth.Quota.Exceeded = true
@@ -1470,18 +1568,24 @@ func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Durati
 		}
 		auth.Quota.NextRecoverAt = next
 		auth.NextRetryAfter = next
+		return
 	case 408, 500, 502, 503, 504:
 		auth.StatusMessage = "transient upstream error"
-		if quotaCooldownDisabledForAuth(auth) {
-			auth.NextRetryAfter = time.Time{}
-		} else {
-			auth.NextRetryAfter = now.Add(1 * time.Minute)
-		}
 	default:
 		if auth.StatusMessage == "" {
 			auth.StatusMessage = "request failed"
 		}
 	}
+	policy, ok := m.circuitPolicyFor(auth.Provider, statusCode)
+	if !ok {
+		return
+	}
+	if policy.transient && quotaCooldownDisabledForAuth(auth) {
+		auth.NextRetryAfter = time.Time{}
+		return
+	}
+	// The circuit stays closed until the failure threshold is reached.
+	auth.NextRetryAfter = m.breaker.recordFailure(auth.ID, "", policy, now)
 }
 
 // nextQuotaCooldown returns the next cooldown duration and updated backoff level for repeated quota errors.
@@ -1528,48 +1632,52 @@ func (m *Manager) GetByID(id string) (*Auth, bool) {
 	return auth.Clone(), true
 }
 
+// pickNext selects the next auth for provider and reserves its max-concurrency slot, which the
+// caller releases once the attempt completes. When every remaining candidate is saturated the
+// request waits in the concurrency queue.
 func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
+	type picked struct {
+		auth     *Auth
+		executor ProviderExecutor
+	}
+	out, err := waitForAdmission(ctx, m, func() (picked, error) {
+		auth, executor, errPick := m.pickNextOnce(ctx, provider, model, opts, tried)
+		return picked{auth: auth, executor: executor}, errPick
+	})
+	return out.auth, out.executor, err
+}
+
+func (m *Manager) pickNextOnce(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
 	m.mu.RLock()
 	executor, okExecutor := m.executors[provider]
 	if !okExecutor {
 		m.mu.RUnlock()
 		return nil, nil, &Error{Code: "executor_not_found", Message: "executor not registered"}
 	}
-	candidates := make([]*Auth, 0, len(m.auths))
-	modelKey := strings.TrimSpace(model)
-	// Always use base model name (without thinking suffix) for auth matching.
-	if modelKey != "" {
-		parsed := thinking.ParseSuffix(modelKey)
-		if parsed.ModelName != "" {
-			modelKey = strings.TrimSpace(parsed.ModelName)
-		}
-	}
-	registryRef := registry.GetGlobalRegistry()
+	now := time.Now()
+	filter := m.newCandidateFilter(model, opts, now)
+	var pool candidatePool
 	for _, candidate := range m.auths {
-		if candidate.Provider != provider || candidate.Disabled {
+		if candidate.Provider != provider {
 			continue
 		}
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
-		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, modelKey) {
-			continue
-		}
-		candidates = append(candidates, candidate)
+		reason, wait := filter.skipReason(candidate)
+		pool.add(candidate, reason, wait)
 	}
-	if len(candidates) == 0 {
+	if len(pool.auths) == 0 {
 		m.mu.RUnlock()
-		return nil, nil, &Error{Code: "auth_not_found", Message: "no auth available"}
+		return nil, nil, pool.emptyError(filter.tags)
 	}
-	selected, errPick := m.selector.Pick(ctx, provider, model, opts, candidates)
+	selected, errPick := m.acquirePicked(pool.auths, model, filter.estimate, func(candidates []*Auth) (*Auth, error) {
+		return m.runSelector(ctx, m.selectorFor([]string{provider}, model), provider, model, opts, candidates, now, false)
+	})
 	if errPick != nil {
 		m.mu.RUnlock()
 		return nil, nil, errPick
 	}
-	if selected == nil {
-		m.mu.RUnlock()
-		return nil, nil, &Error{Code: "auth_not_found", Message: "selector returned no auth"}
-	}
 	authCopy := selected.Clone()
 	m.mu.RUnlock()
 	if !selected.indexAssigned {
@@ -1583,7 +1691,26 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 	return authCopy, executor, nil
 }
 
+// pickNextMixed selects the next auth across providers and reserves its max-concurrency slot,
+// which the caller releases once the attempt completes. When every remaining candidate is
+// saturated the request waits in the concurrency queue; when every one is cooling down it may
+// wait in the cooldown queue.
 func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, string, error) {
+	type picked struct {
+		auth     *Auth
+		executor ProviderExecutor
+		provider string
+	}
+	out, err := waitForCooldownRecovery(ctx, m, model, opts, func() (picked, error) {
+		return waitForAdmission(ctx, m, func() (picked, error) {
+			auth, executor, provider, errPick := m.pickNextMixedOnce(ctx, providers, model, opts, tried)
+			return picked{auth: auth, executor: executor, provider: provider}, errPick
+		})
+	})
+	return out.auth, out.executor, out.provider, err
+}
+
+func (m *Manager) pickNextMixedOnce(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, string, error) {
 	providerSet := make(map[string]struct{}, len(providers))
 	for _, provider := range providers {
 		p := strings.TrimSpace(strings.ToLower(provider))
@@ -1597,55 +1724,43 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 	}
 
 	m.mu.RLock()
-	candidates := make([]*Auth, 0, len(m.auths))
-	modelKey := strings.TrimSpace(model)
-	// Always use base model name (without thinking suffix) for auth matching.
-	if modelKey != "" {
-		parsed := thinking.ParseSuffix(modelKey)
-		if parsed.ModelName != "" {
-			modelKey = strings.TrimSpace(parsed.ModelName)
-		}
-	}
-	registryRef := registry.GetGlobalRegistry()
+	now := time.Now()
+	filter := m.newCandidateFilter(model, opts, now)
+	var pool candidatePool
 	for _, candidate := range m.auths {
-		if candidate == nil || candidate.Disabled {
-			continue
-		}
-		providerKey := strings.TrimSpace(strings.ToLower(candidate.Provider))
-		if providerKey == "" {
+		if candidate == nil {
 			continue
 		}
-		if _, ok := providerSet[providerKey]; !ok {
+		if _, ok := providerSet[strings.TrimSpace(strings.ToLower(candidate.Provider))]; !ok {
 			continue
 		}
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
-		if _, ok := m.executors[providerKey]; !ok {
-			continue
-		}
-		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, modelKey) {
-			continue
-		}
-		candidates = append(candidates, candidate)
+		reason, wait := filter.skipReason(candidate)
+		pool.add(candidate, reason, wait)
 	}
-	if len(candidates) == 0 {
+	if len(pool.auths) == 0 {
 		m.mu.RUnlock()
-		return nil, nil, "", &Error{Code: "auth_not_found", Message: "no auth available"}
+		return nil, nil, "", pool.emptyError(filter.tags)
 	}
-	selected, errPick := m.selector.Pick(ctx, "mixed", model, opts, candidates)
+	candidates, weightedProvider := m.weightedProviderCandidates(pool.auths, filter.modelKey, model, now, false)
+	selected, errPick := m.acquirePicked(candidates, model, filter.estimate, func(candidates []*Auth) (*Auth, error) {
+		return m.runSelector(ctx, m.selectorFor(providers, model), "mixed", model, opts, candidates, now, false)
+	})
 	if errPick != nil {
 		m.mu.RUnlock()
 		return nil, nil, "", errPick
 	}
-	if selected == nil {
-		m.mu.RUnlock()
-		return nil, nil, "", &Error{Code: "auth_not_found", Message: "selector returned no auth"}
-	}
 	providerKey := strings.TrimSpace(strings.ToLower(selected.Provider))
+	if weightedProvider != "" {
+		m.providerBalancer.record(strings.ToLower(filter.modelKey), providerKey)
+	}
 	executor, okExecutor := m.executors[providerKey]
 	if !okExecutor {
 		m.mu.RUnlock()
+		m.concurrency.release(selected)
+		m.breaker.cancelProbe(selected.ID, model)
 		return nil, nil, "", &Error{Code: "executor_not_found", Message: "executor not registered"}
 	}
 	authCopy := selected.Clone()
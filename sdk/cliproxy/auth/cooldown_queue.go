@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+const (
+	// defaultCooldownQueueSize bounds waiting requests per model when routing.cooldown-queue.size is unset.
+	defaultCooldownQueueSize = 64
+	// defaultCooldownQueueMaxWait bounds the wait when routing.cooldown-queue.max-wait is unset.
+	defaultCooldownQueueMaxWait = 60 * time.Second
+	// cooldownQueuePoll is the shortest pause between two picks of the queue head.
+	cooldownQueuePoll = 100 * time.Millisecond
+)
+
+// CooldownQueueStats reports the state of the cooldown admission queue since startup.
+type CooldownQueueStats struct {
+	// Depth is the number of requests waiting right now.
+	Depth int `json:"depth"`
+	// DepthByModel splits Depth per model.
+	DepthByModel map[string]int `json:"depth_by_model"`
+	// Admitted counts waiting requests that got a credential.
+	Admitted int64 `json:"admitted"`
+	// Expired counts waiting requests that gave up on timeout, disconnect or another error.
+	Expired int64 `json:"expired"`
+	// Rejected counts requests turned away because the queue of their model was full.
+	Rejected int64 `json:"rejected"`
+	// AverageWaitMs is the mean time spent in the queue by requests that left it.
+	AverageWaitMs float64 `json:"average_wait_ms"`
+	// MaxWaitMs is the longest time spent in the queue.
+	MaxWaitMs int64 `json:"max_wait_ms"`
+}
+
+// cooldownWaiter is one request waiting in a model queue. turn is closed while the waiter is
+// at the head of its queue.
+type cooldownWaiter struct {
+	rank int
+	seq  uint64
+	turn chan struct{}
+}
+
+// cooldownQueue orders requests waiting for a cooling credential per model, by priority rank and
+// then arrival. Only the head of a model queue picks, so waiters are served in order.
+type cooldownQueue struct {
+	mu     sync.Mutex
+	seq    uint64
+	models map[string][]*cooldownWaiter
+
+	admitted  int64
+	expired   int64
+	rejected  int64
+	waitTotal time.Duration
+	waitMax   time.Duration
+}
+
+func newCooldownQueue() *cooldownQueue {
+	return &cooldownQueue{models: make(map[string][]*cooldownWaiter)}
+}
+
+// enqueue adds a waiter for model unless size requests already wait for it.
+func (q *cooldownQueue) enqueue(model string, rank, size int) (*cooldownWaiter, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	waiters := q.models[model]
+	if len(waiters) >= size {
+		q.rejected++
+		return nil, false
+	}
+	q.seq++
+	w := &cooldownWaiter{rank: rank, seq: q.seq, turn: make(chan struct{})}
+	idx := sort.Search(len(waiters), func(i int) bool { return waiters[i].rank > rank })
+	waiters = append(waiters, nil)
+	copy(waiters[idx+1:], waiters[idx:])
+	waiters[idx] = w
+	if idx == 0 {
+		if len(waiters) > 1 {
+			// The previous head lost its turn to a higher priority request.
+			waiters[1].turn = make(chan struct{})
+		}
+		close(w.turn)
+	}
+	q.models[model] = waiters
+	return w, true
+}
+
+// turn returns the channel closed once w reaches the head of its queue.
+func (q *cooldownQueue) turn(w *cooldownWaiter) <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return w.turn
+}
+
+// leave removes w from the queue of model, hands the turn to the next waiter and records how
+// long w waited.
+func (q *cooldownQueue) leave(model string, w *cooldownWaiter, waited time.Duration, admitted bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	waiters := q.models[model]
+	for i, candidate := range waiters {
+		if candidate != w {
+			continue
+		}
+		waiters = append(waiters[:i], waiters[i+1:]...)
+		if i == 0 && len(waiters) > 0 {
+			close(waiters[0].turn)
+		}
+		break
+	}
+	if len(waiters) == 0 {
+		delete(q.models, model)
+	} else {
+		q.models[model] = waiters
+	}
+	if admitted {
+		q.admitted++
+	} else {
+		q.expired++
+	}
+	q.waitTotal += waited
+	if waited > q.waitMax {
+		q.waitMax = waited
+	}
+}
+
+func (q *cooldownQueue) stats() CooldownQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := CooldownQueueStats{
+		DepthByModel: make(map[string]int, len(q.models)),
+		Admitted:     q.admitted,
+		Expired:      q.expired,
+		Rejected:     q.rejected,
+		MaxWaitMs:    q.waitMax.Milliseconds(),
+	}
+	for model, waiters := range q.models {
+		stats.DepthByModel[model] = len(waiters)
+		stats.Depth += len(waiters)
+	}
+	if left := q.admitted + q.expired; left > 0 {
+		stats.AverageWaitMs = float64(q.waitTotal.Milliseconds()) / float64(left)
+	}
+	return stats
+}
+
+// CooldownQueueStats returns the depth and wait statistics of the cooldown admission queue.
+func (m *Manager) CooldownQueueStats() CooldownQueueStats {
+	if m == nil || m.cooldownQueue == nil {
+		return CooldownQueueStats{DepthByModel: map[string]int{}}
+	}
+	return m.cooldownQueue.stats()
+}
+
+// cooldownQueueSettings returns whether the cooldown queue is enabled, its size, the longest
+// wait of the request's client key and the priority rank of the request (lower is served first).
+func (m *Manager) cooldownQueueSettings(opts cliproxyexecutor.Options) (bool, int, time.Duration, int) {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || !cfg.Routing.CooldownQueue.Enabled {
+		return false, 0, 0, 0
+	}
+	queue := cfg.Routing.CooldownQueue
+	size, maxWait := defaultCooldownQueueSize, defaultCooldownQueueMaxWait
+	if queue.Size > 0 {
+		size = queue.Size
+	}
+	if queue.MaxWait > 0 {
+		maxWait = time.Duration(queue.MaxWait) * time.Second
+	}
+	if key := metadataString(opts.Metadata, cliproxyexecutor.ClientAPIKeyMetadataKey); key != "" {
+		if seconds := queue.KeyMaxWait[key]; seconds > 0 {
+			maxWait = time.Duration(seconds) * time.Second
+		}
+	}
+	class := strings.ToLower(metadataString(opts.Metadata, cliproxyexecutor.PriorityClassMetadataKey))
+	rank, defaultRank := len(queue.PriorityClasses), len(queue.PriorityClasses)
+	for i, candidate := range queue.PriorityClasses {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if candidate == class {
+			rank = i
+		}
+		if candidate == "default" {
+			defaultRank = i
+		}
+	}
+	if rank == len(queue.PriorityClasses) {
+		rank = defaultRank
+	}
+	return true, size, maxWait, rank
+}
+
+func metadataString(meta map[string]any, key string) string {
+	if meta == nil {
+		return ""
+	}
+	value, _ := meta[key].(string)
+	return strings.TrimSpace(value)
+}
+
+// cooldownReset reports whether err means every credential for the model is cooling down, and
+// how long until the earliest one recovers.
+func cooldownReset(err error) (time.Duration, bool) {
+	var cooldownErr *modelCooldownError
+	if errors.As(err, &cooldownErr) && cooldownErr != nil {
+		return cooldownErr.resetIn, true
+	}
+	return 0, false
+}
+
+// waitForCooldownRecovery runs pick and, when it fails because every credential for model is
+// cooling down and the cooldown queue is enabled, waits in the model queue until the earliest
+// credential recovers. Requests whose recovery lies beyond their max wait fail immediately;
+// waiting ends on success, another error, the max wait, or ctx being done.
+func waitForCooldownRecovery[T any](ctx context.Context, m *Manager, model string, opts cliproxyexecutor.Options, pick func() (T, error)) (T, error) {
+	var zero T
+	out, err := pick()
+	resetIn, cooling := cooldownReset(err)
+	if !cooling {
+		return out, err
+	}
+	enabled, size, maxWait, rank := m.cooldownQueueSettings(opts)
+	if !enabled || resetIn > maxWait {
+		return out, err
+	}
+	waiter, ok := m.cooldownQueue.enqueue(model, rank, size)
+	if !ok {
+		return out, err
+	}
+	start := time.Now()
+	admitted := false
+	defer func() { m.cooldownQueue.leave(model, waiter, time.Since(start), admitted) }()
+
+	deadline := time.NewTimer(maxWait)
+	defer deadline.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-deadline.C:
+			return zero, err
+		case <-m.cooldownQueue.turn(waiter):
+		}
+		if resetIn < cooldownQueuePoll {
+			resetIn = cooldownQueuePoll
+		}
+		recovery := time.NewTimer(resetIn)
+		select {
+		case <-ctx.Done():
+			recovery.Stop()
+			return zero, ctx.Err()
+		case <-deadline.C:
+			recovery.Stop()
+			return zero, err
+		case <-recovery.C:
+		}
+		out, err = pick()
+		if resetIn, cooling = cooldownReset(err); !cooling {
+			admitted = err == nil
+			return out, err
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestManager_CooldownQueueWaitsForRecovery(t *testing.T) {
+	const model = "cooldown-queue-model"
+
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	executor := &recordingExecutor{provider: "claude"}
+	m.RegisterExecutor(executor)
+	recoverAt := time.Now().Add(300 * time.Millisecond)
+	auth := &Auth{ID: "cq-cooling", Provider: "claude", Status: StatusActive, ModelStates: map[string]*ModelState{
+		model: {Status: StatusError, Unavailable: true, NextRetryAfter: recoverAt, Quota: QuotaState{Exceeded: true, NextRecoverAt: recoverAt}},
+	}}
+	if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+		t.Fatalf("register auth: %v", errRegister)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, "claude", []*registry.ModelInfo{{ID: model}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	if _, errExec := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{}); errExec == nil {
+		t.Fatalf("expected cooldown error with the queue disabled")
+	}
+
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{CooldownQueue: internalconfig.CooldownQueueConfig{
+		Enabled: true,
+		MaxWait: 1,
+	}}})
+	if _, errExec := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{}); errExec != nil {
+		t.Fatalf("Execute() with cooldown queue error = %v", errExec)
+	}
+	if time.Now().Before(recoverAt) {
+		t.Fatalf("request served before the credential recovered")
+	}
+	stats := m.CooldownQueueStats()
+	if stats.Admitted != 1 || stats.Depth != 0 || stats.MaxWaitMs <= 0 {
+		t.Fatalf("CooldownQueueStats() = %+v, want one admitted request", stats)
+	}
+}
+
+func TestCooldownQueue_PriorityThenFIFO(t *testing.T) {
+	q := newCooldownQueue()
+	first, _ := q.enqueue("m", 1, 10)
+	second, _ := q.enqueue("m", 1, 10)
+	urgent, _ := q.enqueue("m", 0, 10)
+	if _, ok := q.enqueue("m", 1, 3); ok {
+		t.Fatalf("expected a full queue to reject the request")
+	}
+
+	isHead := func(w *cooldownWaiter) bool {
+		select {
+		case <-q.turn(w):
+			return true
+		default:
+			return false
+		}
+	}
+	if !isHead(urgent) || isHead(first) || isHead(second) {
+		t.Fatalf("expected the higher priority request to take the head")
+	}
+	q.leave("m", urgent, time.Millisecond, true)
+	if !isHead(first) || isHead(second) {
+		t.Fatalf("expected the earliest request of the next class to take the head")
+	}
+	q.leave("m", first, time.Millisecond, false)
+	if !isHead(second) {
+		t.Fatalf("expected the last request to take the head")
+	}
+	if stats := q.stats(); stats.Depth != 1 || stats.Admitted != 1 || stats.Expired != 1 || stats.Rejected != 1 {
+		t.Fatalf("stats() = %+v", stats)
+	}
+}
+
+func TestManager_CooldownQueueSettings(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{CooldownQueue: internalconfig.CooldownQueueConfig{
+		Enabled:         true,
+		KeyMaxWait:      map[string]int{"batch-key": 300},
+		PriorityClasses: []string{"interactive", "default", "batch"},
+	}}})
+
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{
+		cliproxyexecutor.ClientAPIKeyMetadataKey:  "batch-key",
+		cliproxyexecutor.PriorityClassMetadataKey: "Batch",
+	}}
+	enabled, size, maxWait, rank := m.cooldownQueueSettings(opts)
+	if !enabled || size != defaultCooldownQueueSize || maxWait != 300*time.Second || rank != 2 {
+		t.Fatalf("cooldownQueueSettings() = %t, %d, %v, %d", enabled, size, maxWait, rank)
+	}
+	if _, _, maxWait, rank = m.cooldownQueueSettings(cliproxyexecutor.Options{}); maxWait != defaultCooldownQueueMaxWait || rank != 1 {
+		t.Fatalf("cooldownQueueSettings(no metadata) maxWait = %v, rank = %d; want default wait and the default class", maxWait, rank)
+	}
+}
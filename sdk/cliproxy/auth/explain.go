@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// RoutingExplanation describes how the manager would route a request, without executing it.
+type RoutingExplanation struct {
+	// Model is the route model the candidates were evaluated for.
+	Model string `json:"model"`
+	// Providers lists the providers the model resolves to.
+	Providers []string `json:"providers"`
+	// RouteTags lists the credential tags the request requires.
+	RouteTags []string `json:"route_tags,omitempty"`
+	// WeightedProvider is the provider routing.provider-weights chooses for the request; empty
+	// when no weights apply.
+	WeightedProvider string `json:"weighted_provider,omitempty"`
+	// SpilloverFloor is the lowest priority group routing.spillover opens to the selector; absent
+	// when only the top group is used.
+	SpilloverFloor *int `json:"spillover_floor,omitempty"`
+	// Candidates lists every credential of those providers, eligible or not.
+	Candidates []RoutingCandidate `json:"candidates"`
+	// Selected is the ID of the credential the selector for the model would pick among the eligible
+	// ones, determined with SelectorPeeker.
+	Selected string `json:"selected,omitempty"`
+	// Error explains why no credential would be picked.
+	Error string `json:"error,omitempty"`
+}
+
+// RoutingCandidate reports the routing state of one credential.
+type RoutingCandidate struct {
+	AuthID    string `json:"auth_id"`
+	AuthIndex string `json:"auth_index,omitempty"`
+	Provider  string `json:"provider"`
+	Label     string `json:"label,omitempty"`
+	Priority  int    `json:"priority"`
+	Weight    int    `json:"weight"`
+	// Tags are the credential tags requests can require with X-Route-Tags or routing.key-tags.
+	Tags []string `json:"tags,omitempty"`
+	// UpstreamModel is the model sent upstream after prefix stripping and alias rewrites.
+	UpstreamModel string `json:"upstream_model"`
+	// Eligible reports whether the credential is passed to the selector.
+	Eligible bool `json:"eligible"`
+	// Reason explains why the credential is skipped or blocked; empty when it can be picked.
+	Reason string `json:"reason,omitempty"`
+	// NextRetryAfter is when a blocked model becomes available again.
+	NextRetryAfter *time.Time   `json:"next_retry_after,omitempty"`
+	Circuit        CircuitState `json:"circuit"`
+	InFlight       int          `json:"in_flight"`
+}
+
+// ExplainRouting evaluates every credential of providers for model the way request dispatch does
+// and reports which one the selector would pick. Candidates are filtered, narrowed by provider
+// weights and spillover with the same code as dispatch. Nothing is reserved or executed, and the
+// selector is only peeked, so round-robin cursors, sticky pins and affinity stay untouched.
+func (m *Manager) ExplainRouting(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options) RoutingExplanation {
+	explanation := RoutingExplanation{Model: model, Providers: providers, Candidates: []RoutingCandidate{}}
+	providerSet := make(map[string]struct{}, len(providers))
+	for _, provider := range providers {
+		if p := strings.TrimSpace(strings.ToLower(provider)); p != "" {
+			providerSet[p] = struct{}{}
+		}
+	}
+	now := time.Now()
+
+	m.mu.RLock()
+	filter := m.newCandidateFilter(model, opts, now)
+	explanation.RouteTags = filter.tags
+	eligible := make([]*Auth, 0, len(m.auths))
+	eligibleIndex := make(map[string]int, len(m.auths))
+	for _, auth := range m.auths {
+		if auth == nil {
+			continue
+		}
+		providerKey := strings.TrimSpace(strings.ToLower(auth.Provider))
+		if _, ok := providerSet[providerKey]; !ok {
+			continue
+		}
+		candidate := RoutingCandidate{
+			AuthID:    auth.ID,
+			AuthIndex: auth.Index,
+			Provider:  providerKey,
+			Label:     auth.Label,
+			Priority:  authPriority(auth),
+			Weight:    weightOf(auth),
+			Tags:      authTags(auth),
+			Circuit:   m.breaker.state(auth.ID, model, now),
+			InFlight:  m.loadStats.InFlight(auth.ID, model),
+		}
+		candidate.UpstreamModel = m.applyAPIKeyModelAlias(auth, m.applyOAuthModelAlias(auth, rewriteModelForAuth(model, auth)))
+		candidate.Reason, _ = filter.skipReason(auth)
+		if candidate.Reason == "" {
+			if blocked, reason, next := isAuthBlockedForModel(auth, model, now); blocked {
+				switch reason {
+				case blockReasonCooldown:
+					candidate.Reason = "quota_cooldown"
+				case blockReasonDisabled:
+					candidate.Reason = "disabled"
+				case blockReasonInactive:
+					candidate.Reason = "outside_active_windows"
+					if auth.ActiveWindowsError() != nil {
+						candidate.Reason = "invalid_active_windows"
+					}
+				default:
+					candidate.Reason = "unavailable"
+					if candidate.Circuit == CircuitOpen {
+						candidate.Reason = "circuit_open"
+					}
+				}
+				if !next.IsZero() {
+					candidate.NextRetryAfter = &next
+				}
+			}
+			candidate.Eligible = true
+			eligible = append(eligible, auth)
+			eligibleIndex[auth.ID] = len(explanation.Candidates)
+		}
+		explanation.Candidates = append(explanation.Candidates, candidate)
+	}
+	eligible, explanation.WeightedProvider = m.weightedProviderCandidates(eligible, filter.modelKey, model, now, true)
+	if explanation.WeightedProvider != "" {
+		// Credentials of the providers the weights did not choose are not passed to the selector.
+		for _, index := range eligibleIndex {
+			if candidate := &explanation.Candidates[index]; candidate.Provider != explanation.WeightedProvider {
+				candidate.Eligible = false
+				candidate.Reason = "provider_weight"
+				candidate.NextRetryAfter = nil
+			}
+		}
+	}
+	if len(eligible) == 0 {
+		explanation.Error = "no auth available"
+	} else {
+		selectorCtx := m.spilloverContext(ctx, eligible, model, now)
+		if floor, ok := prioritySpilloverFloor(selectorCtx); ok {
+			explanation.SpilloverFloor = &floor
+		}
+		// Available credentials outside the priority groups selectors draw from cannot be picked.
+		if reachable, errAvailable := getAvailableAuths(selectorCtx, eligible, "mixed", model, now); errAvailable == nil {
+			inReach := make(map[string]struct{}, len(reachable))
+			for _, auth := range reachable {
+				inReach[auth.ID] = struct{}{}
+			}
+			for _, auth := range eligible {
+				candidate := &explanation.Candidates[eligibleIndex[auth.ID]]
+				if _, ok := inReach[auth.ID]; !ok && candidate.Reason == "" {
+					candidate.Reason = "lower_priority"
+				}
+			}
+		}
+		if selected, errPick := m.runSelector(ctx, m.selectorFor(providers, model), "mixed", model, opts, eligible, now, true); errPick != nil {
+			explanation.Error = errPick.Error()
+		} else if selected != nil {
+			explanation.Selected = selected.ID
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(explanation.Candidates, func(i, j int) bool {
+		left, right := explanation.Candidates[i], explanation.Candidates[j]
+		if left.Priority != right.Priority {
+			return left.Priority > right.Priority
+		}
+		return left.AuthID < right.AuthID
+	})
+	return explanation
+}
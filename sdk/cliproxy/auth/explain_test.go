@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestManager_ExplainRouting(t *testing.T) {
+	const model = "explain-test-model"
+
+	m := NewManager(nil, &FillFirstSelector{}, nil)
+	m.RegisterExecutor(&recordingExecutor{provider: "claude"})
+	auths := []*Auth{
+		{ID: "explain-a-cooling", Provider: "claude", Status: StatusActive},
+		{ID: "explain-b-ready", Provider: "claude", Status: StatusActive, Attributes: map[string]string{"priority": "1", "weight": "3"}},
+		{ID: "explain-c-disabled", Provider: "claude", Status: StatusDisabled, Disabled: true},
+		{ID: "explain-d-other-model", Provider: "claude", Status: StatusActive},
+	}
+	for _, auth := range auths {
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("register auth %s: %v", auth.ID, errRegister)
+		}
+		models := []*registry.ModelInfo{{ID: model}}
+		if auth.ID == "explain-d-other-model" {
+			models = []*registry.ModelInfo{{ID: "other-model"}}
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, "claude", models)
+		id := auth.ID
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+	m.MarkResult(context.Background(), Result{AuthID: "explain-a-cooling", Provider: "claude", Model: model, Error: &Error{Message: "boom", HTTPStatus: 503}})
+
+	explanation := m.ExplainRouting(context.Background(), []string{"claude"}, model, cliproxyexecutor.Options{})
+	if explanation.Selected != "explain-b-ready" {
+		t.Fatalf("selected = %q, want explain-b-ready (error %q)", explanation.Selected, explanation.Error)
+	}
+	if len(explanation.Candidates) != len(auths) {
+		t.Fatalf("candidates = %d, want %d", len(explanation.Candidates), len(auths))
+	}
+	byID := make(map[string]RoutingCandidate)
+	for _, candidate := range explanation.Candidates {
+		byID[candidate.AuthID] = candidate
+	}
+	if first := explanation.Candidates[0]; first.AuthID != "explain-b-ready" || first.Priority != 1 || first.Weight != 3 {
+		t.Fatalf("first candidate = %+v, want highest priority explain-b-ready with weight 3", first)
+	}
+	cooling := byID["explain-a-cooling"]
+	if cooling.Reason != "circuit_open" || cooling.NextRetryAfter == nil || !cooling.NextRetryAfter.After(time.Now()) {
+		t.Fatalf("cooling candidate = %+v, want circuit_open with a future retry", cooling)
+	}
+	if reason := byID["explain-c-disabled"].Reason; reason != "disabled" {
+		t.Fatalf("disabled candidate reason = %q", reason)
+	}
+	if candidate := byID["explain-d-other-model"]; candidate.Eligible || candidate.Reason != "model_not_supported" {
+		t.Fatalf("other-model candidate = %+v, want model_not_supported", candidate)
+	}
+}
+
+func TestManager_ExplainRouting_LeavesSelectorStateUntouched(t *testing.T) {
+	const model = "explain-peek-model"
+
+	selector := NewStickySelector(time.Minute, &RoundRobinSelector{})
+	m := NewManager(nil, selector, nil)
+	m.RegisterExecutor(&recordingExecutor{provider: "claude"})
+	for _, id := range []string{"explain-peek-a", "explain-peek-b"} {
+		if _, errRegister := m.Register(context.Background(), &Auth{ID: id, Provider: "claude", Status: StatusActive}); errRegister != nil {
+			t.Fatalf("register auth %s: %v", id, errRegister)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "claude", []*registry.ModelInfo{{ID: model}})
+		authID := id
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(authID) })
+	}
+	plain := cliproxyexecutor.Options{}
+	conversation := cliproxyexecutor.Options{OriginalRequest: []byte(`{"metadata":{"user_id":"explain-peek"},"messages":[{"role":"user","content":"hi"}]}`)}
+
+	for i := 0; i < 3; i++ {
+		if selected := m.ExplainRouting(context.Background(), []string{"claude"}, model, plain).Selected; selected != "explain-peek-a" {
+			t.Fatalf("explain %d selected %q, want the round-robin cursor to stay on explain-peek-a", i, selected)
+		}
+	}
+	if selected := m.ExplainRouting(context.Background(), []string{"claude"}, model, conversation).Selected; selected != "explain-peek-a" {
+		t.Fatalf("explain for a conversation selected %q, want explain-peek-a", selected)
+	}
+	if len(selector.pins) != 0 {
+		t.Fatalf("explain pinned conversations: %v", selector.pins)
+	}
+
+	auth, _, _, errPick := m.pickNextMixedOnce(context.Background(), []string{"claude"}, model, plain, map[string]struct{}{})
+	if errPick != nil {
+		t.Fatalf("pick: %v", errPick)
+	}
+	if auth.ID != "explain-peek-a" {
+		t.Fatalf("first real pick = %q, want explain-peek-a", auth.ID)
+	}
+	if selected := m.ExplainRouting(context.Background(), []string{"claude"}, model, plain).Selected; selected != "explain-peek-b" {
+		t.Fatalf("explain after a real pick selected %q, want explain-peek-b", selected)
+	}
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// geminiVirtualParent returns the primary auth ID of a per-project Gemini CLI virtual auth.
+// Virtual auths are synthesized for credentials that list several project IDs; each one
+// carries its own ModelStates, so a cooled project stays blocked until it recovers.
+func geminiVirtualParent(auth *Auth) string {
+	if auth == nil || auth.Attributes == nil {
+		return ""
+	}
+	return strings.TrimSpace(auth.Attributes["gemini_virtual_parent"])
+}
+
+func (m *Manager) switchProjectEnabled() bool {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	return cfg != nil && cfg.QuotaExceeded.SwitchProject
+}
+
+// quotaFailedProjectAuth returns auth when it is a Gemini CLI project that just failed with 429.
+func quotaFailedProjectAuth(auth *Auth, err *Error) *Auth {
+	if statusCodeFromResult(err) != http.StatusTooManyRequests || geminiVirtualParent(auth) == "" {
+		return nil
+	}
+	return auth
+}
+
+// pickNextMixedAfterQuota picks the next candidate after quotaFailed (if any) hit a 429.
+// With quota-exceeded.switch-project enabled, another project of the same Google account is
+// preferred; with it disabled, the remaining projects of that account are skipped for this request.
+func (m *Manager) pickNextMixedAfterQuota(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}, quotaFailed *Auth) (*Auth, ProviderExecutor, string, error) {
+	parent := geminiVirtualParent(quotaFailed)
+	if parent == "" {
+		return m.pickNextMixed(ctx, providers, model, opts, tried)
+	}
+	siblings := m.projectSiblingIDs(parent)
+	if !m.switchProjectEnabled() {
+		for _, id := range siblings {
+			tried[id] = struct{}{}
+		}
+		return m.pickNextMixed(ctx, providers, model, opts, tried)
+	}
+
+	restricted := make(map[string]struct{}, len(tried))
+	for id := range tried {
+		restricted[id] = struct{}{}
+	}
+	siblingSet := make(map[string]struct{}, len(siblings))
+	for _, id := range siblings {
+		siblingSet[id] = struct{}{}
+	}
+	m.mu.RLock()
+	for id := range m.auths {
+		if _, ok := siblingSet[id]; !ok {
+			restricted[id] = struct{}{}
+		}
+	}
+	m.mu.RUnlock()
+	// Saturated siblings do not wait in the concurrency queue; other accounts are tried instead.
+	if auth, executor, provider, errPick := m.pickNextMixedOnce(ctx, providers, model, opts, restricted); errPick == nil {
+		logEntryWithRequestID(ctx).Debugf("switch project: %s -> %s", quotaFailed.ID, auth.ID)
+		return auth, executor, provider, nil
+	}
+	return m.pickNextMixed(ctx, providers, model, opts, tried)
+}
+
+func (m *Manager) projectSiblingIDs(parent string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var ids []string
+	for id, auth := range m.auths {
+		if geminiVirtualParent(auth) == parent {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// carryOverProjectState keeps the per-project cooldown state of a Gemini CLI virtual auth when it is
+// re-synthesized (for example after the shared token is refreshed) so a cooled project is not
+// picked again before it recovers.
+func carryOverProjectState(existing, incoming *Auth) {
+	if existing == nil || incoming == nil || geminiVirtualParent(incoming) == "" {
+		return
+	}
+	if len(incoming.ModelStates) > 0 || len(existing.ModelStates) == 0 {
+		return
+	}
+	incoming.ModelStates = make(map[string]*ModelState, len(existing.ModelStates))
+	for model, state := range existing.ModelStates {
+		if state != nil {
+			incoming.ModelStates[model] = state.Clone()
+		}
+	}
+	if !incoming.Quota.Exceeded {
+		incoming.Quota = existing.Quota
+	}
+	if incoming.NextRetryAfter.IsZero() {
+		incoming.NextRetryAfter = existing.NextRetryAfter
+	}
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func newSwitchProjectManager(t *testing.T, switchProject bool) (*Manager, *recordingExecutor) {
+	t.Helper()
+	const model = "switch-project-test-model"
+
+	executor := &recordingExecutor{
+		provider:  "gemini-cli",
+		failAuths: map[string]error{"1-acct-a-p1": &Error{HTTPStatus: http.StatusTooManyRequests, Message: "quota"}},
+	}
+	m := NewManager(nil, &FillFirstSelector{}, nil)
+	m.RegisterExecutor(executor)
+	m.SetConfig(&internalconfig.Config{QuotaExceeded: internalconfig.QuotaExceeded{SwitchProject: switchProject}})
+
+	auths := []*Auth{
+		{ID: "1-acct-a-p1", Provider: "gemini-cli", Status: StatusActive, Attributes: map[string]string{"gemini_virtual_parent": "acct-a", "gemini_virtual_project": "p1"}},
+		{ID: "2-acct-b", Provider: "gemini-cli", Status: StatusActive},
+		{ID: "3-acct-a-p2", Provider: "gemini-cli", Status: StatusActive, Attributes: map[string]string{"gemini_virtual_parent": "acct-a", "gemini_virtual_project": "p2"}},
+	}
+	for _, a := range auths {
+		if _, errRegister := m.Register(context.Background(), a); errRegister != nil {
+			t.Fatalf("register auth %s: %v", a.ID, errRegister)
+		}
+		registry.GetGlobalRegistry().RegisterClient(a.ID, "gemini-cli", []*registry.ModelInfo{{ID: model}})
+	}
+	t.Cleanup(func() {
+		for _, a := range auths {
+			registry.GetGlobalRegistry().UnregisterClient(a.ID)
+		}
+	})
+
+	if _, errExec := m.Execute(context.Background(), []string{"gemini-cli"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{}); errExec != nil {
+		t.Fatalf("Execute() error = %v", errExec)
+	}
+	return m, executor
+}
+
+func TestManager_SwitchProject_PrefersSiblingProjectOnQuota(t *testing.T) {
+	m, executor := newSwitchProjectManager(t, true)
+
+	got := executor.AuthCalls()
+	if len(got) != 2 || got[0] != "1-acct-a-p1" || got[1] != "3-acct-a-p2" {
+		t.Fatalf("auth calls = %v, want [1-acct-a-p1 3-acct-a-p2]", got)
+	}
+	cooled, _ := m.GetByID("1-acct-a-p1")
+	state := cooled.ModelStates["switch-project-test-model"]
+	if state == nil || !state.Unavailable || state.NextRetryAfter.IsZero() {
+		t.Fatalf("expected cooled project state to be tracked, got %+v", state)
+	}
+}
+
+func TestManager_SwitchProject_DisabledSkipsSiblingProjects(t *testing.T) {
+	_, executor := newSwitchProjectManager(t, false)
+
+	got := executor.AuthCalls()
+	if len(got) != 2 || got[0] != "1-acct-a-p1" || got[1] != "2-acct-b" {
+		t.Fatalf("auth calls = %v, want [1-acct-a-p1 2-acct-b]", got)
+	}
+}
+
+func TestManager_Update_KeepsVirtualProjectCooldown(t *testing.T) {
+	m, _ := newSwitchProjectManager(t, true)
+
+	resynthesized := &Auth{ID: "1-acct-a-p1", Provider: "gemini-cli", Status: StatusActive, Attributes: map[string]string{"gemini_virtual_parent": "acct-a", "gemini_virtual_project": "p1"}}
+	if _, errUpdate := m.Update(context.Background(), resynthesized); errUpdate != nil {
+		t.Fatalf("update auth: %v", errUpdate)
+	}
+	updated, _ := m.GetByID("1-acct-a-p1")
+	if state := updated.ModelStates["switch-project-test-model"]; state == nil || !state.Unavailable {
+		t.Fatalf("expected project cooldown to survive re-synthesis, got %+v", state)
+	}
+}
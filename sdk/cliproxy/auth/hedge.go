@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+const (
+	// hedgeLatencyWindow is the number of recent latencies kept per model for the learned delay.
+	hedgeLatencyWindow = 128
+	// hedgeMinSamples is the number of latencies required before the learned delay is used.
+	hedgeMinSamples = 20
+)
+
+// latencyWindow keeps the most recent non-streaming latencies of each model. Attempts that lose a
+// hedge contribute the time they ran before being cancelled, so slow credentials still pull the
+// percentile up.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples map[string]*latencyRing
+}
+
+type latencyRing struct {
+	values []time.Duration
+	next   int
+}
+
+func newLatencyWindow() *latencyWindow {
+	return &latencyWindow{samples: make(map[string]*latencyRing)}
+}
+
+func (w *latencyWindow) observe(model string, latency time.Duration) {
+	if latency <= 0 {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ring := w.samples[model]
+	if ring == nil {
+		ring = &latencyRing{values: make([]time.Duration, 0, hedgeLatencyWindow)}
+		w.samples[model] = ring
+	}
+	if len(ring.values) < hedgeLatencyWindow {
+		ring.values = append(ring.values, latency)
+		return
+	}
+	ring.values[ring.next] = latency
+	ring.next = (ring.next + 1) % hedgeLatencyWindow
+}
+
+// p95 returns the 95th percentile latency of model once hedgeMinSamples were observed.
+func (w *latencyWindow) p95(model string) (time.Duration, bool) {
+	w.mu.Lock()
+	ring := w.samples[model]
+	if ring == nil || len(ring.values) < hedgeMinSamples {
+		w.mu.Unlock()
+		return 0, false
+	}
+	values := append([]time.Duration(nil), ring.values...)
+	w.mu.Unlock()
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values[(len(values)*95+99)/100-1], true
+}
+
+// hedgeDelay returns how long the first attempt of a non-streaming request for model may run
+// before a hedge is dispatched, and false when hedging does not apply.
+func (m *Manager) hedgeDelay(model string) (time.Duration, bool) {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || !cfg.Routing.Hedge.Enabled {
+		return 0, false
+	}
+	if cfg.Routing.Hedge.Delay > 0 {
+		return time.Duration(cfg.Routing.Hedge.Delay) * time.Millisecond, true
+	}
+	return m.hedgeLatency.p95(model)
+}
+
+// attemptOutcome is the result of one dispatched execution, before MarkResult.
+type attemptOutcome struct {
+	ctx    context.Context
+	auth   *Auth
+	resp   cliproxyexecutor.Response
+	result Result
+	err    error
+}
+
+// executeAttempt dispatches req to auth and reports the outcome without marking it.
+func (m *Manager) executeAttempt(ctx context.Context, auth *Auth, executor ProviderExecutor, provider, routeModel string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) attemptOutcome {
+	execCtx := ctx
+	if rt := m.roundTripperFor(auth); rt != nil {
+		execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
+		execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
+	}
+	execCtx = m.withRateReservation(execCtx, auth, estimateRequestTokens(opts.OriginalRequest))
+	execCtx = m.withQuotaReport(execCtx, auth)
+	execReq := req
+	execReq.Model = rewriteModelForAuth(routeModel, auth)
+	execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
+	execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
+	release := m.beginAttempt(auth, routeModel)
+	started := time.Now()
+	resp, errExec := executor.Execute(execCtx, auth, execReq, opts)
+	release()
+	result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
+	if errExec == nil {
+		result.Duration = time.Since(started)
+	} else {
+		result.Error = &Error{Message: errExec.Error()}
+		var se cliproxyexecutor.StatusError
+		if errors.As(errExec, &se) && se != nil {
+			result.Error.HTTPStatus = se.StatusCode()
+		}
+		if ra := retryAfterFromError(errExec); ra != nil {
+			result.RetryAfter = ra
+		}
+	}
+	return attemptOutcome{ctx: execCtx, auth: auth, resp: resp, result: result, err: errExec}
+}
+
+// hedgeLeg is one in-flight attempt of a hedged request.
+type hedgeLeg struct {
+	cancel  context.CancelFunc
+	usage   *coreusage.Deferred
+	started time.Time
+}
+
+// executeHedged runs the attempt on primary and, if it has not finished after delay, dispatches the
+// same request to a second credential. The first success wins: its usage is published and the
+// other attempt is cancelled, its usage dropped and its result marked once it returns. When every
+// attempt fails, all but the returned failure are marked here.
+func (m *Manager) executeHedged(ctx context.Context, providers []string, routeModel string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, tried map[string]struct{}, delay time.Duration, primary *Auth, executor ProviderExecutor, provider string) attemptOutcome {
+	results := make(chan attemptOutcome, 2)
+	legs := make(map[string]hedgeLeg, 2)
+	launch := func(auth *Auth, executor ProviderExecutor, provider string) {
+		legCtx, cancel := context.WithCancel(ctx)
+		legCtx, usage := coreusage.WithDeferred(legCtx)
+		legs[auth.ID] = hedgeLeg{cancel: cancel, usage: usage, started: time.Now()}
+		go func() {
+			results <- m.executeAttempt(legCtx, auth, executor, provider, routeModel, req, opts)
+		}()
+	}
+	launch(primary, executor, provider)
+	running := 1
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			auth, hedgeExecutor, hedgeProvider, errPick := m.pickNextMixedOnce(ctx, providers, routeModel, opts, tried)
+			if errPick != nil {
+				continue
+			}
+			tried[auth.ID] = struct{}{}
+			debugLogAuthSelection(logEntryWithRequestID(ctx), auth, hedgeProvider, req.Model)
+			launch(auth, hedgeExecutor, hedgeProvider)
+			running++
+		case out := <-results:
+			running--
+			leg := legs[out.auth.ID]
+			delete(legs, out.auth.ID)
+			leg.usage.Commit()
+			leg.cancel()
+			out.ctx = ctx
+			if out.err == nil {
+				m.abandonHedgeLegs(ctx, routeModel, legs, results, running)
+				return out
+			}
+			if running == 0 {
+				return out
+			}
+			if ctx.Err() == nil {
+				m.MarkResult(ctx, out.result)
+			}
+		}
+	}
+}
+
+// abandonHedgeLegs cancels the attempts that lost a hedged request. The time each ran is recorded
+// as a latency sample for routeModel and their usage is dropped. Unless the request itself was
+// cancelled, their results are marked once they return: attempts cut short by the cancellation
+// leave the auth state untouched.
+func (m *Manager) abandonHedgeLegs(ctx context.Context, routeModel string, legs map[string]hedgeLeg, results <-chan attemptOutcome, running int) {
+	if running == 0 {
+		return
+	}
+	now := time.Now()
+	for _, leg := range legs {
+		m.hedgeLatency.observe(routeModel, now.Sub(leg.started))
+		leg.usage.Discard()
+		leg.cancel()
+	}
+	go func() {
+		for ; running > 0; running-- {
+			out := <-results
+			if ctx.Err() != nil {
+				continue
+			}
+			if out.err != nil && out.ctx.Err() != nil {
+				out.result.Abandoned = true
+			}
+			m.MarkResult(ctx, out.result)
+		}
+	}()
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+// hedgeExecutor stalls its first call until cancelled and answers later calls immediately.
+// Every call publishes a usage record before returning.
+type hedgeExecutor struct {
+	recordingExecutor
+	calls atomic.Int32
+}
+
+func (e *hedgeExecutor) Execute(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	if e.calls.Add(1) == 1 {
+		<-ctx.Done()
+		coreusage.PublishRecord(ctx, coreusage.Record{AuthID: auth.ID, Model: req.Model})
+		return cliproxyexecutor.Response{}, ctx.Err()
+	}
+	coreusage.PublishRecord(ctx, coreusage.Record{AuthID: auth.ID, Model: req.Model})
+	return e.recordingExecutor.Execute(ctx, auth, req, opts)
+}
+
+type resultHook struct {
+	NoopHook
+	results chan Result
+}
+
+func (h *resultHook) OnResult(_ context.Context, result Result) { h.results <- result }
+
+type usageCollector struct {
+	model string
+	mu    sync.Mutex
+	auths []string
+}
+
+func (c *usageCollector) HandleUsage(_ context.Context, record coreusage.Record) {
+	if record.Model != c.model {
+		return
+	}
+	c.mu.Lock()
+	c.auths = append(c.auths, record.AuthID)
+	c.mu.Unlock()
+}
+
+func (c *usageCollector) snapshot() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.auths...)
+}
+
+func TestManager_Execute_HedgesSlowAttempt(t *testing.T) {
+	const model = "hedge-test-model"
+
+	usage := &usageCollector{model: model}
+	coreusage.RegisterPlugin(usage)
+	hook := &resultHook{results: make(chan Result, 4)}
+	executor := &hedgeExecutor{recordingExecutor: recordingExecutor{provider: "claude"}}
+	m := NewManager(nil, &FillFirstSelector{}, hook)
+	m.RegisterExecutor(executor)
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{Hedge: internalconfig.HedgeConfig{Enabled: true, Delay: 20}}})
+
+	for _, id := range []string{"hedge-auth-a", "hedge-auth-b"} {
+		auth := &Auth{ID: id, Provider: "claude", Status: StatusActive}
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("register auth %s: %v", id, errRegister)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "claude", []*registry.ModelInfo{{ID: model}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	if _, errExec := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{}); errExec != nil {
+		t.Fatalf("Execute() error = %v", errExec)
+	}
+
+	var winner, loser Result
+	for i := 0; i < 2; i++ {
+		select {
+		case result := <-hook.results:
+			if result.Abandoned {
+				loser = result
+			} else {
+				winner = result
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected MarkResult for both attempts, got %d", i)
+		}
+	}
+	if !winner.Success || loser.AuthID == "" || loser.AuthID == winner.AuthID {
+		t.Fatalf("winner = %+v, loser = %+v; want a successful winner and an abandoned loser", winner, loser)
+	}
+	if auth, _ := m.GetByID(loser.AuthID); auth != nil && auth.ModelStates[model] != nil && auth.ModelStates[model].Unavailable {
+		t.Fatalf("abandoned attempt marked %s unavailable", loser.AuthID)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(usage.snapshot()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := usage.snapshot(); len(got) != 1 || got[0] != winner.AuthID {
+		t.Fatalf("usage records = %v, want only the winner %s", got, winner.AuthID)
+	}
+
+	// Both the winner and the cancelled loser feed the learned hedge delay; the loser ran at least
+	// as long as the configured delay before it was cancelled.
+	m.hedgeLatency.mu.Lock()
+	samples := append([]time.Duration(nil), m.hedgeLatency.samples[model].values...)
+	m.hedgeLatency.mu.Unlock()
+	if len(samples) != 2 {
+		t.Fatalf("hedge latency samples = %v, want the winner and the loser", samples)
+	}
+	longest := samples[0]
+	if samples[1] > longest {
+		longest = samples[1]
+	}
+	if longest < 20*time.Millisecond {
+		t.Fatalf("hedge latency samples = %v, want the loser's run time of at least 20ms", samples)
+	}
+}
+
+func TestLatencyWindow_P95(t *testing.T) {
+	t.Parallel()
+
+	window := newLatencyWindow()
+	for i := 1; i < hedgeMinSamples; i++ {
+		window.observe("m", time.Duration(i)*time.Millisecond)
+	}
+	if _, ok := window.p95("m"); ok {
+		t.Fatalf("expected no learned delay below %d samples", hedgeMinSamples)
+	}
+	for i := hedgeMinSamples; i <= 100; i++ {
+		window.observe("m", time.Duration(i)*time.Millisecond)
+	}
+	if p95, ok := window.p95("m"); !ok || p95 != 95*time.Millisecond {
+		t.Fatalf("p95 = %v, %v; want 95ms", p95, ok)
+	}
+}
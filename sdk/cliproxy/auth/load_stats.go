@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// latencyEWMAAlpha is the smoothing factor applied to new latency samples.
+const latencyEWMAAlpha = 0.2
+
+// errorRateEWMAAlpha is the smoothing factor applied to new request outcomes.
+const errorRateEWMAAlpha = 0.2
+
+// LoadStats tracks in-flight requests and EWMAs of latency and error rate per auth+model. Streaming
+// time-to-first-byte and non-streaming response time are kept apart, since a complete response
+// always takes longer than its first byte. The manager feeds it on every dispatched attempt and
+// from MarkResult; selectors read it.
+type LoadStats struct {
+	mu      sync.Mutex
+	entries map[string]*loadEntry
+}
+
+type loadEntry struct {
+	inflight int
+	ttfb     latencyEWMA
+	total    latencyEWMA
+
+	errorRate float64
+	outcomes  int64
+}
+
+// latencyEWMA is an exponentially weighted moving average of latency samples.
+type latencyEWMA struct {
+	value   float64
+	samples int64
+}
+
+func (e *latencyEWMA) observe(latency time.Duration) {
+	sample := float64(latency)
+	if e.samples == 0 {
+		e.value = sample
+	} else {
+		e.value = latencyEWMAAlpha*sample + (1-latencyEWMAAlpha)*e.value
+	}
+	e.samples++
+}
+
+// LoadStatsConsumer is implemented by selectors that rank candidates using LoadStats.
+// The manager injects its LoadStats when the selector is installed.
+type LoadStatsConsumer interface {
+	SetLoadStats(stats *LoadStats)
+}
+
+// NewLoadStats constructs an empty LoadStats tracker.
+func NewLoadStats() *LoadStats {
+	return &LoadStats{entries: make(map[string]*loadEntry)}
+}
+
+func loadStatsKey(authID, model string) string {
+	return authID + "|" + model
+}
+
+func (s *LoadStats) entryLocked(authID, model string) *loadEntry {
+	key := loadStatsKey(authID, model)
+	entry := s.entries[key]
+	if entry == nil {
+		entry = &loadEntry{}
+		s.entries[key] = entry
+	}
+	return entry
+}
+
+// acquire records a dispatched request and returns a release func that must be called exactly once
+// when the attempt completes.
+func (s *LoadStats) acquire(authID, model string) func() {
+	if s == nil || authID == "" {
+		return func() {}
+	}
+	s.mu.Lock()
+	s.entryLocked(authID, model).inflight++
+	s.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			if entry := s.entries[loadStatsKey(authID, model)]; entry != nil && entry.inflight > 0 {
+				entry.inflight--
+			}
+			s.mu.Unlock()
+		})
+	}
+}
+
+// observeLatency folds a streaming time-to-first-byte sample into the EWMA for auth+model.
+func (s *LoadStats) observeLatency(authID, model string, latency time.Duration) {
+	if s == nil || authID == "" || latency <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entryLocked(authID, model).ttfb.observe(latency)
+}
+
+// observeDuration folds a non-streaming response time sample into the EWMA for auth+model.
+func (s *LoadStats) observeDuration(authID, model string, duration time.Duration) {
+	if s == nil || authID == "" || duration <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entryLocked(authID, model).total.observe(duration)
+}
+
+// observeOutcome folds a request outcome into the error rate EWMA for auth+model.
+func (s *LoadStats) observeOutcome(authID, model string, failed bool) {
+	if s == nil || authID == "" {
+		return
+	}
+	sample := 0.0
+	if failed {
+		sample = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.entryLocked(authID, model)
+	if entry.outcomes == 0 {
+		entry.errorRate = sample
+	} else {
+		entry.errorRate = errorRateEWMAAlpha*sample + (1-errorRateEWMAAlpha)*entry.errorRate
+	}
+	entry.outcomes++
+}
+
+// ErrorRate returns the recent error rate (0-1) of auth+model and whether any outcome was recorded.
+func (s *LoadStats) ErrorRate(authID, model string) (float64, bool) {
+	if s == nil {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry := s.entries[loadStatsKey(authID, model)]; entry != nil && entry.outcomes > 0 {
+		return entry.errorRate, true
+	}
+	return 0, false
+}
+
+// InFlight returns the number of requests currently dispatched to auth for model.
+func (s *LoadStats) InFlight(authID, model string) int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry := s.entries[loadStatsKey(authID, model)]; entry != nil {
+		return entry.inflight
+	}
+	return 0
+}
+
+// Latency returns the EWMA streaming time-to-first-byte for auth+model and whether any sample was
+// recorded.
+func (s *LoadStats) Latency(authID, model string) (time.Duration, bool) {
+	if s == nil {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry := s.entries[loadStatsKey(authID, model)]; entry != nil && entry.ttfb.samples > 0 {
+		return time.Duration(entry.ttfb.value), true
+	}
+	return 0, false
+}
+
+// Duration returns the EWMA non-streaming response time for auth+model and whether any sample was
+// recorded.
+func (s *LoadStats) Duration(authID, model string) (time.Duration, bool) {
+	if s == nil {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry := s.entries[loadStatsKey(authID, model)]; entry != nil && entry.total.samples > 0 {
+		return time.Duration(entry.total.value), true
+	}
+	return 0, false
+}
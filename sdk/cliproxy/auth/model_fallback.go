@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// ModelFallbackInfo describes a request that was served by a configured fallback model
+// because every credential for the requested model was exhausted.
+type ModelFallbackInfo struct {
+	// RequestedModel is the model originally requested by the client.
+	RequestedModel string
+	// ServedModel is the fallback model that handled the request.
+	ServedModel string
+	// Hop is the 1-based position of ServedModel in the fallback chain.
+	Hop int
+	// Reason explains why ServedModel was used; see the ModelFallbackReason constants.
+	Reason string
+}
+
+const (
+	// ModelFallbackReasonChain marks a hop taken from the model-fallbacks configuration.
+	ModelFallbackReasonChain = "model-fallback"
+	// ModelFallbackReasonPreview marks a switch to the preview sibling of the requested model
+	// enabled by quota-exceeded.switch-preview-model.
+	ModelFallbackReasonPreview = "preview-model"
+)
+
+type modelFallbackContextKey struct{}
+
+type modelFallbackObserverContextKey struct{}
+
+// WithModelFallback returns a derived context marking the execution as a fallback hop.
+// Executors read it to attribute usage records; the manager uses it to avoid chaining fallbacks.
+func WithModelFallback(ctx context.Context, info ModelFallbackInfo) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, modelFallbackContextKey{}, info)
+}
+
+// ModelFallbackFromContext reports the fallback hop carried by ctx, if any.
+func ModelFallbackFromContext(ctx context.Context) (ModelFallbackInfo, bool) {
+	if ctx == nil {
+		return ModelFallbackInfo{}, false
+	}
+	info, ok := ctx.Value(modelFallbackContextKey{}).(ModelFallbackInfo)
+	return info, ok
+}
+
+// WithModelFallbackObserver returns a derived context whose observer is invoked once a
+// fallback model has accepted the request. Handlers use it to surface the served model to clients.
+func WithModelFallbackObserver(ctx context.Context, observer func(ModelFallbackInfo)) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if observer == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, modelFallbackObserverContextKey{}, observer)
+}
+
+func notifyModelFallback(ctx context.Context, info ModelFallbackInfo) {
+	if ctx == nil {
+		return
+	}
+	if observer, ok := ctx.Value(modelFallbackObserverContextKey{}).(func(ModelFallbackInfo)); ok && observer != nil {
+		observer(info)
+	}
+}
+
+// ModelFallbacks returns the configured fallback chain for the requested model.
+// Exact entries take precedence over wildcard entries, which are evaluated in configuration order.
+// The thinking suffix of the requested model is carried over to fallbacks that do not define one.
+func (m *Manager) ModelFallbacks(model string) []string {
+	if m == nil {
+		return nil
+	}
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return nil
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || len(cfg.ModelFallbacks) == 0 {
+		return nil
+	}
+
+	requestResult := thinking.ParseSuffix(model)
+	base := requestResult.ModelName
+	candidates := []string{base}
+	if base != model {
+		candidates = append(candidates, model)
+	}
+
+	var chain []string
+	for _, entry := range cfg.ModelFallbacks {
+		for _, candidate := range candidates {
+			if strings.EqualFold(strings.TrimSpace(entry.Model), candidate) {
+				chain = entry.Fallbacks
+				break
+			}
+		}
+		if chain != nil {
+			break
+		}
+	}
+	if chain == nil {
+		for _, entry := range cfg.ModelFallbacks {
+			pattern := strings.ToLower(strings.TrimSpace(entry.Model))
+			if !strings.Contains(pattern, "*") {
+				continue
+			}
+			if util.MatchWildcard(pattern, strings.ToLower(base)) {
+				chain = entry.Fallbacks
+				break
+			}
+		}
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+
+	out := make([]string, 0, len(chain))
+	seen := map[string]struct{}{strings.ToLower(base): {}}
+	for _, raw := range chain {
+		fallback := strings.TrimSpace(raw)
+		if fallback == "" {
+			continue
+		}
+		fallbackBase := strings.ToLower(thinking.ParseSuffix(fallback).ModelName)
+		if _, ok := seen[fallbackBase]; ok {
+			continue
+		}
+		seen[fallbackBase] = struct{}{}
+		if !thinking.ParseSuffix(fallback).HasSuffix && requestResult.HasSuffix && requestResult.RawSuffix != "" {
+			fallback = fallback + "(" + requestResult.RawSuffix + ")"
+		}
+		out = append(out, fallback)
+	}
+	return out
+}
+
+// PreviewModel returns the preview sibling used by quota-exceeded.switch-preview-model.
+// The quota-exceeded.preview-models mapping takes precedence over registry discovery.
+// The thinking suffix of the requested model is preserved.
+func (m *Manager) PreviewModel(model string) string {
+	if m == nil {
+		return ""
+	}
+	requestResult := thinking.ParseSuffix(strings.TrimSpace(model))
+	base := requestResult.ModelName
+	if base == "" {
+		return ""
+	}
+	preview := ""
+	if cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config); cfg != nil {
+		for name, mapped := range cfg.QuotaExceeded.PreviewModels {
+			if strings.EqualFold(strings.TrimSpace(name), base) {
+				preview = strings.TrimSpace(mapped)
+				break
+			}
+		}
+	}
+	if preview == "" {
+		preview = registry.GetGlobalRegistry().GetPreviewModel(base)
+	}
+	if preview == "" || strings.EqualFold(preview, base) {
+		return ""
+	}
+	if !thinking.ParseSuffix(preview).HasSuffix && requestResult.HasSuffix && requestResult.RawSuffix != "" {
+		preview = preview + "(" + requestResult.RawSuffix + ")"
+	}
+	return preview
+}
+
+// modelFallbackCandidates lists the hops to try after the requested model failed with cause.
+// The preview sibling comes first when switch-preview-model is enabled and cause is a quota error,
+// followed by the configured model-fallbacks chain.
+func (m *Manager) modelFallbackCandidates(model string, cause error) []ModelFallbackInfo {
+	var out []ModelFallbackInfo
+	seen := make(map[string]struct{})
+	add := func(served, reason string) {
+		key := strings.ToLower(served)
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		out = append(out, ModelFallbackInfo{RequestedModel: model, ServedModel: served, Hop: len(out) + 1, Reason: reason})
+	}
+	if cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config); cfg != nil && cfg.QuotaExceeded.SwitchPreviewModel && isQuotaExceededError(cause) {
+		if preview := m.PreviewModel(model); preview != "" {
+			add(preview, ModelFallbackReasonPreview)
+		}
+	}
+	for _, fallback := range m.ModelFallbacks(model) {
+		add(fallback, ModelFallbackReasonChain)
+	}
+	return out
+}
+
+// ExecuteStreamModelFallback walks the fallback chain of req.Model for a streaming request whose
+// previous attempt failed with cause. It returns cause unchanged when the error is not eligible
+// for fallback or no fallback model accepts the request.
+func (m *Manager) ExecuteStreamModelFallback(ctx context.Context, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, cause error) (<-chan cliproxyexecutor.StreamChunk, error) {
+	return executeModelFallbacks(ctx, m, req, opts, cause, m.executeStreamWithRetries)
+}
+
+// executeModelFallbacks tries each fallback model of req.Model in order using run.
+// The original error is returned when no fallback succeeds so clients still see the
+// cooldown details of the model they requested.
+func executeModelFallbacks[T any](ctx context.Context, m *Manager, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, cause error, run func(context.Context, []string, cliproxyexecutor.Request, cliproxyexecutor.Options) (T, error)) (T, error) {
+	var zero T
+	if m == nil || !isModelFallbackEligible(cause) {
+		return zero, cause
+	}
+	// Fallback hops never chain into the fallbacks of the fallback model.
+	if _, ok := ModelFallbackFromContext(ctx); ok {
+		return zero, cause
+	}
+	candidates := m.modelFallbackCandidates(req.Model, cause)
+	if len(candidates) == 0 {
+		return zero, cause
+	}
+
+	entry := logEntryWithRequestID(ctx)
+	for _, info := range candidates {
+		if !clientModelAllowed(opts, info.ServedModel) {
+			entry.Debugf("%s %s -> %s skipped: not allowed for client", info.Reason, req.Model, info.ServedModel)
+			continue
+		}
+		providers := m.normalizeProviders(modelFallbackProviders(info.ServedModel))
+		if len(providers) == 0 {
+			entry.Debugf("%s %s -> %s skipped: no provider", info.Reason, req.Model, info.ServedModel)
+			continue
+		}
+		hopReq := req
+		hopReq.Model = info.ServedModel
+		hopOpts := opts
+		hopOpts.Metadata = withRequestedModelMetadata(opts.Metadata, info.ServedModel)
+		out, errRun := run(WithModelFallback(ctx, info), providers, hopReq, hopOpts)
+		if errRun == nil {
+			entry.Infof("%s %s -> %s (hop %d)", info.Reason, req.Model, info.ServedModel, info.Hop)
+			notifyModelFallback(ctx, info)
+			return out, nil
+		}
+		if errCtx := ctx.Err(); errCtx != nil {
+			return zero, errCtx
+		}
+		entry.Debugf("%s %s -> %s failed: %v", info.Reason, req.Model, info.ServedModel, errRun)
+	}
+	return zero, cause
+}
+
+// isModelFallbackEligible reports whether err means the requested model has no usable credential,
+// as opposed to a request-level failure that would also fail on another model.
+func isModelFallbackEligible(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var authErr *Error
+	if errors.As(err, &authErr) && authErr != nil {
+		switch authErr.Code {
+		case "auth_not_found", "auth_unavailable":
+			return true
+		}
+	}
+	return isQuotaExceededError(err)
+}
+
+// isQuotaExceededError reports whether err is a quota/rate-limit failure of the requested model.
+func isQuotaExceededError(err error) bool {
+	var cooldownErr *modelCooldownError
+	if errors.As(err, &cooldownErr) {
+		return true
+	}
+	return statusCodeFromError(err) == http.StatusTooManyRequests
+}
+
+// clientModelAllowed applies the model policy of the client carried in opts to a model the request
+// is rerouted to.
+func clientModelAllowed(opts cliproxyexecutor.Options, model string) bool {
+	access, _ := opts.Metadata[cliproxyexecutor.AccessMetadataKey].(map[string]string)
+	return sdkaccess.ModelAllowed(access, thinking.ParseSuffix(model).ModelName)
+}
+
+func modelFallbackProviders(model string) []string {
+	base := strings.TrimSpace(thinking.ParseSuffix(model).ModelName)
+	providers := util.GetProviderName(base)
+	if len(providers) == 0 && base != model {
+		providers = util.GetProviderName(model)
+	}
+	return providers
+}
+
+func withRequestedModelMetadata(meta map[string]any, model string) map[string]any {
+	out := make(map[string]any, len(meta)+1)
+	for k, v := range meta {
+		out[k] = v
+	}
+	out[cliproxyexecutor.RequestedModelMetadataKey] = model
+	return out
+}
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// recordingExecutor records the model and auth of each call and succeeds unless the
+// model is listed in failModels or the auth ID is listed in failAuths.
+type recordingExecutor struct {
+	provider   string
+	failModels map[string]error
+	failAuths  map[string]error
+
+	mu    sync.Mutex
+	calls []string
+	auths []string
+	ctxs  []context.Context
+}
+
+func (e *recordingExecutor) Identifier() string { return e.provider }
+
+func (e *recordingExecutor) record(ctx context.Context, auth *Auth, model string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, model)
+	e.ctxs = append(e.ctxs, ctx)
+	if auth != nil {
+		e.auths = append(e.auths, auth.ID)
+		if err, ok := e.failAuths[auth.ID]; ok {
+			return err
+		}
+	}
+	if err, ok := e.failModels[model]; ok {
+		return err
+	}
+	return nil
+}
+
+func (e *recordingExecutor) Execute(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	if err := e.record(ctx, auth, req.Model); err != nil {
+		return cliproxyexecutor.Response{}, err
+	}
+	return cliproxyexecutor.Response{Payload: []byte(req.Model)}, nil
+}
+
+func (e *recordingExecutor) ExecuteStream(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, _ cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	if err := e.record(ctx, auth, req.Model); err != nil {
+		return nil, err
+	}
+	ch := make(chan cliproxyexecutor.StreamChunk, 1)
+	ch <- cliproxyexecutor.StreamChunk{Payload: []byte(req.Model)}
+	close(ch)
+	return ch, nil
+}
+
+func (e *recordingExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) { return auth, nil }
+
+func (e *recordingExecutor) CountTokens(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return e.Execute(ctx, auth, req, opts)
+}
+
+func (e *recordingExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, &Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func (e *recordingExecutor) Calls() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.calls...)
+}
+
+func (e *recordingExecutor) AuthCalls() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.auths...)
+}
+
+func TestManager_ModelFallbacks_ExactBeforeWildcard(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	m.SetConfig(&internalconfig.Config{ModelFallbacks: []internalconfig.ModelFallback{
+		{Model: "gemini-*", Fallbacks: []string{"gpt-5"}},
+		{Model: "gemini-2.5-pro", Fallbacks: []string{"gemini-2.5-flash", "gemini-2.5-pro", "claude-sonnet-4-5(high)"}},
+	}})
+
+	got := m.ModelFallbacks("gemini-2.5-pro(8192)")
+	want := []string{"gemini-2.5-flash(8192)", "claude-sonnet-4-5(high)"}
+	if len(got) != len(want) {
+		t.Fatalf("ModelFallbacks() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ModelFallbacks()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if got = m.ModelFallbacks("gemini-2.5-flash"); len(got) != 1 || got[0] != "gpt-5" {
+		t.Fatalf("ModelFallbacks(wildcard) = %v, want [gpt-5]", got)
+	}
+	if got = m.ModelFallbacks("claude-sonnet-4-5"); len(got) != 0 {
+		t.Fatalf("ModelFallbacks(unmatched) = %v, want none", got)
+	}
+}
+
+func TestManager_Execute_UsesModelFallbackWhenCredentialsCoolDown(t *testing.T) {
+	const primary = "fallback-test-primary"
+	const backup = "fallback-test-backup"
+
+	executor := &recordingExecutor{provider: "claude"}
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(executor)
+	m.SetConfig(&internalconfig.Config{ModelFallbacks: []internalconfig.ModelFallback{
+		{Model: primary, Fallbacks: []string{backup}},
+	}})
+
+	cooling := &Auth{
+		ID:       "fallback-auth-primary",
+		Provider: "claude",
+		Status:   StatusActive,
+		ModelStates: map[string]*ModelState{
+			primary: {
+				Unavailable:    true,
+				Status:         StatusError,
+				NextRetryAfter: time.Now().Add(time.Hour),
+				Quota:          QuotaState{Exceeded: true, NextRecoverAt: time.Now().Add(time.Hour)},
+			},
+		},
+	}
+	healthy := &Auth{ID: "fallback-auth-backup", Provider: "claude", Status: StatusActive}
+	for _, a := range []*Auth{cooling, healthy} {
+		if _, errRegister := m.Register(context.Background(), a); errRegister != nil {
+			t.Fatalf("register auth %s: %v", a.ID, errRegister)
+		}
+	}
+	registry.GetGlobalRegistry().RegisterClient(cooling.ID, "claude", []*registry.ModelInfo{{ID: primary}})
+	registry.GetGlobalRegistry().RegisterClient(healthy.ID, "claude", []*registry.ModelInfo{{ID: backup}})
+	t.Cleanup(func() {
+		registry.GetGlobalRegistry().UnregisterClient(cooling.ID)
+		registry.GetGlobalRegistry().UnregisterClient(healthy.ID)
+	})
+
+	var observed ModelFallbackInfo
+	ctx := WithModelFallbackObserver(context.Background(), func(info ModelFallbackInfo) { observed = info })
+	resp, errExec := m.Execute(ctx, []string{"claude"}, cliproxyexecutor.Request{Model: primary}, cliproxyexecutor.Options{})
+	if errExec != nil {
+		t.Fatalf("Execute() error = %v", errExec)
+	}
+	if string(resp.Payload) != backup {
+		t.Fatalf("Execute() payload = %q, want %q", string(resp.Payload), backup)
+	}
+	if observed.RequestedModel != primary || observed.ServedModel != backup || observed.Hop != 1 {
+		t.Fatalf("observed fallback = %+v", observed)
+	}
+
+	executor.mu.Lock()
+	hopInfo, ok := ModelFallbackFromContext(executor.ctxs[0])
+	executor.mu.Unlock()
+	if !ok || hopInfo.ServedModel != backup {
+		t.Fatalf("expected executor context to carry fallback info, got %+v (ok=%v)", hopInfo, ok)
+	}
+
+	// Fallbacks the client key may not use are skipped.
+	denied := cliproxyexecutor.Options{Metadata: map[string]any{
+		cliproxyexecutor.AccessMetadataKey: map[string]string{sdkaccess.MetadataDeniedModels: "fallback-test-b*"},
+	}}
+	if _, errExec = m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: primary}, denied); statusCodeFromError(errExec) != http.StatusTooManyRequests {
+		t.Fatalf("expected cooldown error when the fallback is denied, got %v", errExec)
+	}
+
+	// Without a configured chain the original cooldown error is returned.
+	m.SetConfig(&internalconfig.Config{})
+	if _, errExec = m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: primary}, cliproxyexecutor.Options{}); errExec == nil {
+		t.Fatalf("expected cooldown error without model fallbacks")
+	}
+	if status := statusCodeFromError(errExec); status != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 without model fallbacks, got %d (%v)", status, errExec)
+	}
+}
+
+func TestManager_Execute_SwitchesToPreviewModelOnQuota(t *testing.T) {
+	const primary = "preview-switch-test"
+	const preview = "preview-switch-test-preview"
+
+	executor := &recordingExecutor{provider: "claude"}
+	m := NewManager(nil, nil, nil)
+	m.RegisterExecutor(executor)
+
+	cooling := &Auth{
+		ID:       "preview-auth-primary",
+		Provider: "claude",
+		Status:   StatusActive,
+		ModelStates: map[string]*ModelState{
+			primary: {
+				Unavailable:    true,
+				Status:         StatusError,
+				NextRetryAfter: time.Now().Add(time.Hour),
+				Quota:          QuotaState{Exceeded: true, NextRecoverAt: time.Now().Add(time.Hour)},
+			},
+		},
+	}
+	healthy := &Auth{ID: "preview-auth-backup", Provider: "claude", Status: StatusActive}
+	for _, a := range []*Auth{cooling, healthy} {
+		if _, errRegister := m.Register(context.Background(), a); errRegister != nil {
+			t.Fatalf("register auth %s: %v", a.ID, errRegister)
+		}
+	}
+	registry.GetGlobalRegistry().RegisterClient(cooling.ID, "claude", []*registry.ModelInfo{{ID: primary}})
+	registry.GetGlobalRegistry().RegisterClient(healthy.ID, "claude", []*registry.ModelInfo{{ID: preview}})
+	t.Cleanup(func() {
+		registry.GetGlobalRegistry().UnregisterClient(cooling.ID)
+		registry.GetGlobalRegistry().UnregisterClient(healthy.ID)
+	})
+
+	if got := m.PreviewModel(primary + "(high)"); got != preview+"(high)" {
+		t.Fatalf("PreviewModel() = %q, want %q", got, preview+"(high)")
+	}
+
+	m.SetConfig(&internalconfig.Config{})
+	if _, errExec := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: primary}, cliproxyexecutor.Options{}); errExec == nil {
+		t.Fatalf("expected quota error when switch-preview-model is disabled")
+	}
+
+	m.SetConfig(&internalconfig.Config{QuotaExceeded: internalconfig.QuotaExceeded{SwitchPreviewModel: true}})
+	restricted := cliproxyexecutor.Options{Metadata: map[string]any{
+		cliproxyexecutor.AccessMetadataKey: map[string]string{sdkaccess.MetadataAllowedModels: primary},
+	}}
+	if _, errExec := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: primary}, restricted); errExec == nil {
+		t.Fatalf("expected quota error when the preview model is not allowed for the client")
+	}
+
+	var observed ModelFallbackInfo
+	ctx := WithModelFallbackObserver(context.Background(), func(info ModelFallbackInfo) { observed = info })
+	resp, errExec := m.Execute(ctx, []string{"claude"}, cliproxyexecutor.Request{Model: primary}, cliproxyexecutor.Options{})
+	if errExec != nil {
+		t.Fatalf("Execute() error = %v", errExec)
+	}
+	if string(resp.Payload) != preview {
+		t.Fatalf("Execute() payload = %q, want %q", string(resp.Payload), preview)
+	}
+	if observed.Reason != ModelFallbackReasonPreview || observed.ServedModel != preview {
+		t.Fatalf("observed fallback = %+v, want preview switch to %s", observed, preview)
+	}
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+)
+
+// ProviderShare reports how requests for a model are split across its weighted providers.
+type ProviderShare struct {
+	Provider string `json:"provider"`
+	// Weight is the configured weight; zero when the provider is no longer weighted.
+	Weight int `json:"weight"`
+	// Requests counts the requests routed to the provider since startup.
+	Requests int64 `json:"requests"`
+	// Share is Requests as a fraction of all requests routed for the model.
+	Share float64 `json:"share"`
+}
+
+// providerBalancer splits requests for multi-provider models across providers by weight using
+// smooth weighted round-robin, and counts the routed requests for share statistics.
+type providerBalancer struct {
+	mu      sync.Mutex
+	current map[string]map[string]int
+	picks   map[string]map[string]int64
+}
+
+func newProviderBalancer() *providerBalancer {
+	return &providerBalancer{
+		current: make(map[string]map[string]int),
+		picks:   make(map[string]map[string]int64),
+	}
+}
+
+// next returns the provider that should serve the next request for model. providers must be
+// sorted and carry positive weights.
+func (b *providerBalancer) next(model string, providers []string, weights map[string]int) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current := b.current[model]
+	if current == nil {
+		current = make(map[string]int, len(providers))
+		b.current[model] = current
+	}
+	best, total := "", 0
+	for _, provider := range providers {
+		weight := weights[provider]
+		current[provider] += weight
+		total += weight
+		if best == "" || current[provider] > current[best] {
+			best = provider
+		}
+	}
+	current[best] -= total
+	return best
+}
+
+// peek returns the provider next would return, without advancing the rotation.
+func (b *providerBalancer) peek(model string, providers []string, weights map[string]int) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current := b.current[model]
+	best, bestValue := "", 0
+	for _, provider := range providers {
+		value := current[provider] + weights[provider]
+		if best == "" || value > bestValue {
+			best, bestValue = provider, value
+		}
+	}
+	return best
+}
+
+// record counts a request for model routed to provider.
+func (b *providerBalancer) record(model, provider string) {
+	b.mu.Lock()
+	picks := b.picks[model]
+	if picks == nil {
+		picks = make(map[string]int64)
+		b.picks[model] = picks
+	}
+	picks[provider]++
+	b.mu.Unlock()
+}
+
+// providerWeightsFor returns the configured provider weights of model, or nil.
+func (m *Manager) providerWeightsFor(model string) map[string]int {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || len(cfg.Routing.ProviderWeights) == 0 {
+		return nil
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return nil
+	}
+	for _, entry := range cfg.Routing.ProviderWeights {
+		if entry.Model == model {
+			return entry.Weights
+		}
+	}
+	for _, entry := range cfg.Routing.ProviderWeights {
+		if strings.Contains(entry.Model, "*") && util.MatchWildcard(entry.Model, model) {
+			return entry.Weights
+		}
+	}
+	return nil
+}
+
+// weightedProviderCandidates narrows candidates spanning several providers to the provider chosen
+// by the configured weights of modelKey. Only weighted providers with at least one credential
+// available for model take part; when there is none, candidates are returned unchanged. The
+// chosen provider is returned alongside, empty when no weighting applied. With dryRun the
+// rotation does not advance. Callers must hold m.mu.
+func (m *Manager) weightedProviderCandidates(candidates []*Auth, modelKey, model string, now time.Time, dryRun bool) ([]*Auth, string) {
+	weights := m.providerWeightsFor(modelKey)
+	if len(weights) == 0 {
+		return candidates, ""
+	}
+	available := make(map[string]struct{})
+	for _, candidate := range candidates {
+		provider := strings.ToLower(strings.TrimSpace(candidate.Provider))
+		if weights[provider] <= 0 {
+			continue
+		}
+		if blocked, _, _ := isAuthBlockedForModel(candidate, model, now); !blocked {
+			available[provider] = struct{}{}
+		}
+	}
+	if len(available) == 0 {
+		return candidates, ""
+	}
+	providers := make([]string, 0, len(available))
+	for provider := range available {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	var chosen string
+	if dryRun {
+		chosen = m.providerBalancer.peek(strings.ToLower(modelKey), providers, weights)
+	} else {
+		chosen = m.providerBalancer.next(strings.ToLower(modelKey), providers, weights)
+	}
+	filtered := make([]*Auth, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.ToLower(strings.TrimSpace(candidate.Provider)) == chosen {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered, chosen
+}
+
+// ProviderShares returns, per model with configured provider weights, how the requests routed
+// so far were split across providers.
+func (m *Manager) ProviderShares() map[string][]ProviderShare {
+	out := make(map[string][]ProviderShare)
+	if m == nil || m.providerBalancer == nil {
+		return out
+	}
+	b := m.providerBalancer
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for model, picks := range b.picks {
+		weights := m.providerWeightsFor(model)
+		var total int64
+		for _, count := range picks {
+			total += count
+		}
+		shares := make([]ProviderShare, 0, len(picks)+len(weights))
+		for provider, count := range picks {
+			share := ProviderShare{Provider: provider, Weight: weights[provider], Requests: count}
+			if total > 0 {
+				share.Share = float64(count) / float64(total)
+			}
+			shares = append(shares, share)
+		}
+		for provider, weight := range weights {
+			if _, seen := picks[provider]; !seen {
+				shares = append(shares, ProviderShare{Provider: provider, Weight: weight})
+			}
+		}
+		sort.Slice(shares, func(i, j int) bool { return shares[i].Provider < shares[j].Provider })
+		out[model] = shares
+	}
+	return out
+}
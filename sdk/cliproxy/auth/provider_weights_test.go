@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestManager_ProviderWeightsSplitTraffic(t *testing.T) {
+	const model = "provider-weights-model"
+
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{ProviderWeights: []internalconfig.ProviderWeight{
+		{Model: "provider-weights-*", Weights: map[string]int{"gemini-cli": 7, "antigravity": 3}},
+	}}})
+	executors := map[string]*recordingExecutor{}
+	for _, provider := range []string{"gemini-cli", "antigravity", "vertex"} {
+		executor := &recordingExecutor{provider: provider}
+		executors[provider] = executor
+		m.RegisterExecutor(executor)
+		auth := &Auth{ID: "pw-" + provider, Provider: provider, Status: StatusActive}
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("register auth %s: %v", auth.ID, errRegister)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, provider, []*registry.ModelInfo{{ID: model}})
+		id := auth.ID
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	providers := []string{"gemini-cli", "antigravity", "vertex"}
+	for i := 0; i < 10; i++ {
+		if _, errExec := m.Execute(context.Background(), providers, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{}); errExec != nil {
+			t.Fatalf("Execute() error = %v", errExec)
+		}
+	}
+	if got := len(executors["gemini-cli"].Calls()); got != 7 {
+		t.Fatalf("gemini-cli calls = %d, want 7", got)
+	}
+	if got := len(executors["antigravity"].Calls()); got != 3 {
+		t.Fatalf("antigravity calls = %d, want 3", got)
+	}
+	if got := len(executors["vertex"].Calls()); got != 0 {
+		t.Fatalf("unweighted vertex calls = %d, want 0", got)
+	}
+
+	shares := m.ProviderShares()[model]
+	if len(shares) != 2 || shares[1].Provider != "gemini-cli" || shares[1].Requests != 7 || shares[1].Weight != 7 || shares[1].Share != 0.7 {
+		t.Fatalf("ProviderShares() = %+v, want gemini-cli with 7 of 10 requests", shares)
+	}
+
+	// Once the weighted providers cool down, the unweighted provider takes over.
+	for _, provider := range []string{"gemini-cli", "antigravity"} {
+		m.MarkResult(context.Background(), Result{AuthID: "pw-" + provider, Provider: provider, Model: model, Error: &Error{Message: "quota", HTTPStatus: 429}})
+	}
+	if _, errExec := m.Execute(context.Background(), providers, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{}); errExec != nil {
+		t.Fatalf("Execute() after cooldown error = %v", errExec)
+	}
+	if got := len(executors["vertex"].Calls()); got != 1 {
+		t.Fatalf("vertex calls after cooldown = %d, want 1", got)
+	}
+}
+
+func TestManager_ExplainRoutingMatchesWeightedPicks(t *testing.T) {
+	const model = "provider-weights-explain-model"
+
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{ProviderWeights: []internalconfig.ProviderWeight{
+		{Model: model, Weights: map[string]int{"gemini-cli": 2, "antigravity": 1}},
+	}}})
+	providers := []string{"gemini-cli", "antigravity"}
+	for _, provider := range providers {
+		m.RegisterExecutor(&recordingExecutor{provider: provider})
+		for _, suffix := range []string{"-1", "-2"} {
+			auth := &Auth{ID: "pwe-" + provider + suffix, Provider: provider, Status: StatusActive}
+			if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+				t.Fatalf("register auth %s: %v", auth.ID, errRegister)
+			}
+			registry.GetGlobalRegistry().RegisterClient(auth.ID, provider, []*registry.ModelInfo{{ID: model}})
+			id := auth.ID
+			t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+		}
+	}
+
+	for i := 0; i < 6; i++ {
+		explanation := m.ExplainRouting(context.Background(), providers, model, cliproxyexecutor.Options{})
+		again := m.ExplainRouting(context.Background(), providers, model, cliproxyexecutor.Options{})
+		if again.Selected != explanation.Selected || again.WeightedProvider != explanation.WeightedProvider {
+			t.Fatalf("explain %d is not repeatable: %q/%q then %q/%q", i, explanation.WeightedProvider, explanation.Selected, again.WeightedProvider, again.Selected)
+		}
+		for _, candidate := range explanation.Candidates {
+			if candidate.Provider != explanation.WeightedProvider && (candidate.Eligible || candidate.Reason != "provider_weight") {
+				t.Fatalf("explain %d candidate %+v of a provider not chosen by weight", i, candidate)
+			}
+		}
+		auth, _, provider, errPick := m.pickNextMixedOnce(context.Background(), providers, model, cliproxyexecutor.Options{}, map[string]struct{}{})
+		if errPick != nil {
+			t.Fatalf("pick %d: %v", i, errPick)
+		}
+		m.concurrency.release(auth)
+		if auth.ID != explanation.Selected || provider != explanation.WeightedProvider {
+			t.Fatalf("pick %d = %s via %s, explain said %s via %s", i, auth.ID, provider, explanation.Selected, explanation.WeightedProvider)
+		}
+	}
+}
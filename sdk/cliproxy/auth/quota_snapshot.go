@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// ParseQuotaHeaders reads the remaining-quota headers returned by upstream providers:
+// anthropic-ratelimit-* (Claude), x-ratelimit-* (OpenAI and compatible APIs) and
+// x-codex-*-used-percent (Codex). It reports false when none are present.
+func ParseQuotaHeaders(header http.Header, now time.Time) (QuotaSnapshot, bool) {
+	snapshot := QuotaSnapshot{UpdatedAt: now}
+	if len(header) == 0 {
+		return snapshot, false
+	}
+	found := false
+	var requestsReset, tokensReset time.Time
+
+	// Claude: counts with RFC 3339 reset times.
+	if limit, remaining, ok := headerPair(header, "anthropic-ratelimit-requests-limit", "anthropic-ratelimit-requests-remaining"); ok {
+		snapshot.LimitRequests, snapshot.RemainingRequests = limit, remaining
+		requestsReset = headerTime(header.Get("anthropic-ratelimit-requests-reset"))
+		found = true
+	}
+	for _, kind := range []string{"tokens", "input-tokens"} {
+		prefix := "anthropic-ratelimit-" + kind
+		if limit, remaining, ok := headerPair(header, prefix+"-limit", prefix+"-remaining"); ok {
+			snapshot.LimitTokens, snapshot.RemainingTokens = limit, remaining
+			tokensReset = headerTime(header.Get(prefix + "-reset"))
+			found = true
+			break
+		}
+	}
+
+	// OpenAI and compatible APIs: counts with duration resets such as "6m0s".
+	if limit, remaining, ok := headerPair(header, "x-ratelimit-limit-requests", "x-ratelimit-remaining-requests"); ok {
+		snapshot.LimitRequests, snapshot.RemainingRequests = limit, remaining
+		requestsReset = headerDelay(header.Get("x-ratelimit-reset-requests"), now)
+		found = true
+	}
+	if limit, remaining, ok := headerPair(header, "x-ratelimit-limit-tokens", "x-ratelimit-remaining-tokens"); ok {
+		snapshot.LimitTokens, snapshot.RemainingTokens = limit, remaining
+		tokensReset = headerDelay(header.Get("x-ratelimit-reset-tokens"), now)
+		found = true
+	}
+
+	// Codex: usage percentages of the primary and secondary windows; the fuller one wins.
+	for _, window := range []string{"primary", "secondary"} {
+		raw := strings.TrimSpace(header.Get("x-codex-" + window + "-used-percent"))
+		used, err := strconv.ParseFloat(raw, 64)
+		if raw == "" || err != nil {
+			continue
+		}
+		found = true
+		if used < snapshot.UsedPercent {
+			continue
+		}
+		snapshot.UsedPercent = used
+		if seconds, errSec := strconv.ParseFloat(strings.TrimSpace(header.Get("x-codex-"+window+"-reset-after-seconds")), 64); errSec == nil && seconds >= 0 {
+			snapshot.ResetAt = now.Add(time.Duration(seconds * float64(time.Second)))
+		}
+	}
+	if !found {
+		return snapshot, false
+	}
+
+	if snapshot.ResetAt.IsZero() {
+		switch {
+		case requestsReset.IsZero():
+			snapshot.ResetAt = tokensReset
+		case tokensReset.IsZero():
+			snapshot.ResetAt = requestsReset
+		case remainingFraction(snapshot.RemainingRequests, snapshot.LimitRequests) <= remainingFraction(snapshot.RemainingTokens, snapshot.LimitTokens):
+			snapshot.ResetAt = requestsReset
+		default:
+			snapshot.ResetAt = tokensReset
+		}
+	}
+	return snapshot, true
+}
+
+func headerPair(header http.Header, limitKey, remainingKey string) (int64, int64, bool) {
+	remainingRaw := strings.TrimSpace(header.Get(remainingKey))
+	if remainingRaw == "" {
+		return 0, 0, false
+	}
+	remaining, err := strconv.ParseInt(remainingRaw, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	limit, _ := strconv.ParseInt(strings.TrimSpace(header.Get(limitKey)), 10, 64)
+	return limit, remaining, true
+}
+
+func headerTime(raw string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(raw))
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+func headerDelay(raw string, now time.Time) time.Time {
+	delay, err := time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil || delay < 0 {
+		return time.Time{}
+	}
+	return now.Add(delay)
+}
+
+func remainingFraction(remaining, limit int64) float64 {
+	if limit <= 0 {
+		return 1
+	}
+	return math.Max(0, math.Min(1, float64(remaining)/float64(limit)))
+}
+
+// Headroom returns the fraction, from 0 to 1, of the most constrained upstream limit still left
+// at now, and false when the snapshot reports no limit. A snapshot past its reset time has full
+// headroom.
+func (s *QuotaSnapshot) Headroom(now time.Time) (float64, bool) {
+	if s == nil {
+		return 1, false
+	}
+	known := s.LimitRequests > 0 || s.LimitTokens > 0 || s.UsedPercent > 0
+	if !s.ResetAt.IsZero() && !now.Before(s.ResetAt) {
+		return 1, known
+	}
+	headroom := math.Min(remainingFraction(s.RemainingRequests, s.LimitRequests), remainingFraction(s.RemainingTokens, s.LimitTokens))
+	if s.UsedPercent > 0 {
+		headroom = math.Min(headroom, math.Max(0, 1-s.UsedPercent/100))
+	}
+	return headroom, known
+}
+
+type quotaReportContextKey struct{}
+
+// quotaReport links an executed attempt to the auth whose quota headers it reports.
+type quotaReport struct {
+	manager *Manager
+	authID  string
+}
+
+// withQuotaReport attaches auth to ctx so ReportQuotaHeaders can update its quota snapshot.
+func (m *Manager) withQuotaReport(ctx context.Context, auth *Auth) context.Context {
+	if auth == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, quotaReportContextKey{}, &quotaReport{manager: m, authID: auth.ID})
+}
+
+// ReportQuotaHeaders records the remaining quota in the upstream response headers as the quota
+// snapshot of the auth executing the request in ctx. Executors call it for every upstream
+// response; headers without rate-limit information are ignored.
+func ReportQuotaHeaders(ctx context.Context, header http.Header) {
+	if ctx == nil {
+		return
+	}
+	report, ok := ctx.Value(quotaReportContextKey{}).(*quotaReport)
+	if !ok || report == nil || report.manager == nil {
+		return
+	}
+	snapshot, ok := ParseQuotaHeaders(header, time.Now())
+	if !ok {
+		return
+	}
+	report.manager.setQuotaSnapshot(report.authID, &snapshot)
+}
+
+// setQuotaSnapshot stores snapshot on the registered auth. The snapshot changes with every
+// response, so it is neither persisted nor announced to hooks on its own.
+func (m *Manager) setQuotaSnapshot(authID string, snapshot *QuotaSnapshot) {
+	m.mu.Lock()
+	if auth := m.auths[authID]; auth != nil {
+		auth.Quota.Snapshot = snapshot
+	}
+	m.mu.Unlock()
+}
+
+// HeadroomSelector picks the available credential with the most upstream quota headroom, as
+// reported by rate-limit response headers. Credentials without a snapshot count as having full
+// headroom; ties rotate round-robin.
+type HeadroomSelector struct {
+	mu      sync.Mutex
+	cursors map[string]int
+}
+
+// Pick implements Selector.
+func (s *HeadroomSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = opts
+	return s.pick(ctx, provider, model, auths, true)
+}
+
+// Peek implements SelectorPeeker.
+func (s *HeadroomSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = opts
+	return s.pick(ctx, provider, model, auths, false)
+}
+
+func (s *HeadroomSelector) pick(ctx context.Context, provider, model string, auths []*Auth, advance bool) (*Auth, error) {
+	now := time.Now()
+	available, err := getAvailableAuths(ctx, auths, provider, model, now)
+	if err != nil {
+		return nil, err
+	}
+	best := make([]*Auth, 0, len(available))
+	bestHeadroom := -1.0
+	for _, candidate := range available {
+		headroom, _ := candidate.Quota.Snapshot.Headroom(now)
+		switch {
+		case headroom > bestHeadroom:
+			bestHeadroom = headroom
+			best = append(best[:0], candidate)
+		case headroom == bestHeadroom:
+			best = append(best, candidate)
+		}
+	}
+	if len(best) == 1 {
+		return best[0], nil
+	}
+	key := provider + ":" + model
+	s.mu.Lock()
+	index := s.cursors[key]
+	if index >= 2_147_483_640 {
+		index = 0
+	}
+	if advance {
+		if s.cursors == nil {
+			s.cursors = make(map[string]int)
+		}
+		s.cursors[key] = index + 1
+	}
+	s.mu.Unlock()
+	return best[index%len(best)], nil
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestParseQuotaHeaders(t *testing.T) {
+	now := time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC)
+
+	anthropic := http.Header{}
+	anthropic.Set("anthropic-ratelimit-requests-limit", "100")
+	anthropic.Set("anthropic-ratelimit-requests-remaining", "90")
+	anthropic.Set("anthropic-ratelimit-requests-reset", "2026-10-12T12:01:00Z")
+	anthropic.Set("anthropic-ratelimit-tokens-limit", "1000")
+	anthropic.Set("anthropic-ratelimit-tokens-remaining", "250")
+	anthropic.Set("anthropic-ratelimit-tokens-reset", "2026-10-12T12:00:30Z")
+	snapshot, ok := ParseQuotaHeaders(anthropic, now)
+	if !ok || snapshot.RemainingRequests != 90 || snapshot.LimitTokens != 1000 || snapshot.RemainingTokens != 250 {
+		t.Fatalf("anthropic snapshot = %+v, %v", snapshot, ok)
+	}
+	if !snapshot.ResetAt.Equal(now.Add(30 * time.Second)) {
+		t.Fatalf("anthropic ResetAt = %s, want the tokens reset", snapshot.ResetAt)
+	}
+	if headroom, known := snapshot.Headroom(now); !known || headroom != 0.25 {
+		t.Fatalf("anthropic Headroom() = %v, %v; want 0.25, true", headroom, known)
+	}
+	if headroom, _ := snapshot.Headroom(now.Add(time.Minute)); headroom != 1 {
+		t.Fatalf("Headroom() after reset = %v, want 1", headroom)
+	}
+
+	openai := http.Header{}
+	openai.Set("x-ratelimit-limit-requests", "60")
+	openai.Set("x-ratelimit-remaining-requests", "6")
+	openai.Set("x-ratelimit-reset-requests", "6m0s")
+	snapshot, ok = ParseQuotaHeaders(openai, now)
+	if !ok || snapshot.LimitRequests != 60 || !snapshot.ResetAt.Equal(now.Add(6*time.Minute)) {
+		t.Fatalf("openai snapshot = %+v, %v", snapshot, ok)
+	}
+
+	codex := http.Header{}
+	codex.Set("x-codex-primary-used-percent", "40")
+	codex.Set("x-codex-primary-reset-after-seconds", "100")
+	codex.Set("x-codex-secondary-used-percent", "75.5")
+	codex.Set("x-codex-secondary-reset-after-seconds", "3600")
+	snapshot, ok = ParseQuotaHeaders(codex, now)
+	if !ok || snapshot.UsedPercent != 75.5 || !snapshot.ResetAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("codex snapshot = %+v, %v", snapshot, ok)
+	}
+
+	if _, ok = ParseQuotaHeaders(http.Header{"Content-Type": {"application/json"}}, now); ok {
+		t.Fatalf("expected no snapshot without rate-limit headers")
+	}
+}
+
+func TestManager_ReportQuotaHeadersFeedsHeadroomSelector(t *testing.T) {
+	m := NewManager(nil, &HeadroomSelector{}, nil)
+	auths := []*Auth{{ID: "headroom-a", Provider: "claude"}, {ID: "headroom-b", Provider: "claude"}}
+	for _, auth := range auths {
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("register auth %s: %v", auth.ID, errRegister)
+		}
+	}
+	header := http.Header{}
+	header.Set("x-ratelimit-limit-requests", "10")
+	header.Set("x-ratelimit-remaining-requests", "1")
+	ReportQuotaHeaders(m.withQuotaReport(context.Background(), auths[0]), header)
+
+	registered, _ := m.GetByID("headroom-a")
+	if registered == nil || registered.Quota.Snapshot == nil || registered.Quota.Snapshot.RemainingRequests != 1 {
+		t.Fatalf("expected quota snapshot on headroom-a, got %+v", registered)
+	}
+
+	m.mu.RLock()
+	candidates := []*Auth{m.auths["headroom-a"], m.auths["headroom-b"]}
+	m.mu.RUnlock()
+	for i := 0; i < 3; i++ {
+		selected, err := m.selector.Pick(context.Background(), "claude", "m", cliproxyexecutor.Options{}, candidates)
+		if err != nil {
+			t.Fatalf("Pick() error = %v", err)
+		}
+		if selected.ID != "headroom-b" {
+			t.Fatalf("Pick() = %s, want headroom-b with full headroom", selected.ID)
+		}
+	}
+}
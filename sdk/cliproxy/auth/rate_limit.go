@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func init() {
+	coreusage.RegisterPlugin(rateLimitUsagePlugin{})
+}
+
+// estimateRequestTokens approximates the prompt size of a request at four bytes per token.
+// The estimate is charged to the tokens-per-minute bucket at dispatch and corrected once the
+// upstream usage is known.
+func estimateRequestTokens(payload []byte) int64 {
+	if len(payload) == 0 {
+		return 0
+	}
+	return int64(len(payload)+3) / 4
+}
+
+// rateLimitsOf returns the requests-per-minute and tokens-per-minute limits of auth. The rpm and
+// tpm attributes of the credential take precedence over the rate-limits entry of its provider;
+// zero means unlimited.
+func (m *Manager) rateLimitsOf(auth *Auth) (rpm, tpm int) {
+	if auth == nil {
+		return 0, 0
+	}
+	if cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config); cfg != nil && len(cfg.RateLimits) > 0 {
+		if limit, ok := cfg.RateLimits[strings.ToLower(strings.TrimSpace(auth.Provider))]; ok {
+			rpm, tpm = limit.RPM, limit.TPM
+		}
+	}
+	if v, ok := intAttribute(auth, "rpm"); ok {
+		rpm = v
+	}
+	if v, ok := intAttribute(auth, "tpm"); ok {
+		tpm = v
+	}
+	return rpm, tpm
+}
+
+func intAttribute(auth *Auth, key string) (int, bool) {
+	if auth == nil || auth.Attributes == nil {
+		return 0, false
+	}
+	raw := strings.TrimSpace(auth.Attributes[key])
+	if raw == "" {
+		return 0, false
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed < 0 {
+		return 0, false
+	}
+	return parsed, true
+}
+
+// tokenBucket refills limit units per minute up to a burst of limit units.
+type tokenBucket struct {
+	limit float64
+	level float64
+	last  time.Time
+}
+
+func (b *tokenBucket) refill(limit int, now time.Time) {
+	capacity := float64(limit)
+	if b.last.IsZero() || b.limit == 0 {
+		b.level = capacity
+	} else if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.level += elapsed.Minutes() * capacity
+	}
+	b.level = math.Min(b.level, capacity)
+	b.limit = capacity
+	b.last = now
+}
+
+// wait returns how long until cost units are available. Requests larger than the whole bucket
+// are admitted once the bucket is full so they are delayed rather than rejected forever.
+func (b *tokenBucket) wait(cost float64) time.Duration {
+	need := math.Min(cost, b.limit) - b.level
+	if need <= 0 {
+		return 0
+	}
+	return time.Duration(need / b.limit * float64(time.Minute))
+}
+
+type rateBuckets struct {
+	requests tokenBucket
+	tokens   tokenBucket
+}
+
+// rateLimiter keeps the requests-per-minute and tokens-per-minute buckets of each credential.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*rateBuckets
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: make(map[string]*rateBuckets)}
+}
+
+func (l *rateLimiter) bucketsLocked(authID string, rpm, tpm int, now time.Time) *rateBuckets {
+	buckets := l.buckets[authID]
+	if buckets == nil {
+		buckets = &rateBuckets{}
+		l.buckets[authID] = buckets
+	}
+	if rpm > 0 {
+		buckets.requests.refill(rpm, now)
+	}
+	if tpm > 0 {
+		buckets.tokens.refill(tpm, now)
+	}
+	return buckets
+}
+
+// wait returns how long auth must wait before it can accept a request of estimate tokens.
+func (l *rateLimiter) wait(authID string, rpm, tpm int, estimate int64, now time.Time) time.Duration {
+	if rpm <= 0 && tpm <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return waitLocked(l.bucketsLocked(authID, rpm, tpm, now), rpm, tpm, estimate)
+}
+
+// tryConsume charges one request and estimate tokens to auth when both buckets allow it,
+// otherwise it reports how long to wait.
+func (l *rateLimiter) tryConsume(authID string, rpm, tpm int, estimate int64, now time.Time) (time.Duration, bool) {
+	if rpm <= 0 && tpm <= 0 {
+		return 0, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	buckets := l.bucketsLocked(authID, rpm, tpm, now)
+	if wait := waitLocked(buckets, rpm, tpm, estimate); wait > 0 {
+		return wait, false
+	}
+	if rpm > 0 {
+		buckets.requests.level--
+	}
+	if tpm > 0 {
+		buckets.tokens.level -= float64(estimate)
+	}
+	return 0, true
+}
+
+func waitLocked(buckets *rateBuckets, rpm, tpm int, estimate int64) time.Duration {
+	var wait time.Duration
+	if rpm > 0 {
+		wait = buckets.requests.wait(1)
+	}
+	if tpm > 0 {
+		if tokenWait := buckets.tokens.wait(float64(estimate)); tokenWait > wait {
+			wait = tokenWait
+		}
+	}
+	return wait
+}
+
+// settle corrects the tokens bucket of authID once the actual usage of a request is known.
+// The level may go negative, which delays later requests until the overdraft is refilled.
+func (l *rateLimiter) settle(authID string, estimate, actual int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if buckets := l.buckets[authID]; buckets != nil && buckets.tokens.limit > 0 {
+		buckets.tokens.level -= float64(actual - estimate)
+		buckets.tokens.level = math.Min(buckets.tokens.level, buckets.tokens.limit)
+	}
+}
+
+// rateReservation links an executed attempt to the tokens charged for it at dispatch.
+type rateReservation struct {
+	limiter  *rateLimiter
+	authID   string
+	estimate int64
+	once     sync.Once
+}
+
+type rateReservationContextKey struct{}
+
+// withRateReservation attaches the reservation of auth to ctx so the usage record emitted by the
+// executor settles the estimate with the actual token count.
+func (m *Manager) withRateReservation(ctx context.Context, auth *Auth, estimate int64) context.Context {
+	if _, tpm := m.rateLimitsOf(auth); tpm <= 0 {
+		return ctx
+	}
+	reservation := &rateReservation{limiter: m.rateLimits, authID: auth.ID, estimate: estimate}
+	return context.WithValue(ctx, rateReservationContextKey{}, reservation)
+}
+
+// rateLimitUsagePlugin settles token reservations from usage records.
+type rateLimitUsagePlugin struct{}
+
+// HandleUsage implements coreusage.Plugin.
+func (rateLimitUsagePlugin) HandleUsage(ctx context.Context, record coreusage.Record) {
+	if ctx == nil {
+		return
+	}
+	reservation, ok := ctx.Value(rateReservationContextKey{}).(*rateReservation)
+	if !ok || reservation == nil || reservation.authID != record.AuthID {
+		return
+	}
+	reservation.once.Do(func() {
+		actual := record.Detail.TotalTokens
+		if actual <= 0 {
+			actual = record.Detail.InputTokens + record.Detail.OutputTokens + record.Detail.ReasoningTokens
+		}
+		reservation.limiter.settle(reservation.authID, reservation.estimate, actual)
+	})
+}
+
+// rateLimitedError reports that every candidate is over its RPM/TPM limits. RetryIn is the
+// earliest time one of them can accept the request.
+type rateLimitedError struct {
+	cause   *Error
+	retryIn time.Duration
+}
+
+func newRateLimitedError(retryIn time.Duration) *rateLimitedError {
+	return &rateLimitedError{
+		cause: &Error{
+			Code:       "auth_rate_limited",
+			Message:    fmt.Sprintf("all credentials are at their rate limits, retry in %s", retryIn.Round(time.Second)),
+			Retryable:  true,
+			HTTPStatus: http.StatusTooManyRequests,
+		},
+		retryIn: retryIn,
+	}
+}
+
+func (e *rateLimitedError) Error() string { return e.cause.Error() }
+
+func (e *rateLimitedError) Unwrap() error { return e.cause }
+
+func (e *rateLimitedError) StatusCode() int { return http.StatusTooManyRequests }
+
+func (e *rateLimitedError) Headers() http.Header {
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	headers.Set("Retry-After", strconv.Itoa(int(math.Ceil(e.retryIn.Seconds()))))
+	return headers
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+func TestRateLimiter_TokenBucket(t *testing.T) {
+	t.Parallel()
+
+	limiter := newRateLimiter()
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, ok := limiter.tryConsume("a", 2, 0, 0, now); !ok {
+			t.Fatalf("request %d rejected within rpm budget", i+1)
+		}
+	}
+	wait, ok := limiter.tryConsume("a", 2, 0, 0, now)
+	if ok {
+		t.Fatalf("expected third request to exceed rpm 2")
+	}
+	if wait <= 0 || wait > 30*time.Second {
+		t.Fatalf("wait = %v, want (0, 30s]", wait)
+	}
+	if _, ok = limiter.tryConsume("a", 2, 0, 0, now.Add(30*time.Second)); !ok {
+		t.Fatalf("expected bucket to refill one request after 30s")
+	}
+
+	// Under-estimated requests overdraw the tokens bucket once the actual usage is settled.
+	if _, ok = limiter.tryConsume("b", 0, 1000, 100, now); !ok {
+		t.Fatalf("expected request within tpm budget")
+	}
+	limiter.settle("b", 100, 1900)
+	if wait = limiter.wait("b", 0, 1000, 100, now); wait < time.Minute {
+		t.Fatalf("wait after overdraft = %v, want >= 1m", wait)
+	}
+}
+
+func TestRateLimitUsagePlugin_SettlesReservation(t *testing.T) {
+	m := NewManager(nil, nil, nil)
+	auth := &Auth{ID: "rl-settle", Provider: "claude", Attributes: map[string]string{"tpm": "1000"}}
+	now := time.Now()
+	if _, ok := m.rateLimits.tryConsume(auth.ID, 0, 1000, 800, now); !ok {
+		t.Fatalf("expected reservation within tpm budget")
+	}
+	ctx := m.withRateReservation(context.Background(), auth, 800)
+	rateLimitUsagePlugin{}.HandleUsage(ctx, coreusage.Record{AuthID: auth.ID, Detail: coreusage.Detail{TotalTokens: 200}})
+	if wait := m.rateLimits.wait(auth.ID, 0, 1000, 800, now); wait != 0 {
+		t.Fatalf("expected settled usage to free the over-estimate, wait = %v", wait)
+	}
+}
+
+func TestManager_Execute_RateLimitRoutesAroundExhaustedCredential(t *testing.T) {
+	const model = "rate-limit-test-model"
+
+	executor := &recordingExecutor{provider: "claude"}
+	m := NewManager(nil, &FillFirstSelector{}, nil)
+	m.RegisterExecutor(executor)
+	m.SetConfig(&internalconfig.Config{RateLimits: map[string]internalconfig.RateLimit{"claude": {RPM: 1}}})
+
+	for _, id := range []string{"rl-auth-a", "rl-auth-b"} {
+		auth := &Auth{ID: id, Provider: "claude", Status: StatusActive}
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("register auth %s: %v", id, errRegister)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "claude", []*registry.ModelInfo{{ID: model}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, errExec := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{}); errExec != nil {
+			t.Fatalf("Execute() #%d error = %v", i+1, errExec)
+		}
+	}
+	if got := executor.AuthCalls(); len(got) != 2 || got[0] == got[1] {
+		t.Fatalf("expected requests to be spread over both credentials, got %v", got)
+	}
+
+	// With every credential exhausted and queueing disabled the request fails without dispatch.
+	m.SetConfig(&internalconfig.Config{
+		RateLimits: map[string]internalconfig.RateLimit{"claude": {RPM: 1}},
+		Routing:    internalconfig.RoutingConfig{ConcurrencyQueueSize: -1},
+	})
+	_, errExec := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{})
+	if wait, ok := admissionWait(errExec); !ok || wait <= 0 {
+		t.Fatalf("expected rate limited error with retry hint, got %v", errExec)
+	}
+	if got := executor.AuthCalls(); len(got) != 2 {
+		t.Fatalf("expected no dispatch while rate limited, got %v", got)
+	}
+	for _, id := range []string{"rl-auth-a", "rl-auth-b"} {
+		if auth, ok := m.GetByID(id); !ok || auth.Unavailable {
+			t.Fatalf("expected %s to stay out of cooldown", id)
+		}
+	}
+}
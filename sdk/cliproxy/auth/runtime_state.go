@@ -0,0 +1,309 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runtimeStateVersion is bumped when the snapshot layout changes incompatibly.
+const runtimeStateVersion = 1
+
+// runtimeSnapshot is the persisted runtime state of all auths, keyed by auth ID.
+type runtimeSnapshot struct {
+	Version int                          `json:"version"`
+	Auths   map[string]*authRuntimeState `json:"auths"`
+	// Extensions holds the state of registered RuntimeStateExtensions, keyed by name.
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+// RuntimeStateExtension is state kept outside the manager, such as client key consumption, that is
+// saved and restored together with the runtime state of auths.
+type RuntimeStateExtension interface {
+	// CaptureRuntimeState returns the state to save, or nil when there is nothing to keep.
+	CaptureRuntimeState() (json.RawMessage, error)
+	// RestoreRuntimeState merges previously captured state.
+	RestoreRuntimeState(data json.RawMessage) error
+}
+
+// RegisterRuntimeStateExtension saves ext under name with the runtime state. State already loaded
+// for name is restored immediately; otherwise it is restored when Load reads the snapshot.
+func (m *Manager) RegisterRuntimeStateExtension(name string, ext RuntimeStateExtension) {
+	if m == nil || name == "" || ext == nil {
+		return
+	}
+	m.runtimeMu.Lock()
+	defer m.runtimeMu.Unlock()
+	if m.runtimeExtensions == nil {
+		m.runtimeExtensions = make(map[string]RuntimeStateExtension)
+	}
+	m.runtimeExtensions[name] = ext
+	if data, ok := m.pendingExtensions[name]; ok {
+		delete(m.pendingExtensions, name)
+		restoreRuntimeExtension(name, ext, data)
+	}
+}
+
+func restoreRuntimeExtension(name string, ext RuntimeStateExtension, data json.RawMessage) {
+	if err := ext.RestoreRuntimeState(data); err != nil {
+		log.Warnf("failed to restore %s runtime state: %v", name, err)
+	}
+}
+
+// authRuntimeState holds the cooldown and quota state of one auth that must survive a restart.
+type authRuntimeState struct {
+	Status         Status                 `json:"status,omitempty"`
+	StatusMessage  string                 `json:"status_message,omitempty"`
+	Unavailable    bool                   `json:"unavailable,omitempty"`
+	NextRetryAfter time.Time              `json:"next_retry_after,omitempty"`
+	Quota          QuotaState             `json:"quota"`
+	ModelStates    map[string]*ModelState `json:"model_states,omitempty"`
+}
+
+// modelStateWorthKeeping reports whether state still carries a cooldown, error or quota backoff.
+func modelStateWorthKeeping(state *ModelState) bool {
+	if state == nil {
+		return false
+	}
+	return state.Unavailable || state.Status == StatusError || state.Quota.Exceeded || state.Quota.BackoffLevel > 0
+}
+
+// captureRuntimeState returns the runtime state of auth, or nil when it has nothing to keep.
+func captureRuntimeState(auth *Auth) *authRuntimeState {
+	if auth == nil || auth.Disabled {
+		return nil
+	}
+	state := &authRuntimeState{}
+	for model, modelState := range auth.ModelStates {
+		if !modelStateWorthKeeping(modelState) {
+			continue
+		}
+		if state.ModelStates == nil {
+			state.ModelStates = make(map[string]*ModelState)
+		}
+		copied := *modelState
+		copied.LastError = cloneError(modelState.LastError)
+		state.ModelStates[model] = &copied
+	}
+	authLevel := auth.Unavailable || auth.Quota.Exceeded || auth.Quota.BackoffLevel > 0
+	if !authLevel && len(state.ModelStates) == 0 {
+		return nil
+	}
+	if auth.Status == StatusError {
+		state.Status = auth.Status
+		state.StatusMessage = auth.StatusMessage
+	}
+	state.Unavailable = auth.Unavailable
+	state.NextRetryAfter = auth.NextRetryAfter
+	state.Quota = auth.Quota
+	return state
+}
+
+// hasRuntimeState reports whether auth already carries runtime state of its own.
+func hasRuntimeState(auth *Auth) bool {
+	if auth.Unavailable || auth.Quota.Exceeded || auth.Quota.BackoffLevel > 0 {
+		return true
+	}
+	for _, state := range auth.ModelStates {
+		if modelStateWorthKeeping(state) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRuntimeState restores state onto auth unless auth is disabled or has state of its own.
+func applyRuntimeState(auth *Auth, state *authRuntimeState) {
+	if auth == nil || state == nil || auth.Disabled || hasRuntimeState(auth) {
+		return
+	}
+	if state.Status == StatusError {
+		auth.Status = StatusError
+		auth.StatusMessage = state.StatusMessage
+	}
+	auth.Unavailable = state.Unavailable
+	auth.NextRetryAfter = state.NextRetryAfter
+	auth.Quota = state.Quota
+	if len(state.ModelStates) > 0 {
+		if auth.ModelStates == nil {
+			auth.ModelStates = make(map[string]*ModelState, len(state.ModelStates))
+		}
+		for model, modelState := range state.ModelStates {
+			if modelState == nil {
+				continue
+			}
+			copied := *modelState
+			copied.LastError = cloneError(modelState.LastError)
+			auth.ModelStates[model] = &copied
+		}
+	}
+}
+
+// loadRuntimeStateLocked reads the saved snapshot from the store and restores it onto the loaded
+// auths. The entries are also kept until each auth is next registered or updated, so credentials
+// re-synthesized from files and config after startup get their state back too.
+func (m *Manager) loadRuntimeStateLocked(ctx context.Context) {
+	stateStore, ok := m.store.(RuntimeStateStore)
+	if !ok {
+		return
+	}
+	data, err := stateStore.LoadRuntimeState(ctx)
+	if err != nil {
+		log.Warnf("failed to load auth runtime state: %v", err)
+		return
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return
+	}
+	var snapshot runtimeSnapshot
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		log.Warnf("failed to parse auth runtime state: %v", err)
+		return
+	}
+	if snapshot.Version != runtimeStateVersion {
+		log.Warnf("ignoring auth runtime state with unsupported version %d", snapshot.Version)
+		return
+	}
+	m.pendingRuntime = snapshot.Auths
+	for id, auth := range m.auths {
+		applyRuntimeState(auth, snapshot.Auths[id])
+	}
+	m.runtimeMu.Lock()
+	m.runtimeSaved = data
+	for name, raw := range snapshot.Extensions {
+		if ext, ok := m.runtimeExtensions[name]; ok {
+			restoreRuntimeExtension(name, ext, raw)
+			continue
+		}
+		// Kept for an extension registered later, and saved again until then.
+		if m.pendingExtensions == nil {
+			m.pendingExtensions = make(map[string]json.RawMessage)
+		}
+		m.pendingExtensions[name] = raw
+	}
+	m.runtimeMu.Unlock()
+}
+
+// restorePendingRuntimeLocked hands a saved runtime state to auth the first time it is
+// registered or updated after startup.
+func (m *Manager) restorePendingRuntimeLocked(auth *Auth) {
+	if state, ok := m.pendingRuntime[auth.ID]; ok {
+		applyRuntimeState(auth, state)
+		delete(m.pendingRuntime, auth.ID)
+	}
+}
+
+// SaveRuntimeState writes the cooldown and quota state of all auths, and the state of registered
+// extensions, to the store when it supports runtime state. Unchanged snapshots are not written again.
+func (m *Manager) SaveRuntimeState(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	stateStore, ok := m.store.(RuntimeStateStore)
+	if !ok {
+		m.mu.RUnlock()
+		return nil
+	}
+	snapshot := runtimeSnapshot{Version: runtimeStateVersion, Auths: make(map[string]*authRuntimeState)}
+	for id, auth := range m.auths {
+		if state := captureRuntimeState(auth); state != nil {
+			snapshot.Auths[id] = state
+		}
+	}
+	for id, state := range m.pendingRuntime {
+		if _, exists := snapshot.Auths[id]; !exists && m.auths[id] == nil {
+			snapshot.Auths[id] = state
+		}
+	}
+	m.mu.RUnlock()
+
+	m.runtimeMu.Lock()
+	defer m.runtimeMu.Unlock()
+	for name, raw := range m.pendingExtensions {
+		if snapshot.Extensions == nil {
+			snapshot.Extensions = make(map[string]json.RawMessage)
+		}
+		snapshot.Extensions[name] = raw
+	}
+	for name, ext := range m.runtimeExtensions {
+		raw, errCapture := ext.CaptureRuntimeState()
+		if errCapture != nil {
+			return fmt.Errorf("auth runtime state: capture %s failed: %w", name, errCapture)
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		if snapshot.Extensions == nil {
+			snapshot.Extensions = make(map[string]json.RawMessage)
+		}
+		snapshot.Extensions[name] = raw
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("auth runtime state: marshal failed: %w", err)
+	}
+	if bytes.Equal(data, m.runtimeSaved) {
+		return nil
+	}
+	if err = stateStore.SaveRuntimeState(ctx, data); err != nil {
+		return err
+	}
+	m.runtimeSaved = data
+	return nil
+}
+
+// FlushRuntimeState saves the runtime state and asks stores that batch saves to persist it
+// immediately. It is called on shutdown.
+func (m *Manager) FlushRuntimeState(ctx context.Context) error {
+	if err := m.SaveRuntimeState(ctx); err != nil {
+		return err
+	}
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	flusher, ok := m.store.(RuntimeStateFlusher)
+	m.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return flusher.FlushRuntimeState(ctx)
+}
+
+// StartRuntimeStateSync saves the runtime state every interval until StopRuntimeStateSync is
+// called. Starting a new loop cancels the previous one.
+func (m *Manager) StartRuntimeStateSync(parent context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	m.StopRuntimeStateSync()
+	ctx, cancel := context.WithCancel(parent)
+	m.runtimeCancel = cancel
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.SaveRuntimeState(ctx); err != nil {
+					log.Warnf("failed to save auth runtime state: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// StopRuntimeStateSync cancels the background runtime state loop, if running.
+func (m *Manager) StopRuntimeStateSync() {
+	if m.runtimeCancel != nil {
+		m.runtimeCancel()
+		m.runtimeCancel = nil
+	}
+}
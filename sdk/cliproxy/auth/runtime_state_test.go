@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// runtimeStateStore keeps auths and the runtime state snapshot in memory.
+type runtimeStateStore struct {
+	auths  []*Auth
+	state  []byte
+	writes int
+}
+
+func (s *runtimeStateStore) List(context.Context) ([]*Auth, error) { return s.auths, nil }
+
+func (s *runtimeStateStore) Save(context.Context, *Auth) (string, error) { return "", nil }
+
+func (s *runtimeStateStore) Delete(context.Context, string) error { return nil }
+
+func (s *runtimeStateStore) LoadRuntimeState(context.Context) ([]byte, error) { return s.state, nil }
+
+func (s *runtimeStateStore) SaveRuntimeState(_ context.Context, data []byte) error {
+	s.state = append([]byte(nil), data...)
+	s.writes++
+	return nil
+}
+
+func TestManager_RuntimeStateSurvivesRestart(t *testing.T) {
+	const model = "runtime-state-model"
+
+	store := &runtimeStateStore{auths: []*Auth{
+		{ID: "rs-cooling", Provider: "claude", Status: StatusActive},
+		{ID: "rs-healthy", Provider: "claude", Status: StatusActive},
+	}}
+	before := NewManager(store, nil, nil)
+	if err := before.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	before.MarkResult(context.Background(), Result{AuthID: "rs-cooling", Provider: "claude", Model: model, Error: &Error{Message: "quota", HTTPStatus: 429}})
+	before.MarkResult(context.Background(), Result{AuthID: "rs-healthy", Provider: "claude", Model: model, Success: true})
+	if err := before.SaveRuntimeState(context.Background()); err != nil {
+		t.Fatalf("SaveRuntimeState() error = %v", err)
+	}
+	if err := before.SaveRuntimeState(context.Background()); err != nil || store.writes != 1 {
+		t.Fatalf("unchanged snapshot rewritten: writes = %d, err = %v", store.writes, err)
+	}
+
+	after := NewManager(store, nil, nil)
+	if err := after.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	restored, _ := after.GetByID("rs-cooling")
+	state := restored.ModelStates[model]
+	if state == nil || !state.Quota.Exceeded || state.Quota.BackoffLevel != 1 || !state.NextRetryAfter.After(time.Now()) {
+		t.Fatalf("restored model state = %+v, want an exceeded quota with backoff level 1", state)
+	}
+	if healthy, _ := after.GetByID("rs-healthy"); len(healthy.ModelStates) != 0 {
+		t.Fatalf("healthy auth restored model states %v, want none", healthy.ModelStates)
+	}
+
+	// Credentials re-synthesized after startup get the saved state back on their first update.
+	if _, err := after.Update(context.Background(), &Auth{ID: "rs-cooling", Provider: "claude", Status: StatusActive}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	updated, _ := after.GetByID("rs-cooling")
+	if updated.ModelStates[model] == nil || !updated.ModelStates[model].Quota.Exceeded {
+		t.Fatalf("model state lost after re-synthesized update: %+v", updated.ModelStates)
+	}
+}
+
+// batchingRuntimeStateStore counts flushes like a store that commits snapshots only periodically.
+type batchingRuntimeStateStore struct {
+	runtimeStateStore
+	flushes int
+}
+
+func (s *batchingRuntimeStateStore) FlushRuntimeState(context.Context) error {
+	s.flushes++
+	return nil
+}
+
+func TestManager_FlushRuntimeStateFlushesBatchingStores(t *testing.T) {
+	store := &batchingRuntimeStateStore{runtimeStateStore: runtimeStateStore{auths: []*Auth{
+		{ID: "rs-flush", Provider: "claude", Status: StatusActive},
+	}}}
+	m := NewManager(store, nil, nil)
+	if err := m.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	m.MarkResult(context.Background(), Result{AuthID: "rs-flush", Provider: "claude", Model: "flush-model", Error: &Error{Message: "quota", HTTPStatus: 429}})
+	if err := m.SaveRuntimeState(context.Background()); err != nil || store.flushes != 0 {
+		t.Fatalf("periodic save flushed: flushes = %d, err = %v", store.flushes, err)
+	}
+	if err := m.FlushRuntimeState(context.Background()); err != nil {
+		t.Fatalf("FlushRuntimeState() error = %v", err)
+	}
+	if store.writes != 1 || store.flushes != 1 {
+		t.Fatalf("writes = %d, flushes = %d, want 1 and 1", store.writes, store.flushes)
+	}
+}
+
+// counterExtension is a runtime state extension holding a single counter.
+type counterExtension struct{ count int }
+
+func (e *counterExtension) CaptureRuntimeState() (json.RawMessage, error) {
+	if e.count == 0 {
+		return nil, nil
+	}
+	return json.Marshal(e.count)
+}
+
+func (e *counterExtension) RestoreRuntimeState(data json.RawMessage) error {
+	var count int
+	if err := json.Unmarshal(data, &count); err != nil {
+		return err
+	}
+	e.count += count
+	return nil
+}
+
+func TestManager_RuntimeStateExtensionsSurviveRestart(t *testing.T) {
+	store := &runtimeStateStore{}
+	before := NewManager(store, nil, nil)
+	counter := &counterExtension{count: 7}
+	before.RegisterRuntimeStateExtension("counter", counter)
+	if err := before.SaveRuntimeState(context.Background()); err != nil {
+		t.Fatalf("SaveRuntimeState() error = %v", err)
+	}
+
+	// State loaded before the extension registers is handed over on registration.
+	after := NewManager(store, nil, nil)
+	if err := after.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	restored := &counterExtension{}
+	after.RegisterRuntimeStateExtension("counter", restored)
+	if restored.count != 7 {
+		t.Fatalf("restored count = %d, want 7", restored.count)
+	}
+
+	// Unclaimed state is saved again rather than dropped.
+	unclaimed := NewManager(store, nil, nil)
+	if err := unclaimed.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	store.state = nil
+	unclaimed.runtimeSaved = nil
+	if err := unclaimed.SaveRuntimeState(context.Background()); err != nil {
+		t.Fatalf("SaveRuntimeState() error = %v", err)
+	}
+	var snapshot runtimeSnapshot
+	if err := json.Unmarshal(store.state, &snapshot); err != nil || string(snapshot.Extensions["counter"]) != "7" {
+		t.Fatalf("saved extensions = %s (err=%v), want counter 7", snapshot.Extensions, err)
+	}
+}
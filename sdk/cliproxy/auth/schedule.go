@@ -0,0 +1,292 @@
+package auth
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// activeWindowsSeparator joins the windows of a schedule in the "active_windows" attribute;
+// commas are taken by day lists.
+const activeWindowsSeparator = ";"
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// activeWindow is one recurring time range. Windows whose end is not after their start run past
+// midnight into the next day; days refer to the day a window starts.
+type activeWindow struct {
+	days  [7]bool
+	start int // minutes after midnight
+	end   int // minutes after midnight, up to 24*60
+	loc   *time.Location
+}
+
+// ActiveSchedule is a set of recurring windows during which a credential may serve requests.
+type ActiveSchedule struct {
+	windows []activeWindow
+}
+
+// ParseActiveWindows parses windows of the form "[days] [HH:MM-HH:MM] [timezone]", for example
+// "mon-fri 18:00-08:00 Europe/Berlin", "sat,sun" or "00:00-06:00 UTC". Days default to every day,
+// the time range to the whole day and the timezone to the local one. An empty list yields nil.
+func ParseActiveWindows(windows []string) (*ActiveSchedule, error) {
+	schedule := &ActiveSchedule{}
+	for _, raw := range windows {
+		for _, part := range strings.Split(raw, activeWindowsSeparator) {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			window, err := parseActiveWindow(part)
+			if err != nil {
+				return nil, err
+			}
+			schedule.windows = append(schedule.windows, window)
+		}
+	}
+	if len(schedule.windows) == 0 {
+		return nil, nil
+	}
+	return schedule, nil
+}
+
+func parseActiveWindow(raw string) (activeWindow, error) {
+	window := activeWindow{end: 24 * 60, loc: time.Local}
+	fields := strings.Fields(raw)
+	var daysSet, timeSet, locSet bool
+	for _, field := range fields {
+		switch {
+		case !timeSet && strings.Contains(field, ":"):
+			start, end, err := parseTimeRange(field)
+			if err != nil {
+				return activeWindow{}, fmt.Errorf("active window %q: %w", raw, err)
+			}
+			window.start, window.end = start, end
+			timeSet = true
+		case !daysSet && !timeSet && !locSet:
+			days, ok := parseDays(field)
+			if !ok {
+				if loc, err := time.LoadLocation(field); err == nil {
+					window.loc = loc
+					locSet = true
+					continue
+				}
+				return activeWindow{}, fmt.Errorf("active window %q: invalid days %q", raw, field)
+			}
+			window.days = days
+			daysSet = true
+		case !locSet:
+			loc, err := time.LoadLocation(field)
+			if err != nil {
+				return activeWindow{}, fmt.Errorf("active window %q: invalid timezone %q", raw, field)
+			}
+			window.loc = loc
+			locSet = true
+		default:
+			return activeWindow{}, fmt.Errorf("active window %q: unexpected %q", raw, field)
+		}
+	}
+	if !daysSet {
+		for i := range window.days {
+			window.days[i] = true
+		}
+	}
+	return window, nil
+}
+
+// parseDays parses "daily", "*", single days, ranges such as "mon-fri" or "fri-mon", and
+// comma-separated lists of those.
+func parseDays(raw string) ([7]bool, bool) {
+	var days [7]bool
+	raw = strings.ToLower(raw)
+	if raw == "daily" || raw == "*" {
+		for i := range days {
+			days[i] = true
+		}
+		return days, true
+	}
+	for _, item := range strings.Split(raw, ",") {
+		from, to, isRange := strings.Cut(item, "-")
+		first, ok := weekdayNames[from]
+		if !ok {
+			return days, false
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdayNames[to]; !ok {
+				return days, false
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return days, true
+}
+
+// parseTimeRange parses "HH:MM-HH:MM" into minutes after midnight; the end may be 24:00.
+func parseTimeRange(raw string) (int, int, error) {
+	from, to, ok := strings.Cut(raw, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid time range %q", raw)
+	}
+	start, errStart := parseClock(from)
+	end, errEnd := parseClock(to)
+	if errStart != nil || errEnd != nil || start == 24*60 {
+		return 0, 0, fmt.Errorf("invalid time range %q", raw)
+	}
+	return start, end, nil
+}
+
+func parseClock(raw string) (int, error) {
+	hours, minutes, ok := strings.Cut(raw, ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q", raw)
+	}
+	h, errH := strconv.Atoi(hours)
+	mins, errM := strconv.Atoi(minutes)
+	if errH != nil || errM != nil || h < 0 || mins < 0 || mins > 59 || h > 24 || (h == 24 && mins != 0) {
+		return 0, fmt.Errorf("invalid time %q", raw)
+	}
+	return h*60 + mins, nil
+}
+
+// bounds returns the occurrence of w starting on the day of dayStart, if w runs that day.
+func (w activeWindow) bounds(dayStart time.Time) (time.Time, time.Time, bool) {
+	if !w.days[dayStart.Weekday()] {
+		return time.Time{}, time.Time{}, false
+	}
+	y, mo, d := dayStart.Date()
+	start := time.Date(y, mo, d, w.start/60, w.start%60, 0, 0, w.loc)
+	endDay := d
+	if w.end <= w.start {
+		endDay++
+	}
+	end := time.Date(y, mo, endDay, w.end/60, w.end%60, 0, 0, w.loc)
+	return start, end, true
+}
+
+// Active reports whether now falls inside one of the windows.
+func (s *ActiveSchedule) Active(now time.Time) bool {
+	if s == nil {
+		return true
+	}
+	for _, w := range s.windows {
+		local := now.In(w.loc)
+		// An overnight window that started yesterday may still be running.
+		for _, offset := range []int{0, -1} {
+			start, end, ok := w.bounds(local.AddDate(0, 0, offset))
+			if ok && !now.Before(start) && now.Before(end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NextActivation returns the earliest start of a window after now, or the zero time when the
+// schedule is active now or has no window.
+func (s *ActiveSchedule) NextActivation(now time.Time) time.Time {
+	if s == nil || s.Active(now) {
+		return time.Time{}
+	}
+	var next time.Time
+	for _, w := range s.windows {
+		local := now.In(w.loc)
+		for offset := 0; offset <= 7; offset++ {
+			start, _, ok := w.bounds(local.AddDate(0, 0, offset))
+			if !ok || !start.After(now) {
+				continue
+			}
+			if next.IsZero() || start.Before(next) {
+				next = start
+			}
+			break
+		}
+	}
+	return next
+}
+
+// String renders the schedule in the form stored in the "active_windows" attribute.
+func (s *ActiveSchedule) String() string {
+	if s == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(s.windows))
+	for _, w := range s.windows {
+		parts = append(parts, w.String())
+	}
+	return strings.Join(parts, activeWindowsSeparator)
+}
+
+func (w activeWindow) String() string {
+	names := [7]string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+	var days []string
+	for day, on := range w.days {
+		if on {
+			days = append(days, names[day])
+		}
+	}
+	daysPart := strings.Join(days, ",")
+	if len(days) == 7 {
+		daysPart = "daily"
+	}
+	return fmt.Sprintf("%s %02d:%02d-%02d:%02d %s", daysPart, w.start/60, w.start%60, w.end/60, w.end%60, w.loc.String())
+}
+
+// activeSchedules caches parsed "active_windows" attributes; they are checked on every pick.
+var activeSchedules sync.Map // map[string]parsedActiveSchedule
+
+// parsedActiveSchedule is a cached "active_windows" attribute and its parse error.
+type parsedActiveSchedule struct {
+	schedule *ActiveSchedule
+	err      error
+}
+
+// authActiveSchedule returns the schedule in the "active_windows" attribute of auth, nil when it
+// has none, or the error of an attribute that does not parse.
+func authActiveSchedule(auth *Auth) (*ActiveSchedule, error) {
+	if auth == nil || auth.Attributes == nil {
+		return nil, nil
+	}
+	raw := strings.TrimSpace(auth.Attributes["active_windows"])
+	if raw == "" {
+		return nil, nil
+	}
+	if cached, ok := activeSchedules.Load(raw); ok {
+		parsed := cached.(parsedActiveSchedule)
+		return parsed.schedule, parsed.err
+	}
+	schedule, err := ParseActiveWindows([]string{raw})
+	activeSchedules.Store(raw, parsedActiveSchedule{schedule: schedule, err: err})
+	return schedule, err
+}
+
+// ActiveWindowsError returns the parse error of the active windows of the auth, or nil when they
+// are valid or absent. Auths with invalid active windows are never active.
+func (a *Auth) ActiveWindowsError() error {
+	_, err := authActiveSchedule(a)
+	return err
+}
+
+// NextActivation returns when the active windows of the auth next open, and true while the auth
+// is outside its windows. Auths without active windows are always active; auths whose windows do
+// not parse are inactive with no next activation, so a typo never lifts the restriction.
+func (a *Auth) NextActivation(now time.Time) (time.Time, bool) {
+	schedule, err := authActiveSchedule(a)
+	if err != nil {
+		return time.Time{}, true
+	}
+	if schedule == nil || schedule.Active(now) {
+		return time.Time{}, false
+	}
+	return schedule.NextActivation(now), true
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActiveSchedule_OvernightWeekdays(t *testing.T) {
+	schedule, err := ParseActiveWindows([]string{"mon-fri 18:00-08:00 UTC", "sat,sun"})
+	if err != nil {
+		t.Fatalf("ParseActiveWindows() error = %v", err)
+	}
+	// 2026-10-12 is a Monday.
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC) }
+	cases := []struct {
+		now    time.Time
+		active bool
+		next   time.Time
+	}{
+		{now: at(12, 12, 0), active: false, next: at(12, 18, 0)},
+		{now: at(12, 18, 0), active: true},
+		{now: at(13, 7, 59), active: true},
+		{now: at(13, 8, 0), active: false, next: at(13, 18, 0)},
+		{now: at(17, 3, 0), active: true},                       // Saturday, whole day and Friday's overnight window
+		{now: at(19, 7, 0), active: false, next: at(19, 18, 0)}, // Monday morning: Sunday has no overnight window
+	}
+	for _, tc := range cases {
+		if got := schedule.Active(tc.now); got != tc.active {
+			t.Errorf("Active(%s) = %v, want %v", tc.now, got, tc.active)
+		}
+		if got := schedule.NextActivation(tc.now); !got.Equal(tc.next) {
+			t.Errorf("NextActivation(%s) = %s, want %s", tc.now, got, tc.next)
+		}
+	}
+	if got := schedule.String(); got != "mon,tue,wed,thu,fri 18:00-08:00 UTC;sun,sat 00:00-24:00 Local" {
+		t.Fatalf("String() = %q", got)
+	}
+}
+
+func TestActiveSchedule_TimezoneAndErrors(t *testing.T) {
+	schedule, err := ParseActiveWindows([]string{"00:00-06:00 Asia/Tokyo"})
+	if err != nil {
+		t.Fatalf("ParseActiveWindows() error = %v", err)
+	}
+	// 16:00 UTC is 01:00 the next day in Tokyo.
+	if !schedule.Active(time.Date(2026, 10, 12, 16, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected schedule active at 01:00 Tokyo time")
+	}
+	for _, raw := range []string{"mon-xyz", "25:00-26:00", "09:00", "mon 09:00-17:00 Nowhere/City"} {
+		if _, err := ParseActiveWindows([]string{raw}); err == nil {
+			t.Errorf("ParseActiveWindows(%q) expected error", raw)
+		}
+	}
+}
+
+func TestIsAuthBlockedForModel_OutsideActiveWindows(t *testing.T) {
+	auth := &Auth{ID: "scheduled", Attributes: map[string]string{"active_windows": "daily 00:00-06:00 UTC"}}
+	now := time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC)
+	blocked, reason, next := isAuthBlockedForModel(auth, "m", now)
+	if !blocked || reason != blockReasonInactive || !next.Equal(time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("isAuthBlockedForModel() = %v, %v, %s; want blocked until midnight", blocked, reason, next)
+	}
+	if blocked, _, _ = isAuthBlockedForModel(auth, "m", now.Add(13*time.Hour)); blocked {
+		t.Fatalf("expected auth usable inside its window")
+	}
+}
+
+func TestIsAuthBlockedForModel_InvalidActiveWindowsFailClosed(t *testing.T) {
+	auth := &Auth{ID: "misconfigured", Attributes: map[string]string{"active_windows": "mon-fir 09:00-17:00 UTC"}}
+	blocked, reason, next := isAuthBlockedForModel(auth, "m", time.Now())
+	if !blocked || reason != blockReasonInactive || !next.IsZero() {
+		t.Fatalf("isAuthBlockedForModel() = %v, %v, %s; want blocked with no next activation", blocked, reason, next)
+	}
+	if auth.ActiveWindowsError() == nil {
+		t.Fatalf("expected the invalid active windows to be reported")
+	}
+}
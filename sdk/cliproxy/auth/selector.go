@@ -33,12 +33,55 @@ type WeightedSelector struct {
 	rng *rand.Rand
 }
 
+// LeastLoadedMode selects how LeastLoadedSelector ranks candidates.
+type LeastLoadedMode string
+
+const (
+	// LeastLoadedByLatency ranks candidates by EWMA latency scaled by in-flight requests. Streaming
+	// requests compare time-to-first-byte, non-streaming requests complete response time.
+	LeastLoadedByLatency LeastLoadedMode = "least-latency"
+	// LeastLoadedByInflight ranks candidates by in-flight requests, breaking ties by latency.
+	LeastLoadedByInflight LeastLoadedMode = "least-inflight"
+)
+
+// LeastLoadedSelector picks the least loaded credential inside the highest priority group
+// using the in-flight counts and latency EWMA tracked by the manager's LoadStats.
+// Equally ranked candidates are rotated round-robin.
+type LeastLoadedSelector struct {
+	mode LeastLoadedMode
+
+	mu      sync.Mutex
+	stats   *LoadStats
+	cursors map[string]int
+}
+
+// DefaultStickyTTL is how long StickySelector keeps an idle conversation pinned to a credential.
+const DefaultStickyTTL = 30 * time.Minute
+
+// StickySelector pins each conversation to one credential so consecutive turns reuse the
+// upstream prompt cache. Requests without an affinity key, and conversations whose pinned
+// credential is no longer available, are routed by the fallback selector and re-pinned.
+type StickySelector struct {
+	fallback Selector
+	ttl      time.Duration
+
+	mu        sync.Mutex
+	pins      map[string]stickyPin
+	lastSweep time.Time
+}
+
+type stickyPin struct {
+	authID  string
+	expires time.Time
+}
+
 type blockReason int
 
 const (
 	blockReasonNone blockReason = iota
 	blockReasonCooldown
 	blockReasonDisabled
+	blockReasonInactive
 	blockReasonOther
 )
 
@@ -167,7 +210,7 @@ func collectAvailableByPriority(auths []*Auth, model string, now time.Time) (ava
 	return available, cooldownCount, earliest
 }
 
-func getAvailableAuths(auths []*Auth, provider, model string, now time.Time) ([]*Auth, error) {
+func getAvailableAuths(ctx context.Context, auths []*Auth, provider, model string, now time.Time) ([]*Auth, error) {
 	if len(auths) == 0 {
 		return nil, &Error{Code: "auth_not_found", Message: "no auth candidates"}
 	}
@@ -198,33 +241,68 @@ func getAvailableAuths(auths []*Auth, provider, model string, now time.Time) ([]
 	}
 
 	available := availableByPriority[bestPriority]
+	if floor, ok := prioritySpilloverFloor(ctx); ok && floor < bestPriority {
+		// Spillover widens the pool to every group down to the floor chosen by the manager.
+		available = nil
+		for priority, group := range availableByPriority {
+			if priority >= floor {
+				available = append(available, group...)
+			}
+		}
+	}
 	if len(available) > 1 {
-		sort.Slice(available, func(i, j int) bool { return available[i].ID < available[j].ID })
+		sort.Slice(available, func(i, j int) bool {
+			if pi, pj := authPriority(available[i]), authPriority(available[j]); pi != pj {
+				return pi > pj
+			}
+			return available[i].ID < available[j].ID
+		})
 	}
 	return available, nil
 }
 
+// peekSelector returns the credential selector would pick without changing its state, or an
+// error when the selector does not implement SelectorPeeker.
+func peekSelector(ctx context.Context, selector Selector, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	peeker, ok := selector.(SelectorPeeker)
+	if !ok {
+		return nil, &Error{Code: "selector_cannot_peek", Message: "routing strategy does not support dry-run selection"}
+	}
+	return peeker.Peek(ctx, provider, model, opts, auths)
+}
+
 // Pick selects the next available auth for the provider in a round-robin manner.
 func (s *RoundRobinSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
-	_ = ctx
 	_ = opts
+	return s.pick(ctx, provider, model, auths, true)
+}
+
+// Peek implements SelectorPeeker.
+func (s *RoundRobinSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = opts
+	return s.pick(ctx, provider, model, auths, false)
+}
+
+func (s *RoundRobinSelector) pick(ctx context.Context, provider, model string, auths []*Auth, advance bool) (*Auth, error) {
 	now := time.Now()
-	available, err := getAvailableAuths(auths, provider, model, now)
+	available, err := getAvailableAuths(ctx, auths, provider, model, now)
 	if err != nil {
 		return nil, err
 	}
 	key := provider + ":" + model
 	s.mu.Lock()
-	if s.cursors == nil {
-		s.cursors = make(map[string]int)
-	}
 	index := s.cursors[key]
 
 	if index >= 2_147_483_640 {
 		index = 0
 	}
 
-	s.cursors[key] = index + 1
+	if advance {
+		if s.cursors == nil {
+			s.cursors = make(map[string]int)
+		}
+		s.cursors[key] = index + 1
+	}
 	s.mu.Unlock()
 	// log.Debugf("available: %d, index: %d, key: %d", len(available), index, index%len(available))
 	return available[index%len(available)], nil
@@ -232,22 +310,37 @@ func (s *RoundRobinSelector) Pick(ctx context.Context, provider, model string, o
 
 // Pick selects the first available auth for the provider in a deterministic manner.
 func (s *FillFirstSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
-	_ = ctx
 	_ = opts
 	now := time.Now()
-	available, err := getAvailableAuths(auths, provider, model, now)
+	available, err := getAvailableAuths(ctx, auths, provider, model, now)
 	if err != nil {
 		return nil, err
 	}
 	return available[0], nil
 }
 
+// Peek implements SelectorPeeker; fill-first keeps no state, so it is the same as Pick.
+func (s *FillFirstSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	return s.Pick(ctx, provider, model, opts, auths)
+}
+
 // Pick 在最高优先级候选集中按权重随机选择。
 func (s *WeightedSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
-	_ = ctx
 	_ = opts
+	return s.pick(ctx, provider, model, auths, nil)
+}
+
+// Peek implements SelectorPeeker. The draw uses a private random source instead of the
+// selector's, so it is a sample of what Pick returns rather than a prediction.
+func (s *WeightedSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	_ = opts
+	return s.pick(ctx, provider, model, auths, rand.New(rand.NewSource(time.Now().UnixNano())))
+}
+
+// pick draws from rng, or from the selector's own source when rng is nil.
+func (s *WeightedSelector) pick(ctx context.Context, provider, model string, auths []*Auth, rng *rand.Rand) (*Auth, error) {
 	now := time.Now()
-	available, err := getAvailableAuths(auths, provider, model, now)
+	available, err := getAvailableAuths(ctx, auths, provider, model, now)
 	if err != nil {
 		return nil, err
 	}
@@ -272,12 +365,17 @@ func (s *WeightedSelector) Pick(ctx context.Context, provider, model string, opt
 		return nil, &Error{Code: "auth_unavailable", Message: "no auth available"}
 	}
 
-	s.mu.Lock()
-	if s.rng == nil {
-		s.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	var target int64
+	if rng != nil {
+		target = rng.Int63n(total)
+	} else {
+		s.mu.Lock()
+		if s.rng == nil {
+			s.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+		target = s.rng.Int63n(total)
+		s.mu.Unlock()
 	}
-	target := s.rng.Int63n(total)
-	s.mu.Unlock()
 
 	var accum int64
 	for i, weight := range weights {
@@ -293,6 +391,240 @@ func (s *WeightedSelector) Pick(ctx context.Context, provider, model string, opt
 	return nil, &Error{Code: "auth_unavailable", Message: "no auth available"}
 }
 
+// NewLeastLoadedSelector constructs a LeastLoadedSelector; unknown modes rank by latency.
+func NewLeastLoadedSelector(mode LeastLoadedMode) *LeastLoadedSelector {
+	if mode != LeastLoadedByInflight {
+		mode = LeastLoadedByLatency
+	}
+	return &LeastLoadedSelector{mode: mode}
+}
+
+// SetLoadStats implements LoadStatsConsumer.
+func (s *LeastLoadedSelector) SetLoadStats(stats *LoadStats) {
+	s.mu.Lock()
+	s.stats = stats
+	s.mu.Unlock()
+}
+
+// Pick selects the least loaded available auth within the highest priority group.
+func (s *LeastLoadedSelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	return s.pick(ctx, provider, model, opts, auths, true)
+}
+
+// Peek implements SelectorPeeker.
+func (s *LeastLoadedSelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	return s.pick(ctx, provider, model, opts, auths, false)
+}
+
+func (s *LeastLoadedSelector) pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth, advance bool) (*Auth, error) {
+	now := time.Now()
+	available, err := getAvailableAuths(ctx, auths, provider, model, now)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	stats := s.stats
+	s.mu.Unlock()
+
+	inflight := make([]int, len(available))
+	latency := make([]float64, len(available))
+	known := make([]bool, len(available))
+	var knownSum float64
+	knownCount := 0
+	for i, candidate := range available {
+		inflight[i] = stats.InFlight(candidate.ID, model)
+		observed, ok := stats.Duration(candidate.ID, model)
+		if opts.Stream {
+			observed, ok = stats.Latency(candidate.ID, model)
+		}
+		if ok {
+			latency[i] = float64(observed)
+			known[i] = true
+			knownSum += latency[i]
+			knownCount++
+		}
+	}
+	// Credentials without samples are assumed to perform like the average known credential
+	// so they get explored without attracting every concurrent request.
+	if knownCount > 0 {
+		mean := knownSum / float64(knownCount)
+		for i := range latency {
+			if !known[i] {
+				latency[i] = mean
+			}
+		}
+	}
+
+	better := func(i, j int) int {
+		switch s.mode {
+		case LeastLoadedByInflight:
+			if inflight[i] != inflight[j] {
+				if inflight[i] < inflight[j] {
+					return -1
+				}
+				return 1
+			}
+			if latency[i] != latency[j] {
+				if latency[i] < latency[j] {
+					return -1
+				}
+				return 1
+			}
+			return 0
+		default:
+			scoreI := latency[i] * float64(inflight[i]+1)
+			scoreJ := latency[j] * float64(inflight[j]+1)
+			if scoreI != scoreJ {
+				if scoreI < scoreJ {
+					return -1
+				}
+				return 1
+			}
+			if inflight[i] != inflight[j] {
+				if inflight[i] < inflight[j] {
+					return -1
+				}
+				return 1
+			}
+			return 0
+		}
+	}
+
+	best := []int{0}
+	for i := 1; i < len(available); i++ {
+		switch cmp := better(i, best[0]); {
+		case cmp < 0:
+			best = best[:0]
+			best = append(best, i)
+		case cmp == 0:
+			best = append(best, i)
+		}
+	}
+	if len(best) == 1 {
+		return available[best[0]], nil
+	}
+
+	key := provider + ":" + model
+	s.mu.Lock()
+	index := s.cursors[key]
+	if index >= 2_147_483_640 {
+		index = 0
+	}
+	if advance {
+		if s.cursors == nil {
+			s.cursors = make(map[string]int)
+		}
+		s.cursors[key] = index + 1
+	}
+	s.mu.Unlock()
+	return available[best[index%len(best)]], nil
+}
+
+// NewStickySelector constructs a StickySelector. A non-positive ttl uses DefaultStickyTTL and a
+// nil fallback uses WeightedSelector.
+func NewStickySelector(ttl time.Duration, fallback Selector) *StickySelector {
+	if ttl <= 0 {
+		ttl = DefaultStickyTTL
+	}
+	if fallback == nil {
+		fallback = &WeightedSelector{}
+	}
+	return &StickySelector{fallback: fallback, ttl: ttl, pins: make(map[string]stickyPin)}
+}
+
+// TTL returns how long an idle conversation stays pinned.
+func (s *StickySelector) TTL() time.Duration { return s.ttl }
+
+// SetLoadStats implements LoadStatsConsumer by forwarding to the fallback selector.
+func (s *StickySelector) SetLoadStats(stats *LoadStats) {
+	if consumer, ok := s.fallback.(LoadStatsConsumer); ok {
+		consumer.SetLoadStats(stats)
+	}
+}
+
+// Pick returns the credential pinned to the request's conversation while it is available,
+// otherwise the fallback selector's choice, which becomes the new pin.
+func (s *StickySelector) Pick(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	affinity := stickyAffinityKey(opts.OriginalRequest)
+	if affinity == "" {
+		return s.fallback.Pick(ctx, provider, model, opts, auths)
+	}
+	key := provider + ":" + model + ":" + affinity
+	now := time.Now()
+
+	s.mu.Lock()
+	s.sweepLocked(now)
+	s.mu.Unlock()
+
+	if candidate := s.pinned(key, model, auths, now); candidate != nil {
+		s.pin(key, candidate.ID, now)
+		recordAffinity(ctx, AffinityHit)
+		return candidate, nil
+	}
+
+	selected, err := s.fallback.Pick(ctx, provider, model, opts, auths)
+	if err != nil {
+		return nil, err
+	}
+	if selected != nil {
+		s.pin(key, selected.ID, now)
+		recordAffinity(ctx, AffinityMiss)
+	}
+	return selected, nil
+}
+
+// Peek implements SelectorPeeker: it reports the pinned credential while it is available and
+// otherwise peeks the fallback selector, without pinning or recording affinity.
+func (s *StickySelector) Peek(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, auths []*Auth) (*Auth, error) {
+	if affinity := stickyAffinityKey(opts.OriginalRequest); affinity != "" {
+		if candidate := s.pinned(provider+":"+model+":"+affinity, model, auths, time.Now()); candidate != nil {
+			return candidate, nil
+		}
+	}
+	return peekSelector(ctx, s.fallback, provider, model, opts, auths)
+}
+
+// pinned returns the credential among auths that key is pinned to, if the pin is live and the
+// credential is available for model.
+func (s *StickySelector) pinned(key, model string, auths []*Auth, now time.Time) *Auth {
+	s.mu.Lock()
+	pin, ok := s.pins[key]
+	s.mu.Unlock()
+	if !ok || !now.Before(pin.expires) {
+		return nil
+	}
+	for _, candidate := range auths {
+		if candidate == nil || candidate.ID != pin.authID {
+			continue
+		}
+		if blocked, _, _ := isAuthBlockedForModel(candidate, model, now); !blocked {
+			return candidate
+		}
+		return nil
+	}
+	return nil
+}
+
+func (s *StickySelector) pin(key, authID string, now time.Time) {
+	s.mu.Lock()
+	s.pins[key] = stickyPin{authID: authID, expires: now.Add(s.ttl)}
+	s.mu.Unlock()
+}
+
+// sweepLocked drops expired pins at most once per minute.
+func (s *StickySelector) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, pin := range s.pins {
+		if !now.Before(pin.expires) {
+			delete(s.pins, key)
+		}
+	}
+}
+
 func isAuthBlockedForModel(auth *Auth, model string, now time.Time) (bool, blockReason, time.Time) {
 	if auth == nil {
 		return true, blockReasonOther, time.Time{}
@@ -300,6 +632,9 @@ func isAuthBlockedForModel(auth *Auth, model string, now time.Time) (bool, block
 	if auth.Disabled || auth.Status == StatusDisabled {
 		return true, blockReasonDisabled, time.Time{}
 	}
+	if next, inactive := auth.NextActivation(now); inactive {
+		return true, blockReasonInactive, next
+	}
 	if model != "" {
 		if len(auth.ModelStates) > 0 {
 			if state, ok := auth.ModelStates[model]; ok && state != nil {
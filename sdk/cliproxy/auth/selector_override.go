@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+)
+
+// SelectorOverride routes requests whose model and provider match the patterns with Selector
+// instead of the manager's default selector. Patterns may use '*' wildcards and are matched
+// case-insensitively; an empty pattern matches anything.
+type SelectorOverride struct {
+	Model    string
+	Provider string
+	Selector Selector
+}
+
+// SetSelectorOverrides replaces the per-model and per-provider selector overrides. Overrides
+// are evaluated in order and the first match wins.
+func (m *Manager) SetSelectorOverrides(overrides []SelectorOverride) {
+	if m == nil {
+		return
+	}
+	out := make([]SelectorOverride, 0, len(overrides))
+	for _, override := range overrides {
+		if override.Selector == nil {
+			continue
+		}
+		override.Model = strings.ToLower(strings.TrimSpace(override.Model))
+		override.Provider = strings.ToLower(strings.TrimSpace(override.Provider))
+		if consumer, ok := override.Selector.(LoadStatsConsumer); ok {
+			consumer.SetLoadStats(m.loadStats)
+		}
+		out = append(out, override)
+	}
+	m.mu.Lock()
+	m.selectorOverrides = out
+	m.mu.Unlock()
+}
+
+// selectorFor returns the selector for a request for model served by providers: the first
+// override whose model pattern matches the model and whose provider pattern matches one of the
+// providers, or the default selector. Callers must hold m.mu.
+func (m *Manager) selectorFor(providers []string, model string) Selector {
+	if len(m.selectorOverrides) == 0 {
+		return m.selector
+	}
+	modelKey := strings.ToLower(strings.TrimSpace(model))
+	if parsed := thinking.ParseSuffix(modelKey); parsed.ModelName != "" {
+		modelKey = strings.TrimSpace(parsed.ModelName)
+	}
+	for _, override := range m.selectorOverrides {
+		if override.Model != "" && !util.MatchWildcard(override.Model, modelKey) {
+			continue
+		}
+		if override.Provider != "" && !overrideMatchesProvider(override.Provider, providers) {
+			continue
+		}
+		return override.Selector
+	}
+	return m.selector
+}
+
+func overrideMatchesProvider(pattern string, providers []string) bool {
+	for _, provider := range providers {
+		if util.MatchWildcard(pattern, strings.ToLower(strings.TrimSpace(provider))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SelectorFactory builds a selector from the strategy-specific options configured under
+// routing.options.<strategy>. options may be nil.
+type SelectorFactory func(options map[string]any) (Selector, error)
+
+var (
+	selectorRegistryMu sync.RWMutex
+	selectorRegistry   = make(map[string]SelectorFactory)
+)
+
+func init() {
+	RegisterSelector("weighted", func(map[string]any) (Selector, error) { return &WeightedSelector{}, nil })
+	RegisterSelector("round-robin", func(map[string]any) (Selector, error) { return &RoundRobinSelector{}, nil })
+	RegisterSelector("fill-first", func(map[string]any) (Selector, error) { return &FillFirstSelector{}, nil })
+	RegisterSelector("least-latency", func(map[string]any) (Selector, error) {
+		return NewLeastLoadedSelector(LeastLoadedByLatency), nil
+	})
+	RegisterSelector("least-inflight", func(map[string]any) (Selector, error) {
+		return NewLeastLoadedSelector(LeastLoadedByInflight), nil
+	})
+	RegisterSelector("sticky", newStickySelectorFromOptions)
+	RegisterSelector("most-headroom", func(map[string]any) (Selector, error) { return &HeadroomSelector{}, nil })
+}
+
+// RegisterSelector registers a selector factory under a routing strategy name, so the strategy
+// can be chosen with routing.strategy and survives config reloads. Names are case-insensitive;
+// registering an existing name, including a built-in one, replaces its factory.
+func RegisterSelector(name string, factory SelectorFactory) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || factory == nil {
+		return
+	}
+	selectorRegistryMu.Lock()
+	selectorRegistry[name] = factory
+	selectorRegistryMu.Unlock()
+}
+
+// SelectorRegistered reports whether a selector factory is registered under name.
+func SelectorRegistered(name string) bool {
+	selectorRegistryMu.RLock()
+	_, ok := selectorRegistry[strings.ToLower(strings.TrimSpace(name))]
+	selectorRegistryMu.RUnlock()
+	return ok
+}
+
+// NewSelector builds the selector registered under name with the given options.
+func NewSelector(name string, options map[string]any) (Selector, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	selectorRegistryMu.RLock()
+	factory, ok := selectorRegistry[name]
+	selectorRegistryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("auth: routing strategy %q is not registered", name)
+	}
+	selector, err := factory(options)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to build routing strategy %q: %w", name, err)
+	}
+	if selector == nil {
+		return nil, fmt.Errorf("auth: routing strategy %q returned no selector", name)
+	}
+	return selector, nil
+}
+
+// newStickySelectorFromOptions builds a sticky selector; the "ttl" option is the idle pin
+// lifetime in seconds.
+func newStickySelectorFromOptions(options map[string]any) (Selector, error) {
+	var ttl time.Duration
+	if raw, ok := options["ttl"]; ok {
+		seconds, okInt := parseIntAny(raw)
+		if !okInt {
+			return nil, fmt.Errorf("invalid ttl %v", raw)
+		}
+		ttl = time.Duration(seconds) * time.Second
+	}
+	return NewStickySelector(ttl, nil), nil
+}
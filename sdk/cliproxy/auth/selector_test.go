@@ -321,3 +321,218 @@ func TestWeightedSelectorPick_WeightOverflow(t *testing.T) {
 		t.Fatalf("Pick() error code = %q, want %q", authErr.Code, "auth_unavailable")
 	}
 }
+
+func TestLeastLoadedSelectorPick_PrefersLowerLatency(t *testing.T) {
+	t.Parallel()
+
+	stats := NewLoadStats()
+	selector := NewLeastLoadedSelector(LeastLoadedByLatency)
+	selector.SetLoadStats(stats)
+	auths := []*Auth{
+		{ID: "slow"},
+		{ID: "fast"},
+		{ID: "low", Attributes: map[string]string{"priority": "-1"}},
+	}
+	stats.observeLatency("slow", "m", 900*time.Millisecond)
+	stats.observeLatency("fast", "m", 100*time.Millisecond)
+	stats.observeLatency("low", "m", time.Millisecond)
+	streamOpts := cliproxyexecutor.Options{Stream: true}
+
+	got, err := selector.Pick(context.Background(), "mixed", "m", streamOpts, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "fast" {
+		t.Fatalf("Pick() auth.ID = %q, want %q", got.ID, "fast")
+	}
+
+	// Enough in-flight requests on the fast credential make the slow one cheaper.
+	for i := 0; i < 9; i++ {
+		stats.acquire("fast", "m")
+	}
+	got, err = selector.Pick(context.Background(), "mixed", "m", streamOpts, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "slow" {
+		t.Fatalf("Pick() auth.ID = %q, want %q", got.ID, "slow")
+	}
+}
+
+func TestLeastLoadedSelectorPick_SeparatesStreamingAndNonStreamingLatency(t *testing.T) {
+	t.Parallel()
+
+	stats := NewLoadStats()
+	selector := NewLeastLoadedSelector(LeastLoadedByLatency)
+	selector.SetLoadStats(stats)
+	auths := []*Auth{{ID: "chat"}, {ID: "batch"}}
+	// The batch credential mostly serves long non-streaming responses; they must not count
+	// against its time-to-first-byte.
+	stats.observeLatency("chat", "m", 400*time.Millisecond)
+	stats.observeLatency("batch", "m", 200*time.Millisecond)
+	stats.observeDuration("chat", "m", 3*time.Second)
+	for i := 0; i < 5; i++ {
+		stats.observeDuration("batch", "m", 20*time.Second)
+	}
+
+	got, err := selector.Pick(context.Background(), "mixed", "m", cliproxyexecutor.Options{Stream: true}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "batch" {
+		t.Fatalf("streaming Pick() auth.ID = %q, want %q", got.ID, "batch")
+	}
+	got, err = selector.Pick(context.Background(), "mixed", "m", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "chat" {
+		t.Fatalf("non-streaming Pick() auth.ID = %q, want %q", got.ID, "chat")
+	}
+}
+
+func TestLeastLoadedSelectorPick_LeastInflight(t *testing.T) {
+	t.Parallel()
+
+	stats := NewLoadStats()
+	selector := NewLeastLoadedSelector(LeastLoadedByInflight)
+	selector.SetLoadStats(stats)
+	auths := []*Auth{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	releaseA := stats.acquire("a", "m")
+	stats.acquire("c", "m")
+	got, err := selector.Pick(context.Background(), "gemini", "m", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "b" {
+		t.Fatalf("Pick() auth.ID = %q, want %q", got.ID, "b")
+	}
+
+	releaseA()
+	releaseA()
+	if inflight := stats.InFlight("a", "m"); inflight != 0 {
+		t.Fatalf("InFlight(a) = %d after release, want 0", inflight)
+	}
+	stats.acquire("b", "m")
+	got, err = selector.Pick(context.Background(), "gemini", "m", cliproxyexecutor.Options{}, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != "a" {
+		t.Fatalf("Pick() auth.ID = %q, want %q", got.ID, "a")
+	}
+}
+
+func TestStickySelectorPick_PinsConversation(t *testing.T) {
+	t.Parallel()
+
+	selector := NewStickySelector(time.Minute, &RoundRobinSelector{})
+	auths := []*Auth{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	opts := cliproxyexecutor.Options{OriginalRequest: []byte(`{"metadata":{"user_id":"session-1"},"messages":[{"role":"user","content":"hi"}]}`)}
+
+	ctx := withAffinityProbe(context.Background())
+	first, err := selector.Pick(ctx, "claude", "m", opts, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got := AffinityFromContext(ctx); got != AffinityMiss {
+		t.Fatalf("AffinityFromContext() = %q, want %q", got, AffinityMiss)
+	}
+	for i := 0; i < 3; i++ {
+		ctx = withAffinityProbe(context.Background())
+		got, errPick := selector.Pick(ctx, "claude", "m", opts, auths)
+		if errPick != nil {
+			t.Fatalf("Pick() error = %v", errPick)
+		}
+		if got.ID != first.ID {
+			t.Fatalf("Pick() auth.ID = %q, want pinned %q", got.ID, first.ID)
+		}
+		if outcome := AffinityFromContext(ctx); outcome != AffinityHit {
+			t.Fatalf("AffinityFromContext() = %q, want %q", outcome, AffinityHit)
+		}
+	}
+
+	// A blocked pinned credential falls back to normal selection and re-pins.
+	blocked := make([]*Auth, 0, len(auths))
+	for _, auth := range auths {
+		if auth.ID == first.ID {
+			auth = &Auth{ID: auth.ID, ModelStates: map[string]*ModelState{
+				"m": {Unavailable: true, NextRetryAfter: time.Now().Add(time.Hour)},
+			}}
+		}
+		blocked = append(blocked, auth)
+	}
+	ctx = withAffinityProbe(context.Background())
+	next, err := selector.Pick(ctx, "claude", "m", opts, blocked)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if next.ID == first.ID {
+		t.Fatalf("Pick() returned blocked pinned auth %q", next.ID)
+	}
+	if outcome := AffinityFromContext(ctx); outcome != AffinityMiss {
+		t.Fatalf("AffinityFromContext() = %q, want %q", outcome, AffinityMiss)
+	}
+	got, err := selector.Pick(context.Background(), "claude", "m", opts, auths)
+	if err != nil {
+		t.Fatalf("Pick() error = %v", err)
+	}
+	if got.ID != next.ID {
+		t.Fatalf("Pick() auth.ID = %q, want re-pinned %q", got.ID, next.ID)
+	}
+}
+
+func TestStickyAffinityKey(t *testing.T) {
+	t.Parallel()
+
+	if got := stickyAffinityKey([]byte(`{"prompt_cache_key":"conv-9","input":"hello"}`)); got != "cache:conv-9" {
+		t.Fatalf("stickyAffinityKey(prompt_cache_key) = %q", got)
+	}
+	first := stickyAffinityKey([]byte(`{"system":"be brief","messages":[{"role":"user","content":"q1"}]}`))
+	later := stickyAffinityKey([]byte(`{"system":"be brief","messages":[{"role":"user","content":"q1"},{"role":"assistant","content":"a1"},{"role":"user","content":"q2"}]}`))
+	if first == "" || first != later {
+		t.Fatalf("expected turns of one conversation to share a key, got %q and %q", first, later)
+	}
+	other := stickyAffinityKey([]byte(`{"system":"be brief","messages":[{"role":"user","content":"different"}]}`))
+	if other == first {
+		t.Fatalf("expected different conversations to get different keys")
+	}
+	if got := stickyAffinityKey([]byte(`{"model":"m"}`)); got != "" {
+		t.Fatalf("stickyAffinityKey(no conversation) = %q, want empty", got)
+	}
+}
+
+func TestManager_SelectorForOverrides(t *testing.T) {
+	t.Parallel()
+
+	fallback := &WeightedSelector{}
+	fillFirst := &FillFirstSelector{}
+	sticky := NewStickySelector(0, nil)
+	m := NewManager(nil, fallback, nil)
+	m.SetSelectorOverrides([]SelectorOverride{
+		{Model: "Claude-*", Provider: "claude", Selector: fillFirst},
+		{Provider: "codex", Selector: sticky},
+		{Model: "ignored", Selector: nil},
+	})
+
+	cases := []struct {
+		name      string
+		providers []string
+		model     string
+		want      Selector
+	}{
+		{name: "model and provider", providers: []string{"claude"}, model: "claude-sonnet-4(high)", want: fillFirst},
+		{name: "provider only", providers: []string{"gemini", "codex"}, model: "gpt-5", want: sticky},
+		{name: "model without provider", providers: []string{"vertex"}, model: "claude-sonnet-4", want: fallback},
+		{name: "no match", providers: []string{"gemini"}, model: "gemini-2.5-pro", want: fallback},
+	}
+	for _, tc := range cases {
+		m.mu.RLock()
+		got := m.selectorFor(tc.providers, tc.model)
+		m.mu.RUnlock()
+		if got != tc.want {
+			t.Fatalf("%s: selectorFor() = %T, want %T", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+type prioritySpilloverKey struct{}
+
+// withPrioritySpillover returns ctx carrying the lowest priority group selectors may draw from.
+func withPrioritySpillover(ctx context.Context, floor int) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, prioritySpilloverKey{}, floor)
+}
+
+// prioritySpilloverFloor returns the spillover floor carried by ctx, if any.
+func prioritySpilloverFloor(ctx context.Context) (int, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	floor, ok := ctx.Value(prioritySpilloverKey{}).(int)
+	return floor, ok
+}
+
+// spilloverPolicyFor returns the routing.spillover policy of provider, falling back to "*".
+func spilloverPolicyFor(cfg *internalconfig.Config, provider string) (internalconfig.SpilloverPolicy, bool) {
+	if cfg == nil || len(cfg.Routing.Spillover) == 0 {
+		return internalconfig.SpilloverPolicy{}, false
+	}
+	if policy, ok := cfg.Routing.Spillover[strings.ToLower(strings.TrimSpace(provider))]; ok {
+		return policy, true
+	}
+	policy, ok := cfg.Routing.Spillover["*"]
+	return policy, ok
+}
+
+// spilloverContext decides how far below the top priority group selectors may reach for this
+// pick. Starting at the top, the next lower group is added while the lowest included group is
+// overloaded for model, so spillover cascades group by group. ctx is returned unchanged when no
+// spillover applies.
+func (m *Manager) spilloverContext(ctx context.Context, candidates []*Auth, model string, now time.Time) context.Context {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || len(cfg.Routing.Spillover) == 0 {
+		return ctx
+	}
+	available, _, _ := collectAvailableByPriority(candidates, model, now)
+	if len(available) < 2 {
+		return ctx
+	}
+	priorities := make([]int, 0, len(available))
+	for priority := range available {
+		priorities = append(priorities, priority)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+	floor := priorities[0]
+	for i := 0; i < len(priorities)-1; i++ {
+		if !m.priorityGroupOverloaded(cfg, available[priorities[i]], model) {
+			break
+		}
+		floor = priorities[i+1]
+	}
+	if floor == priorities[0] {
+		return ctx
+	}
+	return withPrioritySpillover(ctx, floor)
+}
+
+// priorityGroupOverloaded reports whether the credentials of any provider in group reach the
+// in-flight or error rate threshold of its spillover policy, averaged per credential.
+func (m *Manager) priorityGroupOverloaded(cfg *internalconfig.Config, group []*Auth, model string) bool {
+	type load struct {
+		count     int
+		inflight  int
+		errorSum  float64
+		errorSeen int
+	}
+	byProvider := make(map[string]*load)
+	for _, auth := range group {
+		provider := strings.ToLower(strings.TrimSpace(auth.Provider))
+		entry := byProvider[provider]
+		if entry == nil {
+			entry = &load{}
+			byProvider[provider] = entry
+		}
+		entry.count++
+		entry.inflight += m.loadStats.InFlight(auth.ID, model)
+		if rate, ok := m.loadStats.ErrorRate(auth.ID, model); ok {
+			entry.errorSum += rate
+			entry.errorSeen++
+		}
+	}
+	for provider, entry := range byProvider {
+		policy, ok := spilloverPolicyFor(cfg, provider)
+		if !ok {
+			continue
+		}
+		if policy.InFlight > 0 && float64(entry.inflight)/float64(entry.count) >= policy.InFlight {
+			return true
+		}
+		if policy.ErrorRate > 0 && entry.errorSeen > 0 && entry.errorSum/float64(entry.errorSeen) >= policy.ErrorRate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestManager_SpilloverToLowerPriorityGroup(t *testing.T) {
+	const model = "spillover-model"
+
+	m := NewManager(nil, &FillFirstSelector{}, nil)
+	auths := []*Auth{
+		{ID: "primary", Provider: "claude", Attributes: map[string]string{"priority": "10"}},
+		{ID: "backup", Provider: "claude", Attributes: map[string]string{"priority": "5"}},
+		{ID: "last-resort", Provider: "claude", Attributes: map[string]string{"priority": "1"}},
+	}
+	pick := func() string {
+		now := time.Now()
+		ctx := m.spilloverContext(context.Background(), auths, model, now)
+		selected, err := m.selector.Pick(ctx, "claude", model, cliproxyexecutor.Options{}, auths)
+		if err != nil {
+			t.Fatalf("Pick() error = %v", err)
+		}
+		return selected.ID
+	}
+
+	release := m.loadStats.acquire("primary", model)
+	defer release()
+	if got := pick(); got != "primary" {
+		t.Fatalf("Pick() without spillover policy = %s, want primary", got)
+	}
+
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{Spillover: map[string]internalconfig.SpilloverPolicy{
+		"*": {InFlight: 1},
+	}}})
+	floor, ok := prioritySpilloverFloor(m.spilloverContext(context.Background(), auths, model, time.Now()))
+	if !ok || floor != 5 {
+		t.Fatalf("spillover floor = %d, %v; want 5, true", floor, ok)
+	}
+	if got := pick(); got != "primary" {
+		t.Fatalf("Pick() with spillover = %s, want primary ahead of the backup", got)
+	}
+
+	// Spillover cascades while each included group stays overloaded.
+	releaseBackup := m.loadStats.acquire("backup", model)
+	defer releaseBackup()
+	if floor, _ = prioritySpilloverFloor(m.spilloverContext(context.Background(), auths, model, time.Now())); floor != 1 {
+		t.Fatalf("spillover floor = %d, want 1", floor)
+	}
+
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{Spillover: map[string]internalconfig.SpilloverPolicy{
+		"claude": {ErrorRate: 0.5},
+	}}})
+	if _, ok = prioritySpilloverFloor(m.spilloverContext(context.Background(), auths, model, time.Now())); ok {
+		t.Fatalf("spillover applied without recorded errors")
+	}
+	m.loadStats.observeOutcome("primary", model, true)
+	if floor, ok = prioritySpilloverFloor(m.spilloverContext(context.Background(), auths, model, time.Now())); !ok || floor != 5 {
+		t.Fatalf("spillover floor after errors = %d, %v; want 5, true", floor, ok)
+	}
+}
+
+func TestGetAvailableAuths_SpilloverFloorMergesGroups(t *testing.T) {
+	auths := []*Auth{
+		{ID: "b", Attributes: map[string]string{"priority": "1"}},
+		{ID: "a", Attributes: map[string]string{"priority": "1"}},
+		{ID: "z", Attributes: map[string]string{"priority": "3"}},
+		{ID: "low", Attributes: map[string]string{"priority": "0"}},
+	}
+	ctx := withPrioritySpillover(context.Background(), 1)
+	available, err := getAvailableAuths(ctx, auths, "claude", "m", time.Now())
+	if err != nil {
+		t.Fatalf("getAvailableAuths() error = %v", err)
+	}
+	if len(available) != 3 || available[0].ID != "z" || available[1].ID != "a" || available[2].ID != "b" {
+		ids := make([]string, 0, len(available))
+		for _, auth := range available {
+			ids = append(ids, auth.ID)
+		}
+		t.Fatalf("available = %v, want [z a b]", ids)
+	}
+}
+
+func TestManager_ExplainRoutingMatchesSpilloverPicks(t *testing.T) {
+	const model = "spillover-explain-model"
+
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	m.RegisterExecutor(&recordingExecutor{provider: "claude"})
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{Spillover: map[string]internalconfig.SpilloverPolicy{
+		"*": {InFlight: 1},
+	}}})
+	for _, auth := range []*Auth{
+		{ID: "se-primary", Provider: "claude", Status: StatusActive, Attributes: map[string]string{"priority": "10"}},
+		{ID: "se-overflow", Provider: "claude", Status: StatusActive, Attributes: map[string]string{"priority": "5"}},
+		{ID: "se-last", Provider: "claude", Status: StatusActive, Attributes: map[string]string{"priority": "1"}},
+	} {
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("register auth %s: %v", auth.ID, errRegister)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, "claude", []*registry.ModelInfo{{ID: model}})
+		id := auth.ID
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+	reasons := func(explanation RoutingExplanation) map[string]string {
+		out := make(map[string]string)
+		for _, candidate := range explanation.Candidates {
+			out[candidate.AuthID] = candidate.Reason
+		}
+		return out
+	}
+	pick := func() string {
+		auth, _, _, errPick := m.pickNextMixedOnce(context.Background(), []string{"claude"}, model, cliproxyexecutor.Options{}, map[string]struct{}{})
+		if errPick != nil {
+			t.Fatalf("pick: %v", errPick)
+		}
+		m.concurrency.release(auth)
+		return auth.ID
+	}
+
+	// While the primary has headroom the overflow credentials are out of reach.
+	explanation := m.ExplainRouting(context.Background(), []string{"claude"}, model, cliproxyexecutor.Options{})
+	if got := reasons(explanation); explanation.SpilloverFloor != nil || got["se-overflow"] != "lower_priority" || got["se-last"] != "lower_priority" {
+		t.Fatalf("explain without load: floor %v, reasons %v", explanation.SpilloverFloor, got)
+	}
+	if selected := pick(); selected != explanation.Selected || selected != "se-primary" {
+		t.Fatalf("pick = %s, explain said %s", selected, explanation.Selected)
+	}
+
+	release := m.loadStats.acquire("se-primary", model)
+	defer release()
+	for i := 0; i < 4; i++ {
+		explanation = m.ExplainRouting(context.Background(), []string{"claude"}, model, cliproxyexecutor.Options{})
+		if got := reasons(explanation); explanation.SpilloverFloor == nil || *explanation.SpilloverFloor != 5 || got["se-overflow"] != "" || got["se-last"] != "lower_priority" {
+			t.Fatalf("explain %d with a busy primary: floor %v, reasons %v", i, explanation.SpilloverFloor, got)
+		}
+		if selected := pick(); selected != explanation.Selected {
+			t.Fatalf("pick %d = %s, explain said %s", i, selected, explanation.Selected)
+		}
+	}
+}
@@ -11,3 +11,19 @@ type Store interface {
 	// Delete removes the auth record identified by id.
 	Delete(ctx context.Context, id string) error
 }
+
+// RuntimeStateStore is implemented by stores that also persist the runtime state of auths, such as
+// cooldowns and per-model quota backoff, separately from the credential records.
+type RuntimeStateStore interface {
+	// LoadRuntimeState returns the last saved snapshot, or nil when none was saved.
+	LoadRuntimeState(ctx context.Context) ([]byte, error)
+	// SaveRuntimeState replaces the saved snapshot.
+	SaveRuntimeState(ctx context.Context, data []byte) error
+}
+
+// RuntimeStateFlusher is implemented by runtime state stores that batch saves, such as the git
+// store, which commits snapshots only periodically. FlushRuntimeState persists the latest
+// snapshot immediately.
+type RuntimeStateFlusher interface {
+	FlushRuntimeState(ctx context.Context) error
+}
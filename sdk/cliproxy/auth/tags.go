@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// NormalizeTags trims and lowercases tags, splits comma-separated values and returns them sorted
+// without duplicates.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, raw := range tags {
+		for _, part := range strings.Split(raw, ",") {
+			tag := strings.ToLower(strings.TrimSpace(part))
+			if tag == "" {
+				continue
+			}
+			if _, ok := seen[tag]; ok {
+				continue
+			}
+			seen[tag] = struct{}{}
+			out = append(out, tag)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// authTags returns the tags synthesized into the "tags" attribute of auth.
+func authTags(auth *Auth) []string {
+	if auth == nil || auth.Attributes == nil {
+		return nil
+	}
+	return NormalizeTags([]string{auth.Attributes["tags"]})
+}
+
+// authHasTags reports whether auth carries every tag in required.
+func authHasTags(auth *Auth, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+	tags := authTags(auth)
+	for _, tag := range required {
+		idx := sort.SearchStrings(tags, tag)
+		if idx >= len(tags) || tags[idx] != tag {
+			return false
+		}
+	}
+	return true
+}
+
+// routeTags returns the tags a request requires of its credential: the routing.key-tags default
+// of the client API key combined with the tags of the X-Route-Tags request header. The header can
+// narrow the pool of a key but never widen it.
+func (m *Manager) routeTags(opts cliproxyexecutor.Options) []string {
+	var tags []string
+	if cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config); cfg != nil && len(cfg.Routing.KeyTags) > 0 {
+		if key := metadataString(opts.Metadata, cliproxyexecutor.ClientAPIKeyMetadataKey); key != "" {
+			tags = append(tags, cfg.Routing.KeyTags[key]...)
+		}
+	}
+	if header := metadataString(opts.Metadata, cliproxyexecutor.RouteTagsMetadataKey); header != "" {
+		tags = append(tags, header)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return NormalizeTags(tags)
+}
+
+func newTagsMismatchError(tags []string) *Error {
+	return &Error{Code: "auth_not_found", Message: "no auth matches route tags " + strings.Join(tags, ",")}
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestManager_RouteTagsRestrictCandidates(t *testing.T) {
+	const model = "route-tags-model"
+
+	m := NewManager(nil, &RoundRobinSelector{}, nil)
+	m.SetConfig(&internalconfig.Config{Routing: internalconfig.RoutingConfig{KeyTags: map[string][]string{"free-key": {"free"}}}})
+	executor := &recordingExecutor{provider: "claude"}
+	m.RegisterExecutor(executor)
+	auths := []*Auth{
+		{ID: "tags-paid-eu", Provider: "claude", Status: StatusActive, Attributes: map[string]string{"tags": "eu,paid"}},
+		{ID: "tags-free", Provider: "claude", Status: StatusActive, Attributes: map[string]string{"tags": "free"}},
+	}
+	for _, auth := range auths {
+		if _, errRegister := m.Register(context.Background(), auth); errRegister != nil {
+			t.Fatalf("register auth %s: %v", auth.ID, errRegister)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, "claude", []*registry.ModelInfo{{ID: model}})
+		id := auth.ID
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	execute := func(meta map[string]any) error {
+		_, err := m.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: model}, cliproxyexecutor.Options{Metadata: meta})
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		if err := execute(map[string]any{cliproxyexecutor.RouteTagsMetadataKey: "PAID, eu"}); err != nil {
+			t.Fatalf("Execute(paid,eu) error = %v", err)
+		}
+	}
+	if err := execute(map[string]any{cliproxyexecutor.ClientAPIKeyMetadataKey: "free-key"}); err != nil {
+		t.Fatalf("Execute(free key) error = %v", err)
+	}
+	calls := executor.AuthCalls()
+	if len(calls) != 3 || calls[0] != "tags-paid-eu" || calls[1] != "tags-paid-eu" || calls[2] != "tags-free" {
+		t.Fatalf("auth calls = %v, want paid twice then free", calls)
+	}
+
+	// The header cannot widen the pool of a key with default tags.
+	err := execute(map[string]any{cliproxyexecutor.ClientAPIKeyMetadataKey: "free-key", cliproxyexecutor.RouteTagsMetadataKey: "paid"})
+	if authErr, ok := err.(*Error); !ok || authErr.Message != "no auth matches route tags free,paid" {
+		t.Fatalf("Execute(free key, paid) error = %v, want a route tags mismatch", err)
+	}
+}
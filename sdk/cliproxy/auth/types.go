@@ -75,6 +75,24 @@ type QuotaState struct {
 	NextRecoverAt time.Time `json:"next_recover_at"`
 	// BackoffLevel stores the progressive cooldown exponent used for rate limits.
 	BackoffLevel int `json:"backoff_level,omitempty"`
+	// Snapshot holds the remaining quota last reported by upstream rate-limit response headers.
+	Snapshot *QuotaSnapshot `json:"snapshot,omitempty"`
+}
+
+// QuotaSnapshot is the remaining upstream quota of a credential as reported by response headers.
+// Limits are zero when the provider did not report them.
+type QuotaSnapshot struct {
+	RemainingRequests int64 `json:"remaining_requests"`
+	LimitRequests     int64 `json:"limit_requests,omitempty"`
+	RemainingTokens   int64 `json:"remaining_tokens"`
+	LimitTokens       int64 `json:"limit_tokens,omitempty"`
+	// UsedPercent is the share of the provider usage window already consumed, from 0 to 100,
+	// for providers that report usage instead of remaining counts.
+	UsedPercent float64 `json:"used_percent,omitempty"`
+	// ResetAt is when the most constrained limit resets.
+	ResetAt time.Time `json:"reset_at,omitempty"`
+	// UpdatedAt is when the headers were received.
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // ModelState captures the execution state for a specific model under an auth entry.
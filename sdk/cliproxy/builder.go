@@ -200,16 +200,19 @@ func (b *Builder) Build() (*Service, error) {
 			dirSetter.SetBaseDir(b.cfg.AuthDir)
 		}
 
-		strategy := ""
+		var routing config.RoutingConfig
 		if b.cfg != nil {
-			strategy = b.cfg.Routing.Strategy
+			routing = b.cfg.Routing
 		}
+		strategy := routing.Strategy
 		normalized, known := normalizeRoutingStrategyWithKnown(strategy)
 		if !known && strings.TrimSpace(strategy) != "" {
 			log.Warnf("unknown routing strategy %q; falling back to %s", strategy, routingStrategyWeighted)
 		}
-		selector := selectorForRoutingStrategy(normalized)
+		routing.Strategy = normalized
+		selector := selectorForRouting(routing)
 		coreManager = coreauth.NewManager(tokenStore, selector, nil)
+		coreManager.SetSelectorOverrides(selectorOverridesForRouting(routing))
 	}
 	// Attach a default RoundTripper provider so providers can opt-in per-auth transports.
 	coreManager.SetRoundTripperProvider(newDefaultRoundTripperProvider())
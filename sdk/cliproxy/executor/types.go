@@ -10,6 +10,19 @@ import (
 // RequestedModelMetadataKey stores the client-requested model name in Options.Metadata.
 const RequestedModelMetadataKey = "requested_model"
 
+// ClientAPIKeyMetadataKey stores the authenticated client API key in Options.Metadata.
+const ClientAPIKeyMetadataKey = "client_api_key"
+
+// PriorityClassMetadataKey stores the request priority class in Options.Metadata.
+const PriorityClassMetadataKey = "priority_class"
+
+// RouteTagsMetadataKey stores the comma-separated credential tags requested by the client in Options.Metadata.
+const RouteTagsMetadataKey = "route_tags"
+
+// AccessMetadataKey stores the access provider metadata of the authenticated client, a
+// map[string]string carrying its model policy, in Options.Metadata.
+const AccessMetadataKey = "access_metadata"
+
 // Request encapsulates the translated payload that will be sent to a provider executor.
 type Request struct {
 	// Model is the upstream model identifier after translation.
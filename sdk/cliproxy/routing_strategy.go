@@ -1,17 +1,28 @@
 package cliproxy
 
 import (
+	"reflect"
 	"strings"
+	"time"
 
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
 )
 
 const (
 	routingStrategyWeighted   = "weighted"
 	routingStrategyRoundRobin = "round-robin"
 	routingStrategyFillFirst  = "fill-first"
+
+	routingStrategyLeastLatency  = "least-latency"
+	routingStrategyLeastInflight = "least-inflight"
+	routingStrategySticky        = "sticky"
+	routingStrategyMostHeadroom  = "most-headroom"
 )
 
+// normalizeRoutingStrategyWithKnown maps a configured strategy to its registered name, resolving
+// the aliases of the built-in strategies. Unknown strategies fall back to weighted.
 func normalizeRoutingStrategyWithKnown(strategy string) (string, bool) {
 	normalized := strings.ToLower(strings.TrimSpace(strategy))
 	if normalized == "" {
@@ -24,9 +35,19 @@ func normalizeRoutingStrategyWithKnown(strategy string) (string, bool) {
 		return routingStrategyRoundRobin, true
 	case routingStrategyWeighted, "weight":
 		return routingStrategyWeighted, true
-	default:
-		return routingStrategyWeighted, false
+	case routingStrategyLeastLatency, "leastlatency", "latency":
+		return routingStrategyLeastLatency, true
+	case routingStrategyLeastInflight, "leastinflight", "inflight", "least-loaded":
+		return routingStrategyLeastInflight, true
+	case routingStrategySticky, "affinity":
+		return routingStrategySticky, true
+	case routingStrategyMostHeadroom, "mostheadroom", "headroom":
+		return routingStrategyMostHeadroom, true
+	}
+	if coreauth.SelectorRegistered(normalized) {
+		return normalized, true
 	}
+	return routingStrategyWeighted, false
 }
 
 func normalizeRoutingStrategy(strategy string) string {
@@ -35,12 +56,71 @@ func normalizeRoutingStrategy(strategy string) string {
 }
 
 func selectorForRoutingStrategy(strategy string) coreauth.Selector {
-	switch normalizeRoutingStrategy(strategy) {
-	case routingStrategyFillFirst:
-		return &coreauth.FillFirstSelector{}
-	case routingStrategyRoundRobin:
-		return &coreauth.RoundRobinSelector{}
-	default:
+	return selectorForRouting(config.RoutingConfig{Strategy: strategy})
+}
+
+// selectorForRouting builds the selector for the routing configuration through the selector
+// registry, passing the options configured for the strategy. A factory error falls back to
+// the weighted selector.
+func selectorForRouting(routing config.RoutingConfig) coreauth.Selector {
+	strategy := normalizeRoutingStrategy(routing.Strategy)
+	selector, err := coreauth.NewSelector(strategy, selectorOptions(routing, strategy))
+	if err != nil {
+		log.Warnf("%v; falling back to %s", err, routingStrategyWeighted)
 		return &coreauth.WeightedSelector{}
 	}
+	return selector
+}
+
+// selectorOverridesForRouting builds the selectors of routing.overrides. Entries with an unknown
+// strategy are skipped.
+func selectorOverridesForRouting(routing config.RoutingConfig) []coreauth.SelectorOverride {
+	if len(routing.Overrides) == 0 {
+		return nil
+	}
+	overrides := make([]coreauth.SelectorOverride, 0, len(routing.Overrides))
+	for _, entry := range routing.Overrides {
+		strategy, known := normalizeRoutingStrategyWithKnown(entry.Strategy)
+		if !known {
+			log.Warnf("unknown routing strategy %q in override for model %q provider %q; skipping", entry.Strategy, entry.Model, entry.Provider)
+			continue
+		}
+		selector, err := coreauth.NewSelector(strategy, selectorOptions(routing, strategy))
+		if err != nil {
+			log.Warnf("%v; skipping override for model %q provider %q", err, entry.Model, entry.Provider)
+			continue
+		}
+		overrides = append(overrides, coreauth.SelectorOverride{Model: entry.Model, Provider: entry.Provider, Selector: selector})
+	}
+	return overrides
+}
+
+// routingOverridesChanged reports whether the override selectors must be rebuilt.
+func routingOverridesChanged(previous, next config.RoutingConfig) bool {
+	if len(previous.Overrides) == 0 && len(next.Overrides) == 0 {
+		return false
+	}
+	return !reflect.DeepEqual(previous.Overrides, next.Overrides) ||
+		!reflect.DeepEqual(previous.Options, next.Options) ||
+		stickyTTL(previous) != stickyTTL(next)
+}
+
+// selectorOptions returns the routing.options entry of strategy. For sticky, the legacy
+// sticky-ttl setting supplies the ttl option when it is not set explicitly.
+func selectorOptions(routing config.RoutingConfig, strategy string) map[string]any {
+	options := make(map[string]any, len(routing.Options[strategy])+1)
+	for key, value := range routing.Options[strategy] {
+		options[key] = value
+	}
+	if _, ok := options["ttl"]; !ok && strategy == routingStrategySticky {
+		options["ttl"] = int(stickyTTL(routing) / time.Second)
+	}
+	return options
+}
+
+func stickyTTL(routing config.RoutingConfig) time.Duration {
+	if routing.StickyTTL <= 0 {
+		return coreauth.DefaultStickyTTL
+	}
+	return time.Duration(routing.StickyTTL) * time.Second
 }
@@ -2,8 +2,10 @@ package cliproxy
 
 import (
 	"testing"
+	"time"
 
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 )
 
 func TestNormalizeRoutingStrategy_DefaultWeighted(t *testing.T) {
@@ -65,6 +67,18 @@ func TestNormalizeRoutingStrategy_Synonyms(t *testing.T) {
 			"weighted",
 			"weight",
 		},
+		routingStrategyLeastLatency: {
+			"least-latency",
+			"latency",
+		},
+		routingStrategyLeastInflight: {
+			"least-inflight",
+			"least-loaded",
+		},
+		routingStrategySticky: {
+			"sticky",
+			"affinity",
+		},
 	}
 
 	for expected, inputs := range cases {
@@ -110,6 +124,24 @@ func TestSelectorForRoutingStrategy(t *testing.T) {
 			},
 			wantType: "*auth.WeightedSelector",
 		},
+		{
+			name:  "least-latency",
+			input: "least-latency",
+			match: func(sel coreauth.Selector) bool {
+				_, ok := sel.(*coreauth.LeastLoadedSelector)
+				return ok
+			},
+			wantType: "*auth.LeastLoadedSelector",
+		},
+		{
+			name:  "sticky",
+			input: "sticky",
+			match: func(sel coreauth.Selector) bool {
+				_, ok := sel.(*coreauth.StickySelector)
+				return ok
+			},
+			wantType: "*auth.StickySelector",
+		},
 		{
 			name:  "default-weighted",
 			input: "unknown",
@@ -134,3 +166,93 @@ func TestSelectorForRoutingStrategy(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectorForRouting_StickyTTL(t *testing.T) {
+	selector, ok := selectorForRouting(config.RoutingConfig{Strategy: "sticky", StickyTTL: 90}).(*coreauth.StickySelector)
+	if !ok {
+		t.Fatalf("expected *auth.StickySelector")
+	}
+	if got := selector.TTL(); got != 90*time.Second {
+		t.Fatalf("TTL() = %v, want %v", got, 90*time.Second)
+	}
+	if got := stickyTTL(config.RoutingConfig{}); got != coreauth.DefaultStickyTTL {
+		t.Fatalf("stickyTTL(default) = %v, want %v", got, coreauth.DefaultStickyTTL)
+	}
+}
+
+type customTestSelector struct {
+	coreauth.RoundRobinSelector
+	options map[string]any
+}
+
+func TestSelectorForRouting_RegisteredStrategy(t *testing.T) {
+	coreauth.RegisterSelector("Custom-Test", func(options map[string]any) (coreauth.Selector, error) {
+		return &customTestSelector{options: options}, nil
+	})
+
+	if got, known := normalizeRoutingStrategyWithKnown(" custom-test "); got != "custom-test" || !known {
+		t.Fatalf("normalizeRoutingStrategyWithKnown() = %q, %t; want custom-test, true", got, known)
+	}
+	routing := config.RoutingConfig{
+		Strategy: "custom-test",
+		Options:  map[string]map[string]any{"custom-test": {"shard": 3}, "sticky": {"ttl": 60}},
+	}
+	selector, ok := selectorForRouting(routing).(*customTestSelector)
+	if !ok {
+		t.Fatalf("expected *customTestSelector")
+	}
+	if len(selector.options) != 1 || selector.options["shard"] != 3 {
+		t.Fatalf("factory options = %v, want only shard=3", selector.options)
+	}
+}
+
+func TestSelectorForRouting_StickyTTLOption(t *testing.T) {
+	routing := config.RoutingConfig{
+		Strategy:  "sticky",
+		StickyTTL: 90,
+		Options:   map[string]map[string]any{"sticky": {"ttl": 45}},
+	}
+	selector, ok := selectorForRouting(routing).(*coreauth.StickySelector)
+	if !ok {
+		t.Fatalf("expected *auth.StickySelector")
+	}
+	if got := selector.TTL(); got != 45*time.Second {
+		t.Fatalf("TTL() = %v, want %v", got, 45*time.Second)
+	}
+
+	routing.Options["sticky"]["ttl"] = "not-a-number"
+	if _, ok = selectorForRouting(routing).(*coreauth.WeightedSelector); !ok {
+		t.Fatalf("expected weighted fallback for an invalid ttl option")
+	}
+}
+
+func TestSelectorOverridesForRouting(t *testing.T) {
+	routing := config.RoutingConfig{
+		Overrides: []config.RoutingOverride{
+			{Provider: "claude", Strategy: "ff"},
+			{Model: "gpt-*", Strategy: "not-real"},
+			{Model: "gemini-*", Strategy: "sticky"},
+		},
+		StickyTTL: 120,
+	}
+	overrides := selectorOverridesForRouting(routing)
+	if len(overrides) != 2 {
+		t.Fatalf("expected unknown strategy to be skipped, got %d overrides", len(overrides))
+	}
+	if _, ok := overrides[0].Selector.(*coreauth.FillFirstSelector); !ok || overrides[0].Provider != "claude" {
+		t.Fatalf("override[0] = %+v, want fill-first for provider claude", overrides[0])
+	}
+	sticky, ok := overrides[1].Selector.(*coreauth.StickySelector)
+	if !ok || sticky.TTL() != 120*time.Second {
+		t.Fatalf("override[1] = %+v, want sticky with a 120s TTL", overrides[1])
+	}
+
+	next := routing
+	if routingOverridesChanged(routing, next) {
+		t.Fatalf("expected identical routing to keep overrides")
+	}
+	next.StickyTTL = 60
+	if !routingOverridesChanged(routing, next) {
+		t.Fatalf("expected sticky-ttl change to rebuild overrides")
+	}
+}
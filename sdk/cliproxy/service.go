@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -15,7 +16,8 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
-	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	internalusage "github.com/router-for-me/CLIProxyAPI/v6/internal/usage"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/wsrelay"
 	sdkaccess "github.com/router-for-me/CLIProxyAPI/v6/sdk/access"
@@ -26,6 +28,13 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// runtimeStateSyncInterval is how often credential cooldowns and quota backoff are saved to the
+// token store so a restart does not forget them.
+const runtimeStateSyncInterval = time.Minute
+
+// keyUsageRuntimeState names the client key consumption in the runtime state snapshot.
+const keyUsageRuntimeState = "client-key-usage"
+
 // Service wraps the proxy server lifecycle so external programs can embed the CLI proxy.
 // It manages the complete lifecycle including authentication, file watching, HTTP server,
 // and integration with various AI service providers.
@@ -434,6 +443,8 @@ func (s *Service) Run(ctx context.Context) error {
 	s.applyRetryConfig(s.cfg)
 
 	if s.coreManager != nil {
+		// Client key consumption is saved with the auth runtime state so limits survive restarts.
+		s.coreManager.RegisterRuntimeStateExtension(keyUsageRuntimeState, internalusage.GetKeyUsageTracker())
 		if errLoad := s.coreManager.Load(ctx); errLoad != nil {
 			log.Warnf("failed to load auth store: %v", errLoad)
 		}
@@ -507,10 +518,10 @@ func (s *Service) Run(ctx context.Context) error {
 
 	var watcherWrapper *WatcherWrapper
 	reloadCallback := func(newCfg *config.Config) {
-		previousStrategy := ""
+		var previousRouting config.RoutingConfig
 		s.cfgMu.RLock()
 		if s.cfg != nil {
-			previousStrategy = s.cfg.Routing.Strategy
+			previousRouting = s.cfg.Routing
 		}
 		s.cfgMu.RUnlock()
 
@@ -524,16 +535,22 @@ func (s *Service) Run(ctx context.Context) error {
 		}
 
 		nextStrategy := newCfg.Routing.Strategy
-		previousNormalized, _ := normalizeRoutingStrategyWithKnown(previousStrategy)
+		previousNormalized, _ := normalizeRoutingStrategyWithKnown(previousRouting.Strategy)
 		nextNormalized, nextKnown := normalizeRoutingStrategyWithKnown(nextStrategy)
 		if !nextKnown && strings.TrimSpace(nextStrategy) != "" {
 			log.Warnf("unknown routing strategy %q; falling back to %s", nextStrategy, routingStrategyWeighted)
 		}
-		if s.coreManager != nil && previousNormalized != nextNormalized {
-			selector := selectorForRoutingStrategy(nextNormalized)
-			s.coreManager.SetSelector(selector)
+		optionsChanged := !reflect.DeepEqual(selectorOptions(previousRouting, nextNormalized), selectorOptions(newCfg.Routing, nextNormalized))
+		if s.coreManager != nil && (previousNormalized != nextNormalized || optionsChanged) {
+			nextRouting := newCfg.Routing
+			nextRouting.Strategy = nextNormalized
+			s.coreManager.SetSelector(selectorForRouting(nextRouting))
 			log.Infof("routing strategy updated to %s", nextNormalized)
 		}
+		if s.coreManager != nil && routingOverridesChanged(previousRouting, newCfg.Routing) {
+			s.coreManager.SetSelectorOverrides(selectorOverridesForRouting(newCfg.Routing))
+			log.Infof("routing strategy overrides updated (%d entries)", len(newCfg.Routing.Overrides))
+		}
 
 		s.applyRetryConfig(newCfg)
 		if s.server != nil {
@@ -572,6 +589,7 @@ func (s *Service) Run(ctx context.Context) error {
 		interval := 15 * time.Minute
 		s.coreManager.StartAutoRefresh(context.Background(), interval)
 		log.Infof("core auth auto-refresh started (interval=%s)", interval)
+		s.coreManager.StartRuntimeStateSync(context.Background(), runtimeStateSyncInterval)
 	}
 
 	select {
@@ -609,6 +627,10 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		}
 		if s.coreManager != nil {
 			s.coreManager.StopAutoRefresh()
+			s.coreManager.StopRuntimeStateSync()
+			if err := s.coreManager.FlushRuntimeState(ctx); err != nil {
+				log.Warnf("failed to save auth runtime state: %v", err)
+			}
 		}
 		if s.watcher != nil {
 			if err := s.watcher.Stop(); err != nil {
@@ -1017,7 +1039,7 @@ func applyExcludedModels(models []*ModelInfo, excluded []string) []*ModelInfo {
 		modelID := strings.ToLower(strings.TrimSpace(model.ID))
 		blocked := false
 		for _, pattern := range patterns {
-			if matchWildcard(pattern, modelID) {
+			if util.MatchWildcard(pattern, modelID) {
 				blocked = true
 				break
 			}
@@ -1071,50 +1093,6 @@ func applyModelPrefixes(models []*ModelInfo, prefix string, forceModelPrefix boo
 	return out
 }
 
-// matchWildcard performs case-insensitive wildcard matching where '*' matches any substring.
-func matchWildcard(pattern, value string) bool {
-	if pattern == "" {
-		return false
-	}
-
-	// Fast path for exact match (no wildcard present).
-	if !strings.Contains(pattern, "*") {
-		return pattern == value
-	}
-
-	parts := strings.Split(pattern, "*")
-	// Handle prefix.
-	if prefix := parts[0]; prefix != "" {
-		if !strings.HasPrefix(value, prefix) {
-			return false
-		}
-		value = value[len(prefix):]
-	}
-
-	// Handle suffix.
-	if suffix := parts[len(parts)-1]; suffix != "" {
-		if !strings.HasSuffix(value, suffix) {
-			return false
-		}
-		value = value[:len(value)-len(suffix)]
-	}
-
-	// Handle middle segments in order.
-	for i := 1; i < len(parts)-1; i++ {
-		segment := parts[i]
-		if segment == "" {
-			continue
-		}
-		idx := strings.Index(value, segment)
-		if idx < 0 {
-			return false
-		}
-		value = value[idx+len(segment):]
-	}
-
-	return true
-}
-
 type modelEntry interface {
 	GetName() string
 	GetAlias() string
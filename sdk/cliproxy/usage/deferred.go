@@ -0,0 +1,75 @@
+package usage
+
+import (
+	"context"
+	"sync"
+)
+
+type deferredContextKey struct{}
+
+// Deferred holds back the records published under a context until its owner decides whether they
+// count, e.g. when only the winning attempt of a hedged request may be billed.
+type Deferred struct {
+	mu      sync.Mutex
+	pending []deferredItem
+	settled bool
+	keep    bool
+}
+
+type deferredItem struct {
+	manager *Manager
+	ctx     context.Context
+	record  Record
+}
+
+// WithDeferred returns a context whose usage records are buffered until Commit or Discard is called.
+func WithDeferred(ctx context.Context) (context.Context, *Deferred) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	deferred := &Deferred{}
+	return context.WithValue(ctx, deferredContextKey{}, deferred), deferred
+}
+
+// hold buffers or drops record and reports whether it was taken over from the manager.
+func (d *Deferred) hold(m *Manager, ctx context.Context, record Record) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.settled {
+		return !d.keep
+	}
+	d.pending = append(d.pending, deferredItem{manager: m, ctx: ctx, record: record})
+	return true
+}
+
+// Commit publishes the buffered records; records published later go through directly.
+func (d *Deferred) Commit() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	if d.settled {
+		d.mu.Unlock()
+		return
+	}
+	d.settled, d.keep = true, true
+	pending := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+	for _, item := range pending {
+		item.manager.Publish(item.ctx, item.record)
+	}
+}
+
+// Discard drops the buffered records and every record published later.
+func (d *Deferred) Discard() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.settled {
+		d.settled = true
+		d.pending = nil
+	}
+}
@@ -19,6 +19,12 @@ type Record struct {
 	RequestedAt time.Time
 	Failed      bool
 	Detail      Detail
+	// FallbackFrom is the originally requested model when the request was served by a model fallback.
+	FallbackFrom string
+	// FallbackHop is the 1-based position of Model in the fallback chain; zero when no fallback was used.
+	FallbackHop int
+	// Affinity is the sticky routing outcome ("hit" or "miss"); empty when sticky routing did not apply.
+	Affinity string
 }
 
 // Detail holds the token usage breakdown.
@@ -109,6 +115,11 @@ func (m *Manager) Publish(ctx context.Context, record Record) {
 	if m == nil {
 		return
 	}
+	if ctx != nil {
+		if deferred, ok := ctx.Value(deferredContextKey{}).(*Deferred); ok && deferred != nil && deferred.hold(m, ctx, record) {
+			return
+		}
+	}
 	// ensure worker is running even if Start was not called explicitly
 	m.Start(context.Background())
 	m.mu.Lock()
@@ -13,6 +13,12 @@ type AccessProvider = internalconfig.AccessProvider
 type Config = internalconfig.Config
 
 type StreamingConfig = internalconfig.StreamingConfig
+type MirrorRule = internalconfig.MirrorRule
+type ClientKey = internalconfig.ClientKey
+type ClientCert = internalconfig.ClientCert
+type ClientPolicy = internalconfig.ClientPolicy
+type ClientKeyLimits = internalconfig.ClientKeyLimits
+type ClientRateLimit = internalconfig.ClientRateLimit
 type TLSConfig = internalconfig.TLSConfig
 type RemoteManagement = internalconfig.RemoteManagement
 type AmpCode = internalconfig.AmpCode
@@ -20,6 +26,10 @@ type OAuthModelAlias = internalconfig.OAuthModelAlias
 type PayloadConfig = internalconfig.PayloadConfig
 type PayloadRule = internalconfig.PayloadRule
 type PayloadModelRule = internalconfig.PayloadModelRule
+type RoutingConfig = internalconfig.RoutingConfig
+type RoutingOverride = internalconfig.RoutingOverride
+type ProviderWeight = internalconfig.ProviderWeight
+type SpilloverPolicy = internalconfig.SpilloverPolicy
 
 type GeminiKey = internalconfig.GeminiKey
 type CodexKey = internalconfig.CodexKey
@@ -34,6 +44,9 @@ type TLS = internalconfig.TLSConfig
 
 const (
 	AccessProviderTypeConfigAPIKey = internalconfig.AccessProviderTypeConfigAPIKey
+	AccessProviderTypeJWT          = internalconfig.AccessProviderTypeJWT
+	AccessProviderTypeClientCert   = internalconfig.AccessProviderTypeClientCert
+	DefaultClientCertProviderName  = internalconfig.DefaultClientCertProviderName
 	DefaultAccessProviderName      = internalconfig.DefaultAccessProviderName
 	DefaultPanelGitHubRepository   = internalconfig.DefaultPanelGitHubRepository
 )